@@ -3,31 +3,48 @@ package main
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"time"
 
 	"github.com/SawitProRecruitment/UserService/config"
-	"github.com/SawitProRecruitment/UserService/generated"
 	"github.com/SawitProRecruitment/UserService/handler"
+	"github.com/SawitProRecruitment/UserService/jobs"
+	"github.com/SawitProRecruitment/UserService/ratelimit"
 	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/sms"
+	"github.com/SawitProRecruitment/UserService/tools"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/gommon/log"
+	"google.golang.org/grpc"
 )
 
 func main() {
 	e := echo.New()
 	e.Logger.SetLevel(log.INFO)
 	cfg := config.GetConfig()
-	var server generated.ServerInterface = newServer(cfg)
+	// Tokens, the verifier and the discovery document all name this issuer.
+	if cfg.JWTIssuer == "" {
+		e.Logger.Fatal("JWT_ISSUER must be set to the service's public URL")
+	}
+	srv := newServer(cfg)
 
-	usersGroup := e.Group("/users")
-	usersGroup.Use(handler.JWTMiddleware(*cfg))
-	usersGroup.GET("", server.GetUserProfile)
-	usersGroup.PUT("", server.UpdateUser)
+	ipExtractor, err := handler.NewIPExtractor(cfg.TrustedProxies)
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+	e.IPExtractor = ipExtractor
+
+	rateLimits, err := ratelimit.NewStore(cfg.RateLimitStore, cfg.RedisURL)
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+	e.Use(handler.RateLimitMiddleware(rateLimits, handler.RouteRateLimits(*cfg)))
 
-	generated.RegisterHandlers(e, server)
+	srv.RegisterRoutes(e)
 
 	// Start server
 	go func(port uint16) {
@@ -36,9 +53,48 @@ func main() {
 		}
 	}(cfg.Port)
 
+	// Envoy's external authorizer is only served when a port is configured.
+	var grpcServer *grpc.Server
+	if cfg.ExtAuthzGRPCPort != 0 {
+		listener, err := net.Listen("tcp", fmt.Sprintf(":%v", cfg.ExtAuthzGRPCPort))
+		if err != nil {
+			e.Logger.Fatal(err)
+		}
+
+		grpcServer = grpc.NewServer()
+		authv3.RegisterAuthorizationServer(grpcServer, handler.NewExtAuthzServer(
+			handler.NewTokenVerifier(*cfg, srv.KeyRing, srv.TokenRevocations),
+		))
+		go func() {
+			if err := grpcServer.Serve(listener); err != nil {
+				e.Logger.Fatal("shutting down the ext_authz server")
+			}
+		}()
+	}
+
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	if cfg.AccountPurgeIntervalInMinutes > 0 {
+		purger := jobs.NewAccountPurger(srv.Repository, jobs.AccountPurgerOptions{
+			GracePeriod: time.Duration(cfg.AccountDeletionGracePeriodInDays) * 24 * time.Hour,
+			Interval:    time.Duration(cfg.AccountPurgeIntervalInMinutes) * time.Minute,
+		})
+		go purger.Run(jobsCtx)
+	}
+	if cfg.DataExportPollIntervalInSeconds > 0 {
+		exporter := jobs.NewDataExporter(srv.Repository, jobs.DataExporterOptions{
+			Retention: time.Duration(cfg.DataExportRetentionInHours) * time.Hour,
+			Interval:  time.Duration(cfg.DataExportPollIntervalInSeconds) * time.Second,
+		})
+		go exporter.Run(jobsCtx)
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt)
 	<-quit
+	stopJobs()
+	if grpcServer != nil {
+		grpcServer.GracefulStop()
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	if err := e.Shutdown(ctx); err != nil {
@@ -47,14 +103,48 @@ func main() {
 }
 
 func newServer(config *config.Config) *handler.Server {
-	var repo repository.RepositoryInterface = repository.NewRepository(
+	db := repository.NewRepository(
 		repository.NewRepositoryOptions{
 			Dsn: config.DatabaseURL,
 		},
 	)
+	var repo repository.RepositoryInterface = db
+	var revocations repository.TokenRevocationRepositoryInterface = repository.NewCachedTokenRevocationRepository(
+		db,
+		time.Duration(config.TokenRevocationCacheTTLInSeconds)*time.Second,
+	)
+	keyRing, err := tools.NewKeyRing(tools.KeyRingOptions{
+		PrivateKey:        config.RSAPrivateKey,
+		PublicKey:         config.RSAPublicKey,
+		RetiredPublicKeys: config.RSARetiredPublicKeys,
+	})
+	if err != nil {
+		panic(err)
+	}
+	passwordHasher, err := handler.NewPasswordHasher(*config)
+	if err != nil {
+		panic(err)
+	}
+	smsSender, err := sms.NewSender(config.SMSSender, config.SMSFilePath)
+	if err != nil {
+		panic(err)
+	}
+	passwordBlocklist, err := tools.LoadPasswordBlocklist(tools.PasswordBlocklistOptions{
+		CommonPasswordsFile: config.PasswordCommonListFile,
+		BreachedHashesPath:  config.PasswordBreachedHashesPath,
+	})
+	if err != nil {
+		panic(err)
+	}
+	tools.SetPasswordBlocklist(passwordBlocklist)
+	tools.SetPasswordPolicy(handler.PasswordPolicy(*config))
 	opts := handler.NewServerOptions{
-		Repository: repo,
-		Config:     *config,
+		Repository:       repo,
+		TokenRevocations: revocations,
+		KeyRing:          keyRing,
+		PasswordHasher:   passwordHasher,
+		SMSSender:        smsSender,
+		Config:           *config,
 	}
 	return handler.NewServer(opts)
 }
@@ -13,9 +13,78 @@ type Config struct {
 
 	RSAPrivateKey           string `mapstructure:"RSA_PRIVATE_KEY"`
 	RSAPublicKey            string `mapstructure:"RSA_PUBLIC_KEY"`
+	RSARetiredPublicKeys    string `mapstructure:"RSA_RETIRED_PUBLIC_KEYS"`
 	JWTTokenLifetimeInHours int    `mapstructure:"JWT_TOKEN_LIFETIME_IN_HOURS"`
+	JWTIssuer               string `mapstructure:"JWT_ISSUER"`
+	JWTAudience             string `mapstructure:"JWT_AUDIENCE"`
 	JwtInfoToken            string `mapstructure:"JWT_INFO_TOKEN"`
 	MaxTimeout              int    `mapstructure:"MAX_TIMEOUT"`
+
+	RefreshTokenLifetimeInHours      int `mapstructure:"REFRESH_TOKEN_LIFETIME_IN_HOURS"`
+	TokenRevocationCacheTTLInSeconds int `mapstructure:"TOKEN_REVOCATION_CACHE_TTL_IN_SECONDS"`
+
+	OAuthAuthorizationCodeLifetimeInSeconds int `mapstructure:"OAUTH_AUTHORIZATION_CODE_LIFETIME_IN_SECONDS"`
+
+	ForwardAuthLoginURL    string `mapstructure:"FORWARD_AUTH_LOGIN_URL"`
+	ForwardAuthTokenCookie string `mapstructure:"FORWARD_AUTH_TOKEN_COOKIE"`
+
+	ExtAuthzGRPCPort uint16 `mapstructure:"EXT_AUTHZ_GRPC_PORT"`
+
+	SMSSender   string `mapstructure:"SMS_SENDER"`
+	SMSFilePath string `mapstructure:"SMS_FILE_PATH"`
+
+	OTPLifetimeInSeconds                int `mapstructure:"OTP_LIFETIME_IN_SECONDS"`
+	OTPMaxAttempts                      int `mapstructure:"OTP_MAX_ATTEMPTS"`
+	OTPResendIntervalInSeconds          int `mapstructure:"OTP_RESEND_INTERVAL_IN_SECONDS"`
+	OTPMaxSendsPerHour                  int `mapstructure:"OTP_MAX_SENDS_PER_HOUR"`
+	PasswordResetTokenLifetimeInSeconds int `mapstructure:"PASSWORD_RESET_TOKEN_LIFETIME_IN_SECONDS"`
+
+	PasswordHashAlgorithm     string `mapstructure:"PASSWORD_HASH_ALGORITHM"`
+	PasswordBcryptCost        int    `mapstructure:"PASSWORD_BCRYPT_COST"`
+	PasswordArgon2MemoryKiB   uint32 `mapstructure:"PASSWORD_ARGON2_MEMORY_KIB"`
+	PasswordArgon2Iterations  uint32 `mapstructure:"PASSWORD_ARGON2_ITERATIONS"`
+	PasswordArgon2Parallelism uint8  `mapstructure:"PASSWORD_ARGON2_PARALLELISM"`
+
+	PasswordMinLength int `mapstructure:"PASSWORD_MIN_LENGTH"`
+	// The PASSWORD_REQUIRE_* rules are pointers so that leaving one out keeps
+	// the default policy's rule instead of turning it off.
+	PasswordRequireUppercase *bool `mapstructure:"PASSWORD_REQUIRE_UPPERCASE"`
+	PasswordRequireLowercase *bool `mapstructure:"PASSWORD_REQUIRE_LOWERCASE"`
+	PasswordRequireNumber    *bool `mapstructure:"PASSWORD_REQUIRE_NUMBER"`
+	PasswordRequireSpecial   *bool `mapstructure:"PASSWORD_REQUIRE_SPECIAL"`
+	PasswordMaxAgeInDays     int   `mapstructure:"PASSWORD_MAX_AGE_IN_DAYS"`
+	PasswordHistorySize      int   `mapstructure:"PASSWORD_HISTORY_SIZE"`
+
+	PasswordCommonListFile     string `mapstructure:"PASSWORD_COMMON_LIST_FILE"`
+	PasswordBreachedHashesPath string `mapstructure:"PASSWORD_BREACHED_HASHES_PATH"`
+
+	LoginRequireVerifiedPhone bool `mapstructure:"LOGIN_REQUIRE_VERIFIED_PHONE"`
+
+	LoginMaxFailedAttempts        int `mapstructure:"LOGIN_MAX_FAILED_ATTEMPTS"`
+	LoginMaxFailedAttemptsPerIP   int `mapstructure:"LOGIN_MAX_FAILED_ATTEMPTS_PER_IP"`
+	LoginBackoffBaseInSeconds     int `mapstructure:"LOGIN_BACKOFF_BASE_IN_SECONDS"`
+	LoginLockoutDurationInSeconds int `mapstructure:"LOGIN_LOCKOUT_DURATION_IN_SECONDS"`
+
+	TrustedProxies string `mapstructure:"TRUSTED_PROXIES"`
+
+	RateLimitStore             string `mapstructure:"RATE_LIMIT_STORE"`
+	RedisURL                   string `mapstructure:"REDIS_URL"`
+	RateLimitLoginPerMinute    int    `mapstructure:"RATE_LIMIT_LOGIN_PER_MINUTE"`
+	RateLimitLoginBurst        int    `mapstructure:"RATE_LIMIT_LOGIN_BURST"`
+	RateLimitRegisterPerMinute int    `mapstructure:"RATE_LIMIT_REGISTER_PER_MINUTE"`
+	RateLimitRegisterBurst     int    `mapstructure:"RATE_LIMIT_REGISTER_BURST"`
+
+	TOTPIssuer                      string `mapstructure:"TOTP_ISSUER"`
+	TOTPEncryptionKey               string `mapstructure:"TOTP_ENCRYPTION_KEY"`
+	LoginChallengeLifetimeInSeconds int    `mapstructure:"LOGIN_CHALLENGE_LIFETIME_IN_SECONDS"`
+
+	AccountDeletionGracePeriodInDays int `mapstructure:"ACCOUNT_DELETION_GRACE_PERIOD_IN_DAYS"`
+	AccountPurgeIntervalInMinutes    int `mapstructure:"ACCOUNT_PURGE_INTERVAL_IN_MINUTES"`
+
+	DataExportSigningKey            string `mapstructure:"DATA_EXPORT_SIGNING_KEY"`
+	DataExportLinkLifetimeInMinutes int    `mapstructure:"DATA_EXPORT_LINK_LIFETIME_IN_MINUTES"`
+	DataExportRetentionInHours      int    `mapstructure:"DATA_EXPORT_RETENTION_IN_HOURS"`
+	DataExportPollIntervalInSeconds int    `mapstructure:"DATA_EXPORT_POLL_INTERVAL_IN_SECONDS"`
 }
 
 func GetConfig() *Config {
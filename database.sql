@@ -10,6 +10,7 @@
 /** This is test table. Remove this table and replace with your own tables. */
 
 CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+CREATE EXTENSION IF NOT EXISTS "pg_trgm";
 
 CREATE FUNCTION set_last_modified_at() RETURNS trigger AS $$
 BEGIN
@@ -25,11 +26,23 @@ CREATE TABLE users (
   "full_name" VARCHAR (60) NOT NULL,
   "phone_number" VARCHAR (50) NOT NULL,
   "password" VARCHAR (255) NOT NULL,
+  "password_changed_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+  "phone_verified_at" TIMESTAMP WITHOUT TIME ZONE,
+  "pending_phone_number" VARCHAR (50),
+  "disabled_at" TIMESTAMP WITHOUT TIME ZONE,
   "created_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
   "last_modified_at" TIMESTAMP WITHOUT TIME ZONE DEFAULT NOW(),
-  "deleted_at" TIMESTAMP WITHOUT TIME ZONE
+  "deleted_at" TIMESTAMP WITHOUT TIME ZONE,
+  "deleted_by" UUID,
+  "purged_at" TIMESTAMP WITHOUT TIME ZONE
 );
 
+COMMENT ON COLUMN users.pending_phone_number IS 'New phone number waiting to be confirmed with the code sent to it';
+COMMENT ON COLUMN users.disabled_at IS 'Set by staff to stop the account from logging in without deleting it';
+COMMENT ON COLUMN users.deleted_at IS 'Logging in restores the account until the grace period after this ends';
+COMMENT ON COLUMN users.deleted_by IS 'GUID of who deleted the account; only accounts users deleted themselves can be restored';
+COMMENT ON COLUMN users.purged_at IS 'Set once the personal data of the deleted account has been erased';
+
 -- ALTER TABLE users
 -- ADD CONSTRAINT users_unique_phone_number_password_key
 -- UNIQUE (phone_number, password);
@@ -40,7 +53,275 @@ WHERE deleted_at IS NULL;
 
 CREATE UNIQUE INDEX users_unique_phone_number_key ON users (phone_number) WHERE deleted_at IS NULL;
 
+-- Back the admin user listing: keyset pagination newest first, phone prefix
+-- filters and case-insensitive partial matches on the name.
+CREATE INDEX users_created_at_id_idx ON users (created_at DESC, id DESC) WHERE deleted_at IS NULL;
+CREATE INDEX users_phone_number_pattern_idx ON users (phone_number varchar_pattern_ops) WHERE deleted_at IS NULL;
+CREATE INDEX users_full_name_trgm_idx ON users USING gin (full_name gin_trgm_ops) WHERE deleted_at IS NULL;
+
+-- Find deleted accounts to restore on login and to purge once their grace
+-- period is over.
+CREATE INDEX users_deleted_phone_number_idx ON users (phone_number, deleted_at DESC) WHERE deleted_at IS NOT NULL AND purged_at IS NULL;
+CREATE INDEX users_deleted_at_idx ON users (deleted_at) WHERE deleted_at IS NOT NULL AND purged_at IS NULL;
+
 
 CREATE TRIGGER set_last_modified_at BEFORE
 UPDATE
-    ON users FOR EACH ROW EXECUTE PROCEDURE set_last_modified_at();
\ No newline at end of file
+    ON users FOR EACH ROW EXECUTE PROCEDURE set_last_modified_at();
+
+CREATE TABLE oauth_clients (
+  "id" serial PRIMARY KEY,
+  "client_id" VARCHAR (64) NOT NULL UNIQUE,
+  "name" VARCHAR (100) NOT NULL,
+  "client_secret_hash" VARCHAR (255),
+  "redirect_uris" TEXT[] NOT NULL DEFAULT '{}',
+  "scopes" TEXT[] NOT NULL DEFAULT '{}',
+  "grant_types" TEXT[] NOT NULL DEFAULT '{authorization_code,refresh_token}',
+  "created_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+  "last_modified_at" TIMESTAMP WITHOUT TIME ZONE DEFAULT NOW(),
+  "deleted_at" TIMESTAMP WITHOUT TIME ZONE
+);
+
+COMMENT ON COLUMN oauth_clients.client_secret_hash IS 'NULL for public clients, which must use PKCE';
+COMMENT ON COLUMN oauth_clients.grant_types IS 'Machine clients are registered with client_credentials only';
+
+CREATE TRIGGER set_last_modified_at BEFORE
+UPDATE
+    ON oauth_clients FOR EACH ROW EXECUTE PROCEDURE set_last_modified_at();
+
+
+CREATE TABLE refresh_tokens (
+  "id" serial PRIMARY KEY,
+  "user_id" INTEGER NOT NULL REFERENCES users (id),
+  "oauth_client_id" INTEGER REFERENCES oauth_clients (id),
+  "scope" TEXT NOT NULL DEFAULT '',
+  "family_id" UUID NOT NULL,
+  "token_hash" VARCHAR (64) NOT NULL UNIQUE,
+  "expires_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+  "used_at" TIMESTAMP WITHOUT TIME ZONE,
+  "revoked_at" TIMESTAMP WITHOUT TIME ZONE,
+  "created_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX refresh_tokens_family_id_idx ON refresh_tokens (family_id);
+
+CREATE INDEX refresh_tokens_user_id_idx ON refresh_tokens (user_id);
+
+CREATE TABLE revoked_access_tokens (
+  "jti" VARCHAR (64) PRIMARY KEY,
+  "user_guid" UUID NOT NULL,
+  "expires_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+  "created_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX revoked_access_tokens_expires_at_idx ON revoked_access_tokens (expires_at);
+
+CREATE TABLE user_token_revocations (
+  "user_guid" UUID PRIMARY KEY REFERENCES users (guid),
+  "revoked_before" TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+  "created_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+  "last_modified_at" TIMESTAMP WITHOUT TIME ZONE DEFAULT NOW()
+);
+
+CREATE TRIGGER set_last_modified_at BEFORE
+UPDATE
+    ON user_token_revocations FOR EACH ROW EXECUTE PROCEDURE set_last_modified_at();
+
+
+CREATE TABLE oauth_authorization_codes (
+  "id" serial PRIMARY KEY,
+  "code_hash" VARCHAR (64) NOT NULL UNIQUE,
+  "oauth_client_id" INTEGER NOT NULL REFERENCES oauth_clients (id),
+  "user_id" INTEGER NOT NULL REFERENCES users (id),
+  "redirect_uri" TEXT NOT NULL,
+  -- Whether the authorization request named redirect_uri, in which case the
+  -- token request has to repeat it.
+  "redirect_uri_sent" BOOLEAN NOT NULL DEFAULT FALSE,
+  "scope" TEXT NOT NULL DEFAULT '',
+  "code_challenge" VARCHAR (128) NOT NULL,
+  "code_challenge_method" VARCHAR (10) NOT NULL,
+  "nonce" TEXT NOT NULL DEFAULT '',
+  "refresh_token_family_id" UUID,
+  "expires_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+  "used_at" TIMESTAMP WITHOUT TIME ZONE,
+  "created_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE oauth_consents (
+  "id" serial PRIMARY KEY,
+  "user_id" INTEGER NOT NULL REFERENCES users (id),
+  "oauth_client_id" INTEGER NOT NULL REFERENCES oauth_clients (id),
+  "scopes" TEXT[] NOT NULL DEFAULT '{}',
+  "created_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+  "last_modified_at" TIMESTAMP WITHOUT TIME ZONE DEFAULT NOW(),
+  "revoked_at" TIMESTAMP WITHOUT TIME ZONE,
+  CONSTRAINT oauth_consents_unique_user_id_oauth_client_id_key UNIQUE (user_id, oauth_client_id)
+);
+
+CREATE TRIGGER set_last_modified_at BEFORE
+UPDATE
+    ON oauth_consents FOR EACH ROW EXECUTE PROCEDURE set_last_modified_at();
+
+CREATE TABLE otp_codes (
+  "id" serial PRIMARY KEY,
+  "user_id" INTEGER NOT NULL REFERENCES users (id),
+  "purpose" VARCHAR (32) NOT NULL,
+  "phone_number" VARCHAR (50) NOT NULL,
+  "code_hash" VARCHAR (255) NOT NULL,
+  "attempts" INTEGER NOT NULL DEFAULT 0,
+  "verification_token_hash" VARCHAR (64) UNIQUE,
+  "expires_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+  "verified_at" TIMESTAMP WITHOUT TIME ZONE,
+  "consumed_at" TIMESTAMP WITHOUT TIME ZONE,
+  "created_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX otp_codes_user_id_purpose_idx ON otp_codes (user_id, purpose);
+CREATE INDEX otp_codes_phone_number_created_at_idx ON otp_codes (phone_number, created_at);
+
+COMMENT ON COLUMN otp_codes.phone_number IS 'Where the code was sent, so it only proves ownership of that number';
+COMMENT ON COLUMN otp_codes.code_hash IS 'Hashed with the configured password hasher, since a short numeric code is trivial to brute force from a plain digest';
+COMMENT ON COLUMN otp_codes.verification_token_hash IS 'Set once the code is verified; the token finishes the flow the code was sent for';
+
+CREATE TABLE user_totp (
+  "user_id" INTEGER PRIMARY KEY REFERENCES users (id),
+  "secret_ciphertext" TEXT NOT NULL,
+  "last_used_step" BIGINT NOT NULL DEFAULT 0,
+  "enabled_at" TIMESTAMP WITHOUT TIME ZONE,
+  "created_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+  "last_modified_at" TIMESTAMP WITHOUT TIME ZONE DEFAULT NOW()
+);
+
+CREATE TRIGGER set_last_modified_at BEFORE
+UPDATE
+    ON user_totp FOR EACH ROW EXECUTE PROCEDURE set_last_modified_at();
+
+COMMENT ON COLUMN user_totp.secret_ciphertext IS 'AES-GCM sealed with TOTP_ENCRYPTION_KEY, since the secret has to be read back to check codes';
+COMMENT ON COLUMN user_totp.last_used_step IS 'Time step of the last accepted code, so a code cannot be replayed within its window';
+COMMENT ON COLUMN user_totp.enabled_at IS 'NULL while enrollment waits for the first code';
+
+CREATE TABLE totp_recovery_codes (
+  "id" serial PRIMARY KEY,
+  "user_id" INTEGER NOT NULL REFERENCES users (id),
+  "code_hash" VARCHAR (64) NOT NULL,
+  "used_at" TIMESTAMP WITHOUT TIME ZONE,
+  "created_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX totp_recovery_codes_user_id_idx ON totp_recovery_codes (user_id);
+
+CREATE TABLE login_challenges (
+  "id" serial PRIMARY KEY,
+  "user_id" INTEGER NOT NULL REFERENCES users (id),
+  "token_hash" VARCHAR (64) NOT NULL UNIQUE,
+  "attempts" INTEGER NOT NULL DEFAULT 0,
+  "expires_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+  "consumed_at" TIMESTAMP WITHOUT TIME ZONE,
+  "restore_account" BOOLEAN NOT NULL DEFAULT FALSE,
+  "created_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON COLUMN login_challenges.restore_account IS 'Restore the deleted account once the second factor is accepted';
+COMMENT ON TABLE login_challenges IS 'Issued after the password step for users with two-factor authentication enabled';
+
+CREATE TABLE login_throttles (
+  "subject" VARCHAR (100) PRIMARY KEY,
+  "failed_attempts" INTEGER NOT NULL DEFAULT 0,
+  "last_failed_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+  "blocked_until" TIMESTAMP WITHOUT TIME ZONE,
+  "created_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE login_throttles IS 'Failed password logins per account (user:<id>) and per client IP (ip:<address>)';
+COMMENT ON COLUMN login_throttles.blocked_until IS 'No password login is attempted for the subject before this time';
+
+CREATE TABLE password_history (
+  "id" serial PRIMARY KEY,
+  "user_id" INTEGER NOT NULL REFERENCES users (id),
+  "password_hash" VARCHAR (255) NOT NULL,
+  "created_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX password_history_user_id_idx ON password_history (user_id, id DESC);
+
+COMMENT ON TABLE password_history IS 'Hashes of passwords users replaced, newest last, kept to refuse reusing them';
+
+CREATE TABLE roles (
+  "id" serial PRIMARY KEY,
+  "name" VARCHAR (50) NOT NULL UNIQUE,
+  "description" VARCHAR (255) NOT NULL DEFAULT '',
+  "created_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE permissions (
+  "id" serial PRIMARY KEY,
+  "name" VARCHAR (100) NOT NULL UNIQUE,
+  "description" VARCHAR (255) NOT NULL DEFAULT '',
+  "created_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE role_permissions (
+  "role_id" INTEGER NOT NULL REFERENCES roles (id),
+  "permission_id" INTEGER NOT NULL REFERENCES permissions (id),
+  PRIMARY KEY (role_id, permission_id)
+);
+
+CREATE TABLE user_roles (
+  "user_id" INTEGER NOT NULL REFERENCES users (id),
+  "role_id" INTEGER NOT NULL REFERENCES roles (id),
+  "created_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (user_id, role_id)
+);
+
+COMMENT ON TABLE user_roles IS 'Roles granted to users; their permissions are embedded in access tokens issued afterwards';
+
+INSERT INTO roles (name, description) VALUES
+  ('admin', 'Manages users and reads the audit log'),
+  ('support', 'Looks up users to help them');
+
+INSERT INTO permissions (name, description) VALUES
+  ('users:read', 'Read any user''s account'),
+  ('users:write', 'Change, disable and unlock any user''s account'),
+  ('roles:write', 'Grant and revoke roles'),
+  ('audit_logs:read', 'Read the audit log');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p WHERE r.name = 'admin';
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'users:read' WHERE r.name = 'support';
+
+CREATE TABLE audit_logs (
+  "id" bigserial PRIMARY KEY,
+  "actor_guid" UUID,
+  "action" VARCHAR (50) NOT NULL,
+  "target_user_id" INTEGER REFERENCES users (id),
+  "ip_address" VARCHAR (45) NOT NULL DEFAULT '',
+  "details" JSONB NOT NULL DEFAULT '{}',
+  "created_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX audit_logs_target_user_id_idx ON audit_logs (target_user_id, id DESC);
+
+COMMENT ON TABLE audit_logs IS 'Append-only record of what staff did to which account';
+COMMENT ON COLUMN audit_logs.actor_guid IS 'GUID of the user who acted, kept even after their account is gone; NULL for machine clients, whose client_id is in details';
+
+CREATE TABLE data_exports (
+  "id" serial PRIMARY KEY,
+  "guid" UUID NOT NULL DEFAULT uuid_generate_v4() UNIQUE,
+  "user_id" INTEGER NOT NULL REFERENCES users (id),
+  "format" VARCHAR (10) NOT NULL,
+  "status" VARCHAR (20) NOT NULL DEFAULT 'pending',
+  "archive" BYTEA,
+  "error" VARCHAR (255),
+  "created_at" TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+  "started_at" TIMESTAMP WITHOUT TIME ZONE,
+  "completed_at" TIMESTAMP WITHOUT TIME ZONE,
+  "expires_at" TIMESTAMP WITHOUT TIME ZONE
+);
+
+CREATE INDEX data_exports_queue_idx ON data_exports (created_at) WHERE status IN ('pending', 'processing');
+CREATE INDEX data_exports_ready_expires_at_idx ON data_exports (expires_at) WHERE status = 'ready';
+
+COMMENT ON TABLE data_exports IS 'Copies of everything stored about a user, built in the background at their request';
+COMMENT ON COLUMN data_exports.archive IS 'Set while the export is ready and cleared once it expires';
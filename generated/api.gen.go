@@ -8,6 +8,7 @@ import (
 	"compress/gzip"
 	"encoding/base64"
 	"fmt"
+	"net/http"
 	"net/url"
 	"path"
 	"strings"
@@ -15,14 +16,151 @@ import (
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/labstack/echo/v4"
+	"github.com/oapi-codegen/runtime"
 	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
+// Defines values for DataExportPayloadFormat.
+const (
+	DataExportPayloadFormatJson DataExportPayloadFormat = "json"
+	DataExportPayloadFormatZip  DataExportPayloadFormat = "zip"
+)
+
+// Defines values for DataExportResponseFormat.
+const (
+	DataExportResponseFormatJson DataExportResponseFormat = "json"
+	DataExportResponseFormatZip  DataExportResponseFormat = "zip"
+)
+
+// Defines values for DataExportResponseStatus.
+const (
+	Expired    DataExportResponseStatus = "expired"
+	Failed     DataExportResponseStatus = "failed"
+	Pending    DataExportResponseStatus = "pending"
+	Processing DataExportResponseStatus = "processing"
+	Ready      DataExportResponseStatus = "ready"
+)
+
+// AccountDeletionResponse defines model for AccountDeletionResponse.
+type AccountDeletionResponse struct {
+	Message string `json:"message"`
+
+	// PurgeAt When the personal data will be erased for good, until then logging in can restore the account
+	PurgeAt time.Time `json:"purge_at"`
+}
+
+// AdminUserListResponse defines model for AdminUserListResponse.
+type AdminUserListResponse struct {
+	// NextCursor Pass as cursor to get the next page, absent on the last page
+	NextCursor *string            `json:"next_cursor,omitempty"`
+	Users      []AdminUserSummary `json:"users"`
+}
+
+// AdminUserResponse defines model for AdminUserResponse.
+type AdminUserResponse struct {
+	// CreatedAt User created at
+	CreatedAt time.Time `json:"created_at"`
+
+	// DisabledAt When staff disabled the account, absent while it can log in
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`
+
+	// FullName User full name
+	FullName string `json:"full_name"`
+
+	// Guid User GUID
+	Guid openapi_types.UUID `json:"guid"`
+
+	// PendingPhoneNumber New phone number waiting to be confirmed
+	PendingPhoneNumber *string `json:"pending_phone_number,omitempty"`
+
+	// PhoneNumber User phone number
+	PhoneNumber string `json:"phone_number"`
+
+	// PhoneVerifiedAt When the phone number was verified, absent until it is
+	PhoneVerifiedAt *time.Time `json:"phone_verified_at,omitempty"`
+
+	// Roles Names of the roles granted to the user
+	Roles []string `json:"roles"`
+}
+
+// AdminUserSummary defines model for AdminUserSummary.
+type AdminUserSummary struct {
+	// CreatedAt User created at
+	CreatedAt time.Time `json:"created_at"`
+
+	// DisabledAt When staff disabled the account, absent while it can log in
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`
+
+	// FullName User full name
+	FullName string `json:"full_name"`
+
+	// Guid User GUID
+	Guid openapi_types.UUID `json:"guid"`
+
+	// PhoneNumber User phone number
+	PhoneNumber string `json:"phone_number"`
+
+	// PhoneVerifiedAt When the phone number was verified, absent until it is
+	PhoneVerifiedAt *time.Time `json:"phone_verified_at,omitempty"`
+}
+
+// ChangePasswordPayload defines model for ChangePasswordPayload.
+type ChangePasswordPayload struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,max=64,pwd,not_breached_pwd,nefield=CurrentPassword"`
+}
+
+// ConfirmPhoneChangePayload defines model for ConfirmPhoneChangePayload.
+type ConfirmPhoneChangePayload struct {
+	Code string `json:"code" validate:"required,numeric,len=6"`
+}
+
+// ConfirmTOTPPayload defines model for ConfirmTOTPPayload.
+type ConfirmTOTPPayload struct {
+	Code string `json:"code" validate:"required,numeric,len=6"`
+}
+
+// DataExportPayload defines model for DataExportPayload.
+type DataExportPayload struct {
+	// Format A single JSON document, or a ZIP archive with one JSON file per kind of data
+	Format DataExportPayloadFormat `json:"format" validate:"required,oneof=json zip"`
+}
+
+// DataExportPayloadFormat A single JSON document, or a ZIP archive with one JSON file per kind of data
+type DataExportPayloadFormat string
+
+// DataExportResponse defines model for DataExportResponse.
+type DataExportResponse struct {
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+
+	// DownloadUrl Signed link to the archive, only while it is ready
+	DownloadUrl          *string    `json:"download_url,omitempty"`
+	DownloadUrlExpiresAt *time.Time `json:"download_url_expires_at,omitempty"`
+
+	// ExpiresAt When a ready archive is deleted
+	ExpiresAt *time.Time               `json:"expires_at,omitempty"`
+	Format    DataExportResponseFormat `json:"format"`
+	Id        openapi_types.UUID       `json:"id"`
+	Status    DataExportResponseStatus `json:"status"`
+}
+
+// DataExportResponseFormat defines model for DataExportResponse.Format.
+type DataExportResponseFormat string
+
+// DataExportResponseStatus defines model for DataExportResponse.Status.
+type DataExportResponseStatus string
+
 // DefaultUpdateResponse defines model for DefaultUpdateResponse.
 type DefaultUpdateResponse struct {
 	Message string `json:"message"`
 }
 
+// DeleteAccountPayload defines model for DeleteAccountPayload.
+type DeleteAccountPayload struct {
+	Password string `json:"password" validate:"required,max=64"`
+}
+
 // ErrorResponse defines model for ErrorResponse.
 type ErrorResponse struct {
 	Message string `json:"message"`
@@ -34,19 +172,250 @@ type ErrorWithExtraResponse struct {
 	Message string       `json:"message"`
 }
 
+// JSONWebKey defines model for JSONWebKey.
+type JSONWebKey struct {
+	// Alg Signing algorithm, always RS256
+	Alg string `json:"alg"`
+
+	// E Base64url encoded public exponent
+	E string `json:"e"`
+
+	// Kid Key id matching the kid header of the token
+	Kid string `json:"kid"`
+
+	// Kty Key type, always RSA
+	Kty string `json:"kty"`
+
+	// N Base64url encoded modulus
+	N string `json:"n"`
+
+	// Use Public key use, always sig
+	Use string `json:"use"`
+}
+
+// JSONWebKeySet defines model for JSONWebKeySet.
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// LoginCodePayload defines model for LoginCodePayload.
+type LoginCodePayload struct {
+	Code        string `json:"code" validate:"required,numeric,len=6"`
+	PhoneNumber string `json:"phone_number" validate:"required,min=10,max=13,phone_number"`
+}
+
+// LoginCodeRequestPayload defines model for LoginCodeRequestPayload.
+type LoginCodeRequestPayload struct {
+	PhoneNumber string `json:"phone_number" validate:"required,min=10,max=13,phone_number"`
+}
+
+// LoginTwoFactorPayload defines model for LoginTwoFactorPayload.
+type LoginTwoFactorPayload struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+
+	// Code Current code from the authenticator app
+	Code *string `json:"code,omitempty" validate:"required_without=RecoveryCode,omitempty,numeric,len=6"`
+
+	// RecoveryCode One of the recovery codes, for when the authenticator app is not at hand
+	RecoveryCode *string `json:"recovery_code,omitempty" validate:"required_without=Code,omitempty,min=10,max=12"`
+}
+
 // LoginUserPayload defines model for LoginUserPayload.
 type LoginUserPayload struct {
-	Password    string `json:"password" validate:"required,min=6,max=64,pwd"`
+	Password    string `json:"password" validate:"required,max=64"`
+	PhoneNumber string `json:"phone_number" validate:"required,min=10,max=13,phone_number"`
+
+	// RestoreAccount Restore the account if it was deleted and its grace period has not ended
+	RestoreAccount *bool `json:"restore_account,omitempty"`
+}
+
+// LogoutPayload defines model for LogoutPayload.
+type LogoutPayload struct {
+	// RefreshToken Refresh token of the current session, revoked together with the access token
+	RefreshToken *string `json:"refresh_token,omitempty"`
+}
+
+// OAuthAuthorizeResponse defines model for OAuthAuthorizeResponse.
+type OAuthAuthorizeResponse struct {
+	ClientId   string `json:"client_id"`
+	ClientName string `json:"client_name"`
+
+	// ConsentRequired True when the caller has to approve the scopes before a code is issued
+	ConsentRequired bool `json:"consent_required"`
+
+	// RedirectTo Client redirect URI carrying the code or error, to be opened by the user agent
+	RedirectTo *string  `json:"redirect_to,omitempty"`
+	Scopes     []string `json:"scopes"`
+}
+
+// OAuthConsentPayload defines model for OAuthConsentPayload.
+type OAuthConsentPayload struct {
+	// Approved Whether the caller grants the requested scopes to the client
+	Approved            bool    `json:"approved"`
+	ClientId            string  `json:"client_id"`
+	CodeChallenge       *string `json:"code_challenge,omitempty"`
+	CodeChallengeMethod *string `json:"code_challenge_method,omitempty"`
+	Nonce               *string `json:"nonce,omitempty"`
+	RedirectUri         *string `json:"redirect_uri,omitempty"`
+	ResponseType        *string `json:"response_type,omitempty"`
+	Scope               *string `json:"scope,omitempty"`
+	State               *string `json:"state,omitempty"`
+}
+
+// OAuthErrorResponse defines model for OAuthErrorResponse.
+type OAuthErrorResponse struct {
+	// Error RFC 6749 error code
+	Error            string  `json:"error"`
+	ErrorDescription *string `json:"error_description,omitempty"`
+}
+
+// OAuthIntrospectionResponse defines model for OAuthIntrospectionResponse.
+type OAuthIntrospectionResponse struct {
+	Active   bool      `json:"active"`
+	Aud      *[]string `json:"aud,omitempty"`
+	ClientId *string   `json:"client_id,omitempty"`
+	Exp      *int64    `json:"exp,omitempty"`
+	Iat      *int64    `json:"iat,omitempty"`
+	Iss      *string   `json:"iss,omitempty"`
+	Jti      *string   `json:"jti,omitempty"`
+	Scope    *string   `json:"scope,omitempty"`
+	Sub      *string   `json:"sub,omitempty"`
+
+	// TokenType Bearer for access tokens, refresh_token for refresh tokens
+	TokenType *string `json:"token_type,omitempty"`
+}
+
+// OAuthTokenHintPayload defines model for OAuthTokenHintPayload.
+type OAuthTokenHintPayload struct {
+	ClientId *string `json:"client_id,omitempty"`
+
+	// ClientSecret Only for confidential clients not using HTTP Basic authentication
+	ClientSecret *string `json:"client_secret,omitempty"`
+	Token        string  `json:"token"`
+
+	// TokenTypeHint access_token or refresh_token
+	TokenTypeHint *string `json:"token_type_hint,omitempty"`
+}
+
+// OAuthTokenPayload defines model for OAuthTokenPayload.
+type OAuthTokenPayload struct {
+	ClientId *string `json:"client_id,omitempty"`
+
+	// ClientSecret Only for confidential clients not using HTTP Basic authentication
+	ClientSecret *string `json:"client_secret,omitempty"`
+	Code         *string `json:"code,omitempty"`
+	CodeVerifier *string `json:"code_verifier,omitempty"`
+
+	// GrantType authorization_code, refresh_token or client_credentials
+	GrantType string `json:"grant_type"`
+
+	// RedirectUri The redirect URI the code was issued for, required when the authorization request named one
+	RedirectUri  *string `json:"redirect_uri,omitempty"`
+	RefreshToken *string `json:"refresh_token,omitempty"`
+
+	// Scope Space separated scopes for the client_credentials grant, defaults to every scope registered for the client
+	Scope *string `json:"scope,omitempty"`
+}
+
+// OAuthTokenResponse defines model for OAuthTokenResponse.
+type OAuthTokenResponse struct {
+	AccessToken string `json:"access_token"`
+
+	// ExpiresIn Access token lifetime in seconds
+	ExpiresIn int `json:"expires_in"`
+
+	// IdToken Signed OpenID Connect ID token, only issued when the openid scope is granted
+	IdToken *string `json:"id_token,omitempty"`
+
+	// RefreshToken Not issued for the client_credentials grant
+	RefreshToken *string `json:"refresh_token,omitempty"`
+	Scope        string  `json:"scope"`
+	TokenType    string  `json:"token_type"`
+}
+
+// OpenIDConfiguration defines model for OpenIDConfiguration.
+type OpenIDConfiguration struct {
+	AuthorizationEndpoint             *string   `json:"authorization_endpoint,omitempty"`
+	ClaimsSupported                   []string  `json:"claims_supported"`
+	CodeChallengeMethodsSupported     *[]string `json:"code_challenge_methods_supported,omitempty"`
+	GrantTypesSupported               *[]string `json:"grant_types_supported,omitempty"`
+	IdTokenSigningAlgValuesSupported  []string  `json:"id_token_signing_alg_values_supported"`
+	IntrospectionEndpoint             *string   `json:"introspection_endpoint,omitempty"`
+	Issuer                            string    `json:"issuer"`
+	JwksUri                           string    `json:"jwks_uri"`
+	ResponseTypesSupported            []string  `json:"response_types_supported"`
+	RevocationEndpoint                *string   `json:"revocation_endpoint,omitempty"`
+	ScopesSupported                   []string  `json:"scopes_supported"`
+	SubjectTypesSupported             []string  `json:"subject_types_supported"`
+	TokenEndpoint                     *string   `json:"token_endpoint,omitempty"`
+	TokenEndpointAuthMethodsSupported *[]string `json:"token_endpoint_auth_methods_supported,omitempty"`
+	UserinfoEndpoint                  string    `json:"userinfo_endpoint"`
+}
+
+// PasswordPolicyResponse defines model for PasswordPolicyResponse.
+type PasswordPolicyResponse struct {
+	// HistorySize How many of the latest passwords, the current one included, cannot be reused
+	HistorySize int `json:"history_size"`
+
+	// MaxAgeInDays Days after which a password has to be reset before logging in with it, 0 when passwords do not expire
+	MaxAgeInDays int `json:"max_age_in_days"`
+	MaxLength    int `json:"max_length"`
+	MinLength    int `json:"min_length"`
+
+	// RejectBreached Common and breached passwords are refused
+	RejectBreached   bool `json:"reject_breached"`
+	RequireLowercase bool `json:"require_lowercase"`
+	RequireNumber    bool `json:"require_number"`
+	RequireSpecial   bool `json:"require_special"`
+	RequireUppercase bool `json:"require_uppercase"`
+}
+
+// PasswordResetRequestPayload defines model for PasswordResetRequestPayload.
+type PasswordResetRequestPayload struct {
+	PhoneNumber string `json:"phone_number" validate:"required,min=10,max=13,phone_number"`
+}
+
+// PasswordResetVerifyPayload defines model for PasswordResetVerifyPayload.
+type PasswordResetVerifyPayload struct {
+	Code        string `json:"code" validate:"required,numeric,len=6"`
+	PhoneNumber string `json:"phone_number" validate:"required,min=10,max=13,phone_number"`
+}
+
+// PasswordResetVerifyResponse defines model for PasswordResetVerifyResponse.
+type PasswordResetVerifyResponse struct {
+	ExpiresAt  time.Time `json:"expires_at"`
+	ResetToken string    `json:"reset_token"`
+}
+
+// PhoneVerificationPayload defines model for PhoneVerificationPayload.
+type PhoneVerificationPayload struct {
+	Code        string `json:"code" validate:"required,numeric,len=6"`
 	PhoneNumber string `json:"phone_number" validate:"required,min=10,max=13,phone_number"`
 }
 
+// PhoneVerificationResendPayload defines model for PhoneVerificationResendPayload.
+type PhoneVerificationResendPayload struct {
+	PhoneNumber string `json:"phone_number" validate:"required,min=10,max=13,phone_number"`
+}
+
+// RefreshTokenPayload defines model for RefreshTokenPayload.
+type RefreshTokenPayload struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
 // RegisterUserPayload defines model for RegisterUserPayload.
 type RegisterUserPayload struct {
 	FullName    string `json:"full_name" validate:"required,min=3,max=60"`
-	Password    string `json:"password" validate:"required,min=6,max=64,pwd"`
+	Password    string `json:"password" validate:"required,max=64,pwd,not_breached_pwd"`
 	PhoneNumber string `json:"phone_number" validate:"required,min=10,max=13,phone_number"`
 }
 
+// ResetPasswordPayload defines model for ResetPasswordPayload.
+type ResetPasswordPayload struct {
+	NewPassword string `json:"new_password" validate:"required,max=64,pwd,not_breached_pwd"`
+	ResetToken  string `json:"reset_token" validate:"required"`
+}
+
 // SuccessGetUserProfileResponse defines model for SuccessGetUserProfileResponse.
 type SuccessGetUserProfileResponse struct {
 	// CreatedAt User created at
@@ -58,14 +427,24 @@ type SuccessGetUserProfileResponse struct {
 	// Guid User GUID
 	Guid openapi_types.UUID `json:"guid"`
 
+	// PendingPhoneNumber New phone number waiting to be confirmed
+	PendingPhoneNumber *string `json:"pending_phone_number,omitempty"`
+
 	// PhoneNumber User phone number
 	PhoneNumber string `json:"phone_number"`
+
+	// PhoneVerifiedAt When the phone number was verified, absent until it is
+	PhoneVerifiedAt *time.Time `json:"phone_verified_at,omitempty"`
 }
 
 // SuccessLoginUserResponse defines model for SuccessLoginUserResponse.
 type SuccessLoginUserResponse struct {
 	ExpiredAt string `json:"expired_at"`
-	Token     string `json:"token"`
+
+	// RefreshToken Opaque single-use token to obtain a new token pair
+	RefreshToken          string `json:"refresh_token"`
+	RefreshTokenExpiredAt string `json:"refresh_token_expired_at"`
+	Token                 string `json:"token"`
 }
 
 // SuccessRegisterUserResponse defines model for SuccessRegisterUserResponse.
@@ -74,35 +453,315 @@ type SuccessRegisterUserResponse struct {
 	Message string             `json:"message"`
 }
 
+// TOTPEnrollmentResponse defines model for TOTPEnrollmentResponse.
+type TOTPEnrollmentResponse struct {
+	// OtpauthUri otpauth URI to render as a QR code
+	OtpauthUri string `json:"otpauth_uri"`
+
+	// Secret Base32 secret for entering into the authenticator app by hand
+	Secret string `json:"secret"`
+}
+
+// TOTPRecoveryCodesResponse defines model for TOTPRecoveryCodesResponse.
+type TOTPRecoveryCodesResponse struct {
+	Message string `json:"message"`
+
+	// RecoveryCodes Single-use codes that can stand in for a TOTP code
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// TwoFactorChallengeResponse defines model for TwoFactorChallengeResponse.
+type TwoFactorChallengeResponse struct {
+	// ChallengeToken Opaque single-use token to pass to /login/2fa
+	ChallengeToken string    `json:"challenge_token"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	Message        string    `json:"message"`
+}
+
 // UpdateUserPayload defines model for UpdateUserPayload.
 type UpdateUserPayload struct {
 	FullName    string `json:"full_name" validate:"required,min=3,max=60"`
 	PhoneNumber string `json:"phone_number" validate:"required,min=6,max=64,phone_number"`
 }
 
+// UserInfoResponse defines model for UserInfoResponse.
+type UserInfoResponse struct {
+	// Name User full name
+	Name *string `json:"name,omitempty"`
+
+	// PhoneNumber User phone number
+	PhoneNumber *string `json:"phone_number,omitempty"`
+
+	// Sub User GUID
+	Sub string `json:"sub"`
+
+	// UpdatedAt Time the profile was last updated, in seconds since the Unix epoch
+	UpdatedAt *int64 `json:"updated_at,omitempty"`
+}
+
+// ListAdminUsersParams defines parameters for ListAdminUsers.
+type ListAdminUsersParams struct {
+	// Limit Page size, 20 by default
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Cursor next_cursor from the previous page
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+
+	// CreatedFrom Only users created at or after this time
+	CreatedFrom *time.Time `form:"created_from,omitempty" json:"created_from,omitempty"`
+
+	// CreatedTo Only users created before this time
+	CreatedTo *time.Time `form:"created_to,omitempty" json:"created_to,omitempty"`
+
+	// PhoneVerified Only users whose phone number is, or is not, verified
+	PhoneVerified *bool `form:"phone_verified,omitempty" json:"phone_verified,omitempty"`
+
+	// Disabled Only users who are, or are not, disabled
+	Disabled *bool `form:"disabled,omitempty" json:"disabled,omitempty"`
+
+	// PhonePrefix Only users whose phone number starts with this, such as +6281
+	PhonePrefix *string `form:"phone_prefix,omitempty" json:"phone_prefix,omitempty"`
+
+	// Q Case-insensitive part of the full name
+	Q *string `form:"q,omitempty" json:"q,omitempty"`
+}
+
+// VerifyForwardAuthParams defines parameters for VerifyForwardAuth.
+type VerifyForwardAuthParams struct {
+	// Redirect Answer failures with a redirect to the login page instead of 401/403, for Traefik ForwardAuth
+	Redirect *bool `form:"redirect,omitempty" json:"redirect,omitempty"`
+}
+
+// DownloadDataExportParams defines parameters for DownloadDataExport.
+type DownloadDataExportParams struct {
+	// Expires Unix time the link expires at
+	Expires int64 `form:"expires" json:"expires"`
+
+	// Signature Signature of the link
+	Signature string `form:"signature" json:"signature"`
+}
+
+// AuthorizeOAuthClientParams defines parameters for AuthorizeOAuthClient.
+type AuthorizeOAuthClientParams struct {
+	// ResponseType Must be code
+	ResponseType *string `form:"response_type,omitempty" json:"response_type,omitempty"`
+
+	// ClientId Registered client id
+	ClientId *string `form:"client_id,omitempty" json:"client_id,omitempty"`
+
+	// RedirectUri One of the redirect URIs registered for the client, optional when only one is registered
+	RedirectUri *string `form:"redirect_uri,omitempty" json:"redirect_uri,omitempty"`
+
+	// Scope Space separated scopes, defaults to every scope registered for the client
+	Scope *string `form:"scope,omitempty" json:"scope,omitempty"`
+
+	// State Opaque value returned to the client unchanged
+	State *string `form:"state,omitempty" json:"state,omitempty"`
+
+	// Nonce Opaque value repeated in the ID token issued for the code
+	Nonce *string `form:"nonce,omitempty" json:"nonce,omitempty"`
+
+	// CodeChallenge Base64url encoded SHA-256 of the PKCE code verifier
+	CodeChallenge *string `form:"code_challenge,omitempty" json:"code_challenge,omitempty"`
+
+	// CodeChallengeMethod Must be S256
+	CodeChallengeMethod *string `form:"code_challenge_method,omitempty" json:"code_challenge_method,omitempty"`
+}
+
+// UpdateAdminUserJSONRequestBody defines body for UpdateAdminUser for application/json ContentType.
+type UpdateAdminUserJSONRequestBody = UpdateUserPayload
+
 // LoginUserJSONRequestBody defines body for LoginUser for application/json ContentType.
 type LoginUserJSONRequestBody = LoginUserPayload
 
+// LoginTwoFactorJSONRequestBody defines body for LoginTwoFactor for application/json ContentType.
+type LoginTwoFactorJSONRequestBody = LoginTwoFactorPayload
+
+// RequestLoginCodeJSONRequestBody defines body for RequestLoginCode for application/json ContentType.
+type RequestLoginCodeJSONRequestBody = LoginCodeRequestPayload
+
+// LoginWithCodeJSONRequestBody defines body for LoginWithCode for application/json ContentType.
+type LoginWithCodeJSONRequestBody = LoginCodePayload
+
+// LogoutJSONRequestBody defines body for Logout for application/json ContentType.
+type LogoutJSONRequestBody = LogoutPayload
+
+// ConsentOAuthClientJSONRequestBody defines body for ConsentOAuthClient for application/json ContentType.
+type ConsentOAuthClientJSONRequestBody = OAuthConsentPayload
+
+// IntrospectOAuthTokenFormdataRequestBody defines body for IntrospectOAuthToken for application/x-www-form-urlencoded ContentType.
+type IntrospectOAuthTokenFormdataRequestBody = OAuthTokenHintPayload
+
+// RevokeOAuthTokenFormdataRequestBody defines body for RevokeOAuthToken for application/x-www-form-urlencoded ContentType.
+type RevokeOAuthTokenFormdataRequestBody = OAuthTokenHintPayload
+
+// IssueOAuthTokenFormdataRequestBody defines body for IssueOAuthToken for application/x-www-form-urlencoded ContentType.
+type IssueOAuthTokenFormdataRequestBody = OAuthTokenPayload
+
+// RequestPasswordResetJSONRequestBody defines body for RequestPasswordReset for application/json ContentType.
+type RequestPasswordResetJSONRequestBody = PasswordResetRequestPayload
+
+// VerifyPasswordResetCodeJSONRequestBody defines body for VerifyPasswordResetCode for application/json ContentType.
+type VerifyPasswordResetCodeJSONRequestBody = PasswordResetVerifyPayload
+
+// ResetPasswordJSONRequestBody defines body for ResetPassword for application/json ContentType.
+type ResetPasswordJSONRequestBody = ResetPasswordPayload
+
+// VerifyPhoneNumberJSONRequestBody defines body for VerifyPhoneNumber for application/json ContentType.
+type VerifyPhoneNumberJSONRequestBody = PhoneVerificationPayload
+
+// ResendPhoneVerificationCodeJSONRequestBody defines body for ResendPhoneVerificationCode for application/json ContentType.
+type ResendPhoneVerificationCodeJSONRequestBody = PhoneVerificationResendPayload
+
 // RegisterUserJSONRequestBody defines body for RegisterUser for application/json ContentType.
 type RegisterUserJSONRequestBody = RegisterUserPayload
 
+// RefreshTokenJSONRequestBody defines body for RefreshToken for application/json ContentType.
+type RefreshTokenJSONRequestBody = RefreshTokenPayload
+
+// DeleteAccountJSONRequestBody defines body for DeleteAccount for application/json ContentType.
+type DeleteAccountJSONRequestBody = DeleteAccountPayload
+
 // UpdateUserJSONRequestBody defines body for UpdateUser for application/json ContentType.
 type UpdateUserJSONRequestBody = UpdateUserPayload
 
+// ConfirmTOTPJSONRequestBody defines body for ConfirmTOTP for application/json ContentType.
+type ConfirmTOTPJSONRequestBody = ConfirmTOTPPayload
+
+// RequestDataExportJSONRequestBody defines body for RequestDataExport for application/json ContentType.
+type RequestDataExportJSONRequestBody = DataExportPayload
+
+// ChangePasswordJSONRequestBody defines body for ChangePassword for application/json ContentType.
+type ChangePasswordJSONRequestBody = ChangePasswordPayload
+
+// ConfirmPhoneChangeJSONRequestBody defines body for ConfirmPhoneChange for application/json ContentType.
+type ConfirmPhoneChangeJSONRequestBody = ConfirmPhoneChangePayload
+
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
+	// This is an endpoint to list the public keys access tokens can be verified with
+	// (GET /.well-known/jwks.json)
+	GetJSONWebKeySet(ctx echo.Context) error
+	// This is an endpoint to describe the service as an OpenID Connect provider
+	// (GET /.well-known/openid-configuration)
+	GetOpenIDConfiguration(ctx echo.Context) error
+	// This is an endpoint for staff with users:read to list and search accounts, newest first
+	// (GET /admin/users)
+	ListAdminUsers(ctx echo.Context, params ListAdminUsersParams) error
+	// This is an endpoint for staff with users:write to delete any other account and sign it out everywhere
+	// (DELETE /admin/users/{guid})
+	DeleteAdminUser(ctx echo.Context, guid openapi_types.UUID) error
+	// This is an endpoint for staff with users:read to look up any account
+	// (GET /admin/users/{guid})
+	GetAdminUser(ctx echo.Context, guid openapi_types.UUID) error
+	// This is an endpoint for staff with users:write to correct any account; a new phone number has to be verified again
+	// (PUT /admin/users/{guid})
+	UpdateAdminUser(ctx echo.Context, guid openapi_types.UUID) error
+	// This is an endpoint for staff with users:write to stop any other account from logging in and sign it out everywhere
+	// (POST /admin/users/{guid}/disable)
+	DisableAdminUser(ctx echo.Context, guid openapi_types.UUID) error
+	// This is an endpoint for staff with users:write to let a disabled account log in again
+	// (POST /admin/users/{guid}/enable)
+	EnableAdminUser(ctx echo.Context, guid openapi_types.UUID) error
+	// This is an endpoint for staff with users:write to lift a lockout after too many failed logins
+	// (POST /admin/users/{guid}/unlock)
+	UnlockAdminUser(ctx echo.Context, guid openapi_types.UUID) error
+	// This is an endpoint for reverse proxies to authenticate a request before forwarding it
+	// (GET /auth/verify)
+	VerifyForwardAuth(ctx echo.Context, params VerifyForwardAuthParams) error
+	// This is the signed link to download a ready export, which needs no token and stops working when it expires
+	// (GET /data-exports/{id}/download)
+	DownloadDataExport(ctx echo.Context, id openapi_types.UUID, params DownloadDataExportParams) error
 	// This is an endpoint to login user
 	// (POST /login)
 	LoginUser(ctx echo.Context) error
+	// This is an endpoint to finish a two-factor login with the challenge token and a TOTP or recovery code
+	// (POST /login/2fa)
+	LoginTwoFactor(ctx echo.Context) error
+	// This is an endpoint to text a one-time login code to the phone number if it belongs to a user
+	// (POST /login/otp)
+	RequestLoginCode(ctx echo.Context) error
+	// This is an endpoint to log in with a one-time code texted to the phone number instead of the password
+	// (POST /login/otp/verify)
+	LoginWithCode(ctx echo.Context) error
+	// This is an endpoint to revoke the caller's access token and its refresh token
+	// (POST /logout)
+	Logout(ctx echo.Context) error
+	// This is an endpoint to revoke every token issued to the caller on all devices
+	// (POST /logout/all)
+	LogoutAllDevices(ctx echo.Context) error
+	// This is an endpoint to start an OAuth2 authorization code grant for the caller
+	// (GET /oauth/authorize)
+	AuthorizeOAuthClient(ctx echo.Context, params AuthorizeOAuthClientParams) error
+	// This is an endpoint to approve or deny the consent asked by an OAuth2 client
+	// (POST /oauth/authorize)
+	ConsentOAuthClient(ctx echo.Context) error
+	// This is an endpoint for authenticated OAuth2 clients to check whether a token is active
+	// (POST /oauth/introspect)
+	IntrospectOAuthToken(ctx echo.Context) error
+	// This is an endpoint for OAuth2 clients to revoke a token issued to them
+	// (POST /oauth/revoke)
+	RevokeOAuthToken(ctx echo.Context) error
+	// This is an endpoint for OAuth2 clients to exchange a grant for tokens
+	// (POST /oauth/token)
+	IssueOAuthToken(ctx echo.Context) error
+	// This is an endpoint to describe the rules new passwords must follow
+	// (GET /password-policy)
+	GetPasswordPolicy(ctx echo.Context) error
+	// This is an endpoint to text a password reset code to the phone number if it belongs to a user
+	// (POST /password/forgot)
+	RequestPasswordReset(ctx echo.Context) error
+	// This is an endpoint to exchange a password reset code for a reset token
+	// (POST /password/forgot/verify)
+	VerifyPasswordResetCode(ctx echo.Context) error
+	// This is an endpoint to set a new password with a reset token, which signs the user out of every device
+	// (POST /password/reset)
+	ResetPassword(ctx echo.Context) error
+	// This is an endpoint to verify a registered phone number with the code sent to it
+	// (POST /phone/verify)
+	VerifyPhoneNumber(ctx echo.Context) error
+	// This is an endpoint to text a new verification code to a registered phone number that is not verified yet
+	// (POST /phone/verify/resend)
+	ResendPhoneVerificationCode(ctx echo.Context) error
 	// This is an endpoint to register user
 	// (POST /register)
 	RegisterUser(ctx echo.Context) error
+	// This is an endpoint to rotate a refresh token for a new token pair
+	// (POST /token/refresh)
+	RefreshToken(ctx echo.Context) error
+	// This is an endpoint to get the OpenID Connect claims of the token owner
+	// (GET /userinfo)
+	GetUserInfo(ctx echo.Context) error
+	// This is an endpoint to get the OpenID Connect claims of the token owner
+	// (POST /userinfo)
+	PostUserInfo(ctx echo.Context) error
+	// This is an endpoint to delete the caller's account, which can be restored by logging in until the grace period ends
+	// (DELETE /users/)
+	DeleteAccount(ctx echo.Context) error
 	// Endpoint to get user profile
 	// (GET /users/)
 	GetUserProfile(ctx echo.Context) error
 	// This is an endpoint to update user data
 	// (PUT /users/)
 	UpdateUser(ctx echo.Context) error
+	// This is an endpoint to start enrolling an authenticator app for two-factor authentication
+	// (POST /users/2fa/totp)
+	EnrollTOTP(ctx echo.Context) error
+	// This is an endpoint to enable two-factor authentication with the first code from the authenticator app
+	// (POST /users/2fa/totp/confirm)
+	ConfirmTOTP(ctx echo.Context) error
+	// This is an endpoint to request an export of everything stored about the caller, which is built in the background
+	// (POST /users/data-export)
+	RequestDataExport(ctx echo.Context) error
+	// This is an endpoint to check on an export of the caller's data and get a download link once it is ready
+	// (GET /users/data-export/{id})
+	GetDataExport(ctx echo.Context, id openapi_types.UUID) error
+	// This is an endpoint to change the caller's password, which signs them out of every device
+	// (PUT /users/password)
+	ChangePassword(ctx echo.Context) error
+	// This is an endpoint to confirm a pending phone number change with the code sent to the new number
+	// (POST /users/phone/confirm)
+	ConfirmPhoneChange(ctx echo.Context) error
 }
 
 // ServerInterfaceWrapper converts echo contexts to parameters.
@@ -110,39 +769,569 @@ type ServerInterfaceWrapper struct {
 	Handler ServerInterface
 }
 
-// LoginUser converts echo context to params.
-func (w *ServerInterfaceWrapper) LoginUser(ctx echo.Context) error {
+// GetJSONWebKeySet converts echo context to params.
+func (w *ServerInterfaceWrapper) GetJSONWebKeySet(ctx echo.Context) error {
 	var err error
 
 	// Invoke the callback with all the unmarshaled arguments
-	err = w.Handler.LoginUser(ctx)
+	err = w.Handler.GetJSONWebKeySet(ctx)
 	return err
 }
 
-// RegisterUser converts echo context to params.
-func (w *ServerInterfaceWrapper) RegisterUser(ctx echo.Context) error {
+// GetOpenIDConfiguration converts echo context to params.
+func (w *ServerInterfaceWrapper) GetOpenIDConfiguration(ctx echo.Context) error {
 	var err error
 
 	// Invoke the callback with all the unmarshaled arguments
-	err = w.Handler.RegisterUser(ctx)
+	err = w.Handler.GetOpenIDConfiguration(ctx)
 	return err
 }
 
-// GetUserProfile converts echo context to params.
-func (w *ServerInterfaceWrapper) GetUserProfile(ctx echo.Context) error {
+// ListAdminUsers converts echo context to params.
+func (w *ServerInterfaceWrapper) ListAdminUsers(ctx echo.Context) error {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListAdminUsersParams
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", ctx.QueryParams(), &params.Limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+	}
+
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", ctx.QueryParams(), &params.Cursor)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter cursor: %s", err))
+	}
+
+	// ------------- Optional query parameter "created_from" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "created_from", ctx.QueryParams(), &params.CreatedFrom)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter created_from: %s", err))
+	}
+
+	// ------------- Optional query parameter "created_to" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "created_to", ctx.QueryParams(), &params.CreatedTo)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter created_to: %s", err))
+	}
+
+	// ------------- Optional query parameter "phone_verified" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "phone_verified", ctx.QueryParams(), &params.PhoneVerified)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter phone_verified: %s", err))
+	}
+
+	// ------------- Optional query parameter "disabled" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "disabled", ctx.QueryParams(), &params.Disabled)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter disabled: %s", err))
+	}
+
+	// ------------- Optional query parameter "phone_prefix" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "phone_prefix", ctx.QueryParams(), &params.PhonePrefix)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter phone_prefix: %s", err))
+	}
+
+	// ------------- Optional query parameter "q" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "q", ctx.QueryParams(), &params.Q)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter q: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ListAdminUsers(ctx, params)
+	return err
+}
+
+// DeleteAdminUser converts echo context to params.
+func (w *ServerInterfaceWrapper) DeleteAdminUser(ctx echo.Context) error {
 	var err error
+	// ------------- Path parameter "guid" -------------
+	var guid openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "guid", runtime.ParamLocationPath, ctx.Param("guid"), &guid)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter guid: %s", err))
+	}
 
 	// Invoke the callback with all the unmarshaled arguments
-	err = w.Handler.GetUserProfile(ctx)
+	err = w.Handler.DeleteAdminUser(ctx, guid)
 	return err
 }
 
-// UpdateUser converts echo context to params.
-func (w *ServerInterfaceWrapper) UpdateUser(ctx echo.Context) error {
+// GetAdminUser converts echo context to params.
+func (w *ServerInterfaceWrapper) GetAdminUser(ctx echo.Context) error {
 	var err error
+	// ------------- Path parameter "guid" -------------
+	var guid openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "guid", runtime.ParamLocationPath, ctx.Param("guid"), &guid)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter guid: %s", err))
+	}
 
 	// Invoke the callback with all the unmarshaled arguments
-	err = w.Handler.UpdateUser(ctx)
+	err = w.Handler.GetAdminUser(ctx, guid)
+	return err
+}
+
+// UpdateAdminUser converts echo context to params.
+func (w *ServerInterfaceWrapper) UpdateAdminUser(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "guid" -------------
+	var guid openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "guid", runtime.ParamLocationPath, ctx.Param("guid"), &guid)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter guid: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.UpdateAdminUser(ctx, guid)
+	return err
+}
+
+// DisableAdminUser converts echo context to params.
+func (w *ServerInterfaceWrapper) DisableAdminUser(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "guid" -------------
+	var guid openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "guid", runtime.ParamLocationPath, ctx.Param("guid"), &guid)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter guid: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.DisableAdminUser(ctx, guid)
+	return err
+}
+
+// EnableAdminUser converts echo context to params.
+func (w *ServerInterfaceWrapper) EnableAdminUser(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "guid" -------------
+	var guid openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "guid", runtime.ParamLocationPath, ctx.Param("guid"), &guid)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter guid: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.EnableAdminUser(ctx, guid)
+	return err
+}
+
+// UnlockAdminUser converts echo context to params.
+func (w *ServerInterfaceWrapper) UnlockAdminUser(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "guid" -------------
+	var guid openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "guid", runtime.ParamLocationPath, ctx.Param("guid"), &guid)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter guid: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.UnlockAdminUser(ctx, guid)
+	return err
+}
+
+// VerifyForwardAuth converts echo context to params.
+func (w *ServerInterfaceWrapper) VerifyForwardAuth(ctx echo.Context) error {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params VerifyForwardAuthParams
+	// ------------- Optional query parameter "redirect" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "redirect", ctx.QueryParams(), &params.Redirect)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter redirect: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.VerifyForwardAuth(ctx, params)
+	return err
+}
+
+// DownloadDataExport converts echo context to params.
+func (w *ServerInterfaceWrapper) DownloadDataExport(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "id", runtime.ParamLocationPath, ctx.Param("id"), &id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params DownloadDataExportParams
+	// ------------- Required query parameter "expires" -------------
+
+	err = runtime.BindQueryParameter("form", true, true, "expires", ctx.QueryParams(), &params.Expires)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter expires: %s", err))
+	}
+
+	// ------------- Required query parameter "signature" -------------
+
+	err = runtime.BindQueryParameter("form", true, true, "signature", ctx.QueryParams(), &params.Signature)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter signature: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.DownloadDataExport(ctx, id, params)
+	return err
+}
+
+// LoginUser converts echo context to params.
+func (w *ServerInterfaceWrapper) LoginUser(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.LoginUser(ctx)
+	return err
+}
+
+// LoginTwoFactor converts echo context to params.
+func (w *ServerInterfaceWrapper) LoginTwoFactor(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.LoginTwoFactor(ctx)
+	return err
+}
+
+// RequestLoginCode converts echo context to params.
+func (w *ServerInterfaceWrapper) RequestLoginCode(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.RequestLoginCode(ctx)
+	return err
+}
+
+// LoginWithCode converts echo context to params.
+func (w *ServerInterfaceWrapper) LoginWithCode(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.LoginWithCode(ctx)
+	return err
+}
+
+// Logout converts echo context to params.
+func (w *ServerInterfaceWrapper) Logout(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.Logout(ctx)
+	return err
+}
+
+// LogoutAllDevices converts echo context to params.
+func (w *ServerInterfaceWrapper) LogoutAllDevices(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.LogoutAllDevices(ctx)
+	return err
+}
+
+// AuthorizeOAuthClient converts echo context to params.
+func (w *ServerInterfaceWrapper) AuthorizeOAuthClient(ctx echo.Context) error {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params AuthorizeOAuthClientParams
+	// ------------- Optional query parameter "response_type" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "response_type", ctx.QueryParams(), &params.ResponseType)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter response_type: %s", err))
+	}
+
+	// ------------- Optional query parameter "client_id" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "client_id", ctx.QueryParams(), &params.ClientId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter client_id: %s", err))
+	}
+
+	// ------------- Optional query parameter "redirect_uri" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "redirect_uri", ctx.QueryParams(), &params.RedirectUri)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter redirect_uri: %s", err))
+	}
+
+	// ------------- Optional query parameter "scope" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "scope", ctx.QueryParams(), &params.Scope)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter scope: %s", err))
+	}
+
+	// ------------- Optional query parameter "state" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "state", ctx.QueryParams(), &params.State)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter state: %s", err))
+	}
+
+	// ------------- Optional query parameter "nonce" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "nonce", ctx.QueryParams(), &params.Nonce)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter nonce: %s", err))
+	}
+
+	// ------------- Optional query parameter "code_challenge" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "code_challenge", ctx.QueryParams(), &params.CodeChallenge)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter code_challenge: %s", err))
+	}
+
+	// ------------- Optional query parameter "code_challenge_method" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "code_challenge_method", ctx.QueryParams(), &params.CodeChallengeMethod)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter code_challenge_method: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.AuthorizeOAuthClient(ctx, params)
+	return err
+}
+
+// ConsentOAuthClient converts echo context to params.
+func (w *ServerInterfaceWrapper) ConsentOAuthClient(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ConsentOAuthClient(ctx)
+	return err
+}
+
+// IntrospectOAuthToken converts echo context to params.
+func (w *ServerInterfaceWrapper) IntrospectOAuthToken(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.IntrospectOAuthToken(ctx)
+	return err
+}
+
+// RevokeOAuthToken converts echo context to params.
+func (w *ServerInterfaceWrapper) RevokeOAuthToken(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.RevokeOAuthToken(ctx)
+	return err
+}
+
+// IssueOAuthToken converts echo context to params.
+func (w *ServerInterfaceWrapper) IssueOAuthToken(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.IssueOAuthToken(ctx)
+	return err
+}
+
+// GetPasswordPolicy converts echo context to params.
+func (w *ServerInterfaceWrapper) GetPasswordPolicy(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetPasswordPolicy(ctx)
+	return err
+}
+
+// RequestPasswordReset converts echo context to params.
+func (w *ServerInterfaceWrapper) RequestPasswordReset(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.RequestPasswordReset(ctx)
+	return err
+}
+
+// VerifyPasswordResetCode converts echo context to params.
+func (w *ServerInterfaceWrapper) VerifyPasswordResetCode(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.VerifyPasswordResetCode(ctx)
+	return err
+}
+
+// ResetPassword converts echo context to params.
+func (w *ServerInterfaceWrapper) ResetPassword(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ResetPassword(ctx)
+	return err
+}
+
+// VerifyPhoneNumber converts echo context to params.
+func (w *ServerInterfaceWrapper) VerifyPhoneNumber(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.VerifyPhoneNumber(ctx)
+	return err
+}
+
+// ResendPhoneVerificationCode converts echo context to params.
+func (w *ServerInterfaceWrapper) ResendPhoneVerificationCode(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ResendPhoneVerificationCode(ctx)
+	return err
+}
+
+// RegisterUser converts echo context to params.
+func (w *ServerInterfaceWrapper) RegisterUser(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.RegisterUser(ctx)
+	return err
+}
+
+// RefreshToken converts echo context to params.
+func (w *ServerInterfaceWrapper) RefreshToken(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.RefreshToken(ctx)
+	return err
+}
+
+// GetUserInfo converts echo context to params.
+func (w *ServerInterfaceWrapper) GetUserInfo(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetUserInfo(ctx)
+	return err
+}
+
+// PostUserInfo converts echo context to params.
+func (w *ServerInterfaceWrapper) PostUserInfo(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PostUserInfo(ctx)
+	return err
+}
+
+// DeleteAccount converts echo context to params.
+func (w *ServerInterfaceWrapper) DeleteAccount(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.DeleteAccount(ctx)
+	return err
+}
+
+// GetUserProfile converts echo context to params.
+func (w *ServerInterfaceWrapper) GetUserProfile(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetUserProfile(ctx)
+	return err
+}
+
+// UpdateUser converts echo context to params.
+func (w *ServerInterfaceWrapper) UpdateUser(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.UpdateUser(ctx)
+	return err
+}
+
+// EnrollTOTP converts echo context to params.
+func (w *ServerInterfaceWrapper) EnrollTOTP(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.EnrollTOTP(ctx)
+	return err
+}
+
+// ConfirmTOTP converts echo context to params.
+func (w *ServerInterfaceWrapper) ConfirmTOTP(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ConfirmTOTP(ctx)
+	return err
+}
+
+// RequestDataExport converts echo context to params.
+func (w *ServerInterfaceWrapper) RequestDataExport(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.RequestDataExport(ctx)
+	return err
+}
+
+// GetDataExport converts echo context to params.
+func (w *ServerInterfaceWrapper) GetDataExport(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "id", runtime.ParamLocationPath, ctx.Param("id"), &id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetDataExport(ctx, id)
+	return err
+}
+
+// ChangePassword converts echo context to params.
+func (w *ServerInterfaceWrapper) ChangePassword(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ChangePassword(ctx)
+	return err
+}
+
+// ConfirmPhoneChange converts echo context to params.
+func (w *ServerInterfaceWrapper) ConfirmPhoneChange(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ConfirmPhoneChange(ctx)
 	return err
 }
 
@@ -174,32 +1363,163 @@ func RegisterHandlersWithBaseURL(router EchoRouter, si ServerInterface, baseURL
 		Handler: si,
 	}
 
+	router.GET(baseURL+"/.well-known/jwks.json", wrapper.GetJSONWebKeySet)
+	router.GET(baseURL+"/.well-known/openid-configuration", wrapper.GetOpenIDConfiguration)
+	router.GET(baseURL+"/admin/users", wrapper.ListAdminUsers)
+	router.DELETE(baseURL+"/admin/users/:guid", wrapper.DeleteAdminUser)
+	router.GET(baseURL+"/admin/users/:guid", wrapper.GetAdminUser)
+	router.PUT(baseURL+"/admin/users/:guid", wrapper.UpdateAdminUser)
+	router.POST(baseURL+"/admin/users/:guid/disable", wrapper.DisableAdminUser)
+	router.POST(baseURL+"/admin/users/:guid/enable", wrapper.EnableAdminUser)
+	router.POST(baseURL+"/admin/users/:guid/unlock", wrapper.UnlockAdminUser)
+	router.GET(baseURL+"/auth/verify", wrapper.VerifyForwardAuth)
+	router.GET(baseURL+"/data-exports/:id/download", wrapper.DownloadDataExport)
 	router.POST(baseURL+"/login", wrapper.LoginUser)
+	router.POST(baseURL+"/login/2fa", wrapper.LoginTwoFactor)
+	router.POST(baseURL+"/login/otp", wrapper.RequestLoginCode)
+	router.POST(baseURL+"/login/otp/verify", wrapper.LoginWithCode)
+	router.POST(baseURL+"/logout", wrapper.Logout)
+	router.POST(baseURL+"/logout/all", wrapper.LogoutAllDevices)
+	router.GET(baseURL+"/oauth/authorize", wrapper.AuthorizeOAuthClient)
+	router.POST(baseURL+"/oauth/authorize", wrapper.ConsentOAuthClient)
+	router.POST(baseURL+"/oauth/introspect", wrapper.IntrospectOAuthToken)
+	router.POST(baseURL+"/oauth/revoke", wrapper.RevokeOAuthToken)
+	router.POST(baseURL+"/oauth/token", wrapper.IssueOAuthToken)
+	router.GET(baseURL+"/password-policy", wrapper.GetPasswordPolicy)
+	router.POST(baseURL+"/password/forgot", wrapper.RequestPasswordReset)
+	router.POST(baseURL+"/password/forgot/verify", wrapper.VerifyPasswordResetCode)
+	router.POST(baseURL+"/password/reset", wrapper.ResetPassword)
+	router.POST(baseURL+"/phone/verify", wrapper.VerifyPhoneNumber)
+	router.POST(baseURL+"/phone/verify/resend", wrapper.ResendPhoneVerificationCode)
 	router.POST(baseURL+"/register", wrapper.RegisterUser)
+	router.POST(baseURL+"/token/refresh", wrapper.RefreshToken)
+	router.GET(baseURL+"/userinfo", wrapper.GetUserInfo)
+	router.POST(baseURL+"/userinfo", wrapper.PostUserInfo)
+	router.DELETE(baseURL+"/users/", wrapper.DeleteAccount)
 	router.GET(baseURL+"/users/", wrapper.GetUserProfile)
 	router.PUT(baseURL+"/users/", wrapper.UpdateUser)
+	router.POST(baseURL+"/users/2fa/totp", wrapper.EnrollTOTP)
+	router.POST(baseURL+"/users/2fa/totp/confirm", wrapper.ConfirmTOTP)
+	router.POST(baseURL+"/users/data-export", wrapper.RequestDataExport)
+	router.GET(baseURL+"/users/data-export/:id", wrapper.GetDataExport)
+	router.PUT(baseURL+"/users/password", wrapper.ChangePassword)
+	router.POST(baseURL+"/users/phone/confirm", wrapper.ConfirmPhoneChange)
 
 }
 
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/+RY32vjOBD+V4TuHu213XQb1rAvy5alsAdHt+UeSimqNbG1Z0s6Se4PQv73Q2MnthPH",
-	"vYMk5bg3Nxppvvlm5puhS5qpSisJ0lmaLqnNCqgYfn6FBatLd6s5c3ANVitpwR9oozQYJwDNKrCW5Xjg",
-	"XjXQlFpnhMzpahVQA3/VwgCn6d3G8D5YG6rHn5A5ugropTHKnMLHH8IVly/OsP3OwB/TdLkKDuP3u8qF",
-	"vLVgfmevpWJ816Nm1j4rw3f9BPQlVEyLMFMccpAhggsdy/HiEyuFzw5NN2iCSsjPF0HFXj5fnAf6mSNW",
-	"XSgJD7KuHsEczEsSo5tkFgyeX22TMzgNumjHyLqGXFgHZpKvRV2WD5JVcLBQZg1hcUPW/yodHZlvpOZH",
-	"nWVg7TdwmByjFqKc0IXMAHPAH5jzf3GwmRHaCSVpSv0DpDUgzHkUylTekvrIQicQzlbPBcPEjzzpz0kb",
-	"y87lvBZ8z71vt1df+yBqbzryxHbeRp5CE7Jhd1o28sbPIAV9FxNp2KjKlJBp76jNQCXkd5C5K2iajITm",
-	"1J8g37Tbwt9cCvqeJiD3e7uPGl5Ypcs1ZvxO/DdmJaXJp8eLs9nHeTi/gFl4nvHz8NMsXoTxx2zO+Dw5",
-	"g2ROe1rdBzskpHlw+Xae/7Hut4UzJf/N/HxXPTuG2nSaNik2/6K0/VUhF8p7LUUGbXU0vNDfrm6wTIUr",
-	"Yd1rP8A8icw//QTGNk2YfIg/xN5SaZBMC5rSGf7k1c0VGFNU+ubBPCiLzeGzwXwbX3GadhObNsGAdV8U",
-	"f0VVU9KBxDtM61JkeCv6aZXs1if/9auBBU3pL1G3X0XtchXtbAQYu62riplXX5Y+OMRIWvdEry09oqZ1",
-	"MJSzOD4YrL3KgvCGWtfaejznB4Qw3ANH/H5hnFw3nNAhaTeFsERYwiQBybUS0hGnWho9o2gfmVaE9me/",
-	"L1NHKoCxLWerBtY4iVpPSiLhGQN5j6IY1e4T18Xu7n6IAtkQ3dWI/7KRx5TDSIEMNyB6fPL3rFxv0Z+c",
-	"ri2vJM4JcoMLAXqfnc47NgVXYIlUjhTsCQhreRhUwGUv7Tm4ppn0Jo3NyLvD58L1z/d+gNYjVdDN9SOJ",
-	"xO7isD0m0OD0kjD+j4H/8pDoM8mZYy3TYPxeQdO7Ja1NSVNaOKfTKCpVxsrCD4/V/ervAAAA//9wZlxV",
-	"QhEAAA==",
+	"H4sIAAAAAAAC/+w9aXPcNpZ/BcXdqv2w7EiWHWejrXxwbE+inUziteWdqZ1KdUHk625EbIABQLU6Lv/3",
+	"rYeDBEmQTR3dkrP6MDWxGsTx7gsPn5JMrEvBgWuVnH5KVLaCNTX/+SrLRMX1GyhAM8HfgyoFV4A/lVKU",
+	"IDUDM3ANStGl+UFvS0hOE6Ul48vkc5qUlVzCnGr8MQeVSVbiZMlp8vcVcKJXQEqQSnBakJxqSjasKMgF",
+	"EJBUQU4WQpKlEHlKKq5ZgR9wUojlkvElYZxklBMJSgsJZjJqN52kyULINa6b5FTDTLM1JGl3f5/TRMLv",
+	"FZOQJ6f/rA8SbPvX+htx8RtkGs/0Kl8z/lGB/IkpPQwWDtd6nlVSCdk//TuqFKGK2N+JFmQJ2pwAPyMl",
+	"XUJK6IUCromwcCqosj/0z5EmlQJpVmUa1uY//lXCIjlN/uWoQfCRw+5RfYIP1XpN5Tb5XE9JpaTbHmTs",
+	"9KPAGAZEJoFqyKNUgF8SN4DQqXhLk5wpelFAPkxaStPFgvhxIXHUgN2sWAGEaUNFhUCCmryBRVUUc07X",
+	"MHAm/J1wGv94WbF84LsfPp69CTdR4dDIFCXwnPHlvFwJDnNerS8gQmY/w4aYEcSOIBvKNLKOFshkmeAL",
+	"JtcQX2F0ZrPZcOrhKa5AsgUbw5URA+1tKuI/q9FlJQDThKnJaJKiANVf9We6BkXEwqxsxpClpByJUAvz",
+	"R6T4JG3YqTfzKL8sLdYaIumA028sDZljlL08pz5x10G468un/RtT5A5KfL2ifAmotzZC5u/othA0j5Bj",
+	"JSVwPS/dwD7vpMn1TNCSzTKRwxL4DK61pDNNl2aCK1owPFZy2uwfD8Nhc6+Tpmt6/d3LF2m5yVMu9PxC",
+	"As1WkM/NH2DBoMi/e21P40+dfO6CtXfczkajkLRS9x2C30N1CJoih3s5LK/WIFmWFsC/exk5Bq4zstfz",
+	"X87fPd5NvqGavr0uhdSDe/Qc02XBV0QxviyA/NeHX34muciqNaAME5JQ8r9n7wiV2YpdAdkwvSKCu4EL",
+	"FG0lSHLJeI56BG3XJE2AV2vc6m9K8CRN/mBlsOHbA0ZwEIvvcFKCU/Yg4043DpsRA02sywIaJTJNRrcV",
+	"z7RvcrHhiKB5JYs+Mj6wJYecFIxfejXswJ8SwYtto1KYIhJovt21xhyuSyZB3WiT7W8iIpvaxWvaYIrk",
+	"YAA4XcHV9DiFZD6niVVpOxWX0lRXKpzXGYpJikjPQCn7Dw++BWWF2bc9dR5ZvENsLDhlveBO9fEGFrQq",
+	"9McSwXIbX3LAV4uvhchw/uugSNiDMulz5qgieCulkHuGhVnj70yv3uIZhhczR0xOP31O72ddFJN/h4u/",
+	"QsRkpcUyzvzomdBiKSTTq3VKaLGhW0Xefzj5+mWUUfuzfE8VvHxRyYIAR+zlpKwuCpYRuLZOcGyey5i9",
+	"+FfYEpaTNdXZCveF0uiS5WQFNAfpfQctLoFH59Tb+Jw4Mjjaq9jHfMrB1iKvikrFvq9UBDTvLCAuYYvO",
+	"Tb0FxZY7jUc8jJ01NcizIMN9psku9H8A3aeAS9hOj1Y0c+30u8y8sf38JJaMvxb5A5hafW/i7tKG8e+e",
+	"HRuh8+x52pq+L4E6Vv6QEVVD6D38XoEakZuP5jSDxzjfiL/QTAs5jO0VLQrgS5hbDr4vL8VTUZv1nBtB",
+	"8FeykGJtbZtKr4BrllGN5mZZJnc3Fedop4pKf/ceMnEFcosYTcUaGa3U2whxSjdwHt/6LxzqOIkbaY6h",
+	"UhOb3XgXtncYtIq40IRqsqI8v8+zdc4U0s9JxG3ooHqQatCtP7C1cGjZkCYuWD73gfIewt/3o+mELdDo",
+	"3tDaziWU54RpEzXLjCfERE5W1KIceB6GFC+EKIDyZIdkGjWVfhJLUQ3LJAkLCWrVMHP3TOZnq609OTvX",
+	"nShQigmeEglX4tJEAJegVyCtz+fgAEoNKfvPkf3+8qrSK/yfkOyPEXs3KxiGD1gejTG6X30grP87Tsr1",
+	"vIFq9+TnsoKGSTPkBGnwpAUyqRRXFtMqEyUocgELxD21koopwpSqorhEVOZMQqbnWkQkntk58WPIx/dn",
+	"JKNSbr0pZRYQkoCUQqYuHi1KQAfwYluHYAldDhhtdsctI+JmMdoe8NIAG23Y16v9OoTq13ayQQJ1sM6j",
+	"HqUhtgA/JhStnMw1yhhyjyHnF9vdRfGyg6REDvNaJE4YMl+DXon4ZFzwLD5HTRyVZAMDLEvM7S+fBhAc",
+	"/0VTHfuli+AAmzX8BzG4wxkzdBqRLH95TV5+8+JbS8eGqqOeCv46b326a/d2wcHtnnEthSohG8/N0kyz",
+	"qxBWAaHQKr8J++yiLLguWyEKxvXLFw0wGNewRCWUJozqqSNVfGe/aXZTmqku4mdEmV4TYcfnAiox2SBk",
+	"SwMoVBWBvjEDZKhi1E6PyuFlEL3nOM2PbESiTNIcCjIJOmbVFVuzbZMBzIFrRgsnVKwOrxRK6h/Pz9+R",
+	"76liWWjgMRF1eQcs6RaQ5ysWMzoseB04G2jOhzVuCMxhu66B5RcEx7gn6gWzSw3J6AijOQaomTprxKxr",
+	"rP0uHQvptj7PJLjDROMLXenesTmM3goUf63vN9RbFAi0lHgktt2IepNe+5lMX04Ej6d4u7bfsFToRJxK",
+	"tF0VlFTSQMMiOhsVG4LCKuaU5DaWaZQxGI/IfEokLJnSICHvTLI7O9fgbZyMxyR9w0JD8tmEtVnEQH4V",
+	"iDdSsAVotgbCOFGQCZ6ruHDOh+xtF8j/pQR+9oa8FpwjKZy9sQu4YL4jhBr1ogTOHBbQ9HQJ+Uko72T3",
+	"hQ7IbBSZg5blBGWxS8IH+Gh92kKFXy6KdgM/k4JbVpJ6u6GD9xZbA89L4URsRJhRtlZzVZWlkBpuqv1j",
+	"ZuGtZ2so/tZTePqbKxs6ntNiOb+iRXWHKUOrahyYhsDiUvi3zaWaZvjeeqPoq2YTUG5l2m1XUZWhxbtt",
+	"1eJodJftIXMk6bvSFzqPjC/E2MLdxJbFaIC/2DQjGBwG2FRijSAswrYxWVEXZoiCZdthNbFiSgu5nSv2",
+	"R0Ql/ig2ZE351sdICqrBVB3ayVXaipwIjjoiK6oc8pRklHOh0Y2XUCnIozpjTa/ndAlzxuc53UYqs95g",
+	"ToIuNIZfVixbEVqv7uMWZgUF2kcrgnJQE7FhOiXHVq/UGye5sKEpI3kHt4aiTa8CUgl/Z3z0dwkG9b6O",
+	"JBITEeu14CZ05gcFG6QSj7VoQ64VbjGUOi/EBmRG1YBT54f1IoqRMSjoGC3GB1VlObxeNyHYgKgFz9hs",
+	"sRP1tt/fax/OfaJK21Q+xi7vkZC+7IxH6yj/g97B9inJlcNUWI2lxG9eO2IE06AR3tl1OLhVdhLdOh7R",
+	"bNm5jk9IHkJyF1KIbZ5/oeztchfjQYwdPvCtkpk9Yg2XiO/Uur+jebRWZe+9QPq5TacdWwI9UIXoo+OG",
+	"VmHvWCbNiL6dZbyHrLb1eclhwXk/9BsK251Fuh8q47//ANqQsxRY8nnYOy5PV0z+fGX2I6RW1yDsskj8",
+	"KdeM/+T8kWc3D5L9UtLfK3Dlz7NKuVo2RJu40JRxQgmHjftrSRmC/yZLzm+023qXo+Oigf80hEv33COb",
+	"GsFFqMpCdMA1xVJpFfz3M/xvw1KnybNvL16ePP/6m9k3L+H57EWWv5h9+/x4MTv+OvuG5t88O4Fn3yRB",
+	"gWV4rjam7YQTCn4nF2s6rh+r2cQq/7dciqJYAx+pFxe6NCGaaPTf/WgD/4JI4DlIQhWh5L/fDyZIhxIs",
+	"WPb4/ITYn000F7gGad19XyPeKz262MZrjrowcaumrRMNQSasqFK3uw3bqrVSsbh5zYxmBNErau9PKW0q",
+	"bmyukRLcj4flLcsfOnvZQRm+oO61j/+OqMJ+ad1kyYMqGf//qEBheHSyoLsL9Kcp08l80t1+a7lxKNni",
+	"9gc1f/dhkb6sjbdRg/RGOg+BdMYXYpiMbmv43INN4WoFhk2n3heVwXzc/DjHVJoxP6wRaSwPc5nbfZUG",
+	"iTbkiswO/8jZNYFSZKsk3V0r0ZVs1UUE7p9NimNh6rUKloEDvAV18rezcyM1mC7AH/gDyCuWIaCvQCp7",
+	"oGdfHX91jCNFCZyWLDlNnps/oeehVwZ7R19toChml1xs+BHG078y91tOPyVLK+YR1cY5P8uT0+QH0O2K",
+	"8SbKbqY7OT5OTGSDa7ChfFqWhXPvj/zUtmB8ejn5B3Aw6UhhawMYmCp/7TY5X5lyOEI58bkAlFQFU/be",
+	"flmX16t2tYiR3xdQG5smSG0mbwHJpj9nWTfbNwSvWHJwj1CLLXdX2NlPLyy5K0tpxlLg3dQxFm+x3NH5",
+	"Ec3XjB/VLQ+iEMLeDPXlaWVIU9I1aPPNP/vtGJaokP6AlJwco/ngUvwJ8ktymvxegdwmqeeUgq2ZNska",
+	"D7o1vWZrvHD17PjYmMnuXzFG7S4eNIpoisNLCVdMVMp3fIhtw37T2kdPt0UrVgzoApfUXHc0OReNmHI6",
+	"NLqk83Nxn62Fp/lKE3bjkjpTN6LF/W5jsxKq4ykyZe6D2nr2tObjgY21HdIYcoLsyfhGCJVglqYS7Nr+",
+	"wv3A2sHPt1+1e3ylqdTKF0MjMFSFeTlF/v3lyX88GwVDKWHBrm9Goa+pghnjCrhiWCRHSiq1T0iGqj+2",
+	"7O9drvS+5MvjPh38ukd5GW8SMyYx0+TFPa7fLmeNrHvGjb2HV5mR8YV0vWjsRp4dfiMmwm1Xf3641c+b",
+	"suuCZpfKtyRaM3MrAOFiC3R2azR0zWxfDMMrhqFOJdC8thPQh1OA14b91QqVYpAFlCYLJpVO0sRa4/9M",
+	"jJJLfu0qvKNPGGL6bM3MAjT0VZ+7/uoJcJ9WQfxW7yOi8g8GH640wQIMMcwkERvukUBWIOGJ7gfoHjf2",
+	"4nAbM26Huzu2EBXPb8t5G8k0WDPToN2UtZirFh7thh3ZkhOmiai0reXcGGLo82E6aIkfhNP6fbZ2cdkT",
+	"Kf9pSLlWIkJckqo0pNx0uutT6qivgzEMb07h9DjxmvLAzUAvvjGpXEqjiS5oWUHU5o7HqNHMKqsI61it",
+	"0eYeU4jzvci394alflDucxsLBmwuFmMBUvqBj4qfD6g1v6c5cUVRT7Lk0coS3MG3hwVNyzG8gELwpb1K",
+	"yq1mRfa5s77OhDRXRwIp958uGdlavykHrUNrdEkZn2xEHzl/2d7vfmwiU6iIzHxjd/xITY4HM+wtVPqW",
+	"/ZP0+rMa9UqLMmLSm/hpUBB+IxN/QEoA/8KExFv+iGXEEzP++ZixAE1o0w3WM6Nt+npTpVzxQmSXXxC7",
+	"fTQbfmK3J3Y7FLuxBfIbUh3qNJe5E8Je3bIdHYmpoFFDbFfp1ZGxmreDGVR7PeIvQm6ozPEC8q4k6iuu",
+	"NlidQVlRSXAZI9pcBXflWmZjJq1JGFcaQxtiQV4cPzt6cfzcdpU6lxQW7JK0F4/le/zk4xmvgURPlG3S",
+	"sBOL6expk9R1W5p/zBCjMyN/0Lxw/8aW4q45oM0X/mNmm+DMzt6YQ61ptmIcfIeIz2ny/Pgk1qtoEFzm",
+	"SlsNTqYIirCDs/nfmOkhmhLm+F3IoHXSJfCvyD9miLJZfZSMSslARc9jLhEq4useID+45PByS1u5NYk5",
+	"JRqSytT0XDPblicoQwRD9rZ3gctnLywpG7NUm0WOcqrpDExvXnX0yfijrnXtIE++cQOarr67mNJUEWlf",
+	"gmRa67p6NluKHmMqN2CathwuSIp1BaC6knVDOdzNwBaUHzu6ibsldEWmQc+UlkDXbXKqD3fBOJWRLsNx",
+	"9VM3KzY9ooU0DaRzcHX0/h2PpqWTW+YhNKUhBCTthocxnOKbAB9aR1pabmlJ3BLHkDdfYp9tKPUAayJM",
+	"VbtztOejulOz5bLUXSnmADmu5OpOjY+oRanIRshLxJQRTEyThhG8EkVNPHOFfLtD7f5YedwkvatBikLE",
+	"iFMcHbdN60sFe4qu9xondoLrxjK3It8LxENE1wevVIybyyfHJ/e2hZGS6cgm/IUsUzpYmqJQ6mpCycJM",
+	"Y3ueW1pBGl8wztTKGllBufSDpwoOKMjetau0jOxoqixNr/iG9IzKY9qad62WAk7opaQ2umPvmtx3vH3o",
+	"ea0h3WKHB73myUXl7wa4N7Ncb09jqYYxMPS/LaF0un8SBfZcJ4fMI3hHxQkERYR1Gmqnpe7a47fZauJD",
+	"zt6lRIKW27pmEch7/Pfslfm3NcInlu8a+qjTFgEjjYvUmrv3KVd7bYw7wlVvxMwJB29VP1rR+iC1beaG",
+	"zEOFQYT0koXUd0om+xiNgKekh+a6L21nXmPJuMtBxkcJ+jWH5C10OUzeTpjX7cD3SeCRduMdErcHxgNM",
+	"sB9ODl/X9qpW1xvXx9WYrbp/39Sob98f7mHV9BTq0/gMISWC2ypqEiBCi8jpTIfoMCPcE6pCl0HIaUS2",
+	"4msVh6C83ST3ZKpGNoGg++LM1JhQ9lJxipFQN5cKWMIyA1wHrwa2eaKJboY2Z80TotKjnIC/740Fgm7q",
+	"ffrHqPIh3bVHX0b8iAqiphCsDccGgex/a99Jq9v3t7oWh4R5RItiF3G+Koo3gHe21OMrMf8i8GN7x1qM",
+	"uH6lWgRYI4ITWhQkd1A2+BEmd+O7fsJgqLh+fMD2p/d9aEfjVX+rTMC6vlkeTbiEfdtvdLHnfdMe13ly",
+	"bOgiU9i6/UaX24LHSpoGxGq4M29KhPmYFj4bUWx9SqL5aEfyyfWKvMFG462Hb9dZOLYz89UNYWdv5ZuW",
+	"lESCriQP6NHiq+KZea9yCCC2Mf9dli3tJUBmQ+Vnb9rcUZ99mD7tkwQ32kL/aa0PP76anXz90tPSu7++",
+	"fmu1fd18e4Bs248r3GgXnvfcY2e7p/cPM9xfQuSGN4LjT5zEovuseeGi8y5I9E0Q+9/cdOTpP1uyxDuB",
+	"9rbUPZsBkXcgYvl8bi5s+6MI2Zzm4/uzL0H9mBud5pY1Hvik03TdYMG0Z274zSAAjxa3B9zrJ21Nsw/D",
+	"NfbWSsd89YRzSPt1Oiuc34QHcuCMFk9Ufjsq988bCYmA3LakClWXNkzeMIE9aGhjNc3Ahy3h5hmWpkf/",
+	"ZNq/nm02mxmm+WaVLJzyuSEiew+FdNih1dH88EwRf6Zm2HpPCeP2bRTfPcNYZIZFiPthQQsFD8MVngzl",
+	"fnzBaZtwb2u1HxJxGZSJ5SvBt/hOQ8gBRsdmK8gu65Amra0wh4KQS6wvMxZPxt8fKXc0bfSnskZfnNew",
+	"8UVQwjYXNNlIe8W+bbI/Ue4dKLdPq86ZpjFHeh1Sat2ObECU44cPSadDWT5zrINL7vZzM4eLxd2KnJHn",
+	"jMn6J6JruLbONqGhNe6KR5GqfUB5Vpo3H8aaRbVfh9hnuG7gHYp7bRUlqwKUvQ9YP5+wRvd5IYpCbNrQ",
+	"OVoIuRR6Z8Kz1Rp+T+7L2KsDHa7323dPXTwlPx8g+dnBwe2Snx063JkC9S84BJSyx2ToyOMR4wR5sPzo",
+	"2JMNj7Dk5FYJxkDUx8Bse77aPwSZmpqyzC9jAi7o+L4nOop2le8Z23iA+oBPicU42XTxvDucZ27fheqw",
+	"uXpTT+VrnxVbctVcmsNMr1i4XIPNMzniQgE3VVjh2J/DF3vuXUwNPX7SFVI4jlwFA5/I7M7SyRIBoYEK",
+	"77TSr+vRUFrZsL2or7mElGREFc/HZRXPe+jepwYcfy1mN4HZI/0pLDNTF1Dxunnll2Kooexr4cSbasM0",
+	"azqrd2vGt+Bo1n81RqjNCwF706n993R6KtUOQfTajqgGFka0P8B9i+izCQcWg1g9+Bb7mt+knudh69/r",
+	"pr91gbspiXdRw5Bs76/wvaacxlMxdsKRKwkao/zmQaq9UX7/zase5QeVS1/C3aLHQegPkLNLmws2QYTc",
+	"vhEaKUDbTblC+xu2IQlYJ6nzYo2ha/+G61iAzL9HsM/QWO/Ngy+7iG0J1pzotGq3r9X60hmLC7HhY+UD",
+	"74R6QsAhEOC5QR1N6B9c95fch4RvrTEg4n3fVjvoMF0Zp1/KqxPWxvupX7EvQSpTTphTTcnG3cwDSdVD",
+	"29P/r7rB1PdpmSIbKdw7PxPSDL5DdKtwGmnCB1Dcax4Ddy3tA3L4/VLSzJADEzmuMXqLfEwtubcHk/2b",
+	"NAOPHT61GHKrG7cqF2C9xhW9AldV3yGutx0hbb4razQOUsFIl9yHbJAbtsZ9uhVyl3BFCEnUD6FGPlnQ",
+	"Iz16M9M+zYe3O/cpCwYeAXzcQuCQ7ntzG7eT70d8F67fCLfvsNygHBcMzFGNUB550tBEA4aWHhYqffo6",
+	"ci+yDtPZazugJrT7FznBCsNVvDjC3mU+hLAZfuFxp+1nr5sEd63du41UAlErLKJ1t0oyeNCb8KlrqQM1",
+	"dyMxuu5ET1x8Ry62g4d5tKEVc3fBZXZ95K/H8FNYOmggtrO8pdUxbC8eXb3AkDuHDpHd7WGSJfWGJnSe",
+	"+r2CCiNUpSjM+88GWT+8PSd9UJtWbU83UHeFl62diH+2EPZZZr1CHeecJ3qBCejG3fJOFlPkomKF9hfB",
+	"Lmh2uZSmeebN+MIiayTw1+GLfZmyk2jxERlUD9v7bRqN2SJ1wdtE1vLdjczBpObSdij2feFMozhUyPat",
+	"d9sl7jG3ebOEXbcQOP0UdxZfmzKiPdf6tBcZMuDMoLCc6clxfIrLJa8rKYEHVWA3i885qmrxuJ+qV+G0",
+	"jlY3TdAftmJlqp9kCkgsT+zXXQoW2uE1mQOQmgWfOG6oAMq7RC2APR6naBJLOJxTv+l2nYs7U7xEC/+A",
+	"GdPmKfQB1sCNgLzy6q+SRXKarLQuT4+OCpHRYiVQlP36+f8GABg2KvJQvAAA",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/tools"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// DeleteAccount deletes the caller's account once they confirm their
+// password, and signs them out everywhere. Their data is kept for the grace
+// period so that logging in again can undo it.
+func (s *Server) DeleteAccount(ctx echo.Context) error {
+	rCtx := ctx.Request().Context()
+	guid := uuid.MustParse(ctx.Get("UserGUID").(string))
+	var errData *tools.Err
+
+	var input generated.DeleteAccountJSONRequestBody
+	err := ctx.Bind(&input)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	err = tools.ValidateRequestPayload(input)
+	if err != nil && errors.As(err, &errData) {
+		return ctx.JSON(errData.Code, generated.ErrorWithExtraResponse{Message: errData.Message, Extra: &errData.Extra})
+	}
+
+	user, err := s.Repository.GetUserByGUID(rCtx, guid)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ctx.JSON(http.StatusNotFound, generated.ErrorResponse{Message: "user is not found"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	if !tools.IsValidPassword(user.Password, input.Password) {
+		return ctx.JSON(http.StatusForbidden, generated.ErrorResponse{Message: "invalid password"})
+	}
+
+	deleted, err := s.Repository.DeleteUser(rCtx, user.ID, guid)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+	if !deleted {
+		return ctx.JSON(http.StatusNotFound, generated.ErrorResponse{Message: "user is not found"})
+	}
+
+	err = s.revokeAllUserTokens(rCtx, guid)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, generated.AccountDeletionResponse{
+		Message: "account deleted, log in again before it is purged to restore it",
+		PurgeAt: s.accountPurgeAt(time.Now().UTC()),
+	})
+}
+
+// accountDeletionGracePeriod is how long a deleted account can be restored.
+func (s *Server) accountDeletionGracePeriod() time.Duration {
+	return time.Duration(s.Config.AccountDeletionGracePeriodInDays) * 24 * time.Hour
+}
+
+// accountPurgeAt is when the account deleted at deletedAt becomes due for
+// purging.
+func (s *Server) accountPurgeAt(deletedAt time.Time) time.Time {
+	return deletedAt.Add(s.accountDeletionGracePeriod())
+}
+
+// getRestorableUserLogin finds a deleted account for phoneNumber that is still
+// within its grace period. Without a grace period there is none to find.
+func (s *Server) getRestorableUserLogin(ctx context.Context, phoneNumber string) (output repository.LoginUserOutput, err error) {
+	if s.Config.AccountDeletionGracePeriodInDays <= 0 {
+		return output, sql.ErrNoRows
+	}
+	return s.Repository.GetDeletedUserLoginByPhoneNumber(ctx, phoneNumber, time.Now().UTC().Add(-s.accountDeletionGracePeriod()))
+}
+
+// restoreAccount undoes the deletion of the account of a login whose
+// password was accepted.
+func (s *Server) restoreAccount(ctx context.Context, userID int) error {
+	var errData *tools.Err
+
+	restored, err := s.Repository.RestoreUser(ctx, userID)
+	if err != nil {
+		if errors.As(err, &errData) && errData.Code == http.StatusConflict {
+			return &tools.Err{Code: http.StatusConflict, Message: "phone number now belongs to another account, the account cannot be restored"}
+		}
+		return err
+	}
+	if !restored {
+		return &tools.Err{Code: http.StatusNotFound, Message: "user is not found"}
+	}
+	return nil
+}
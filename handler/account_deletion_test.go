@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/config"
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/tools"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func MockAccountDeletionConfig() config.Config {
+	return config.Config{
+		RSAPrivateKey:                    tools.MockRSAPrivateKey(),
+		JWTTokenLifetimeInHours:          8,
+		RefreshTokenLifetimeInHours:      720,
+		AccountDeletionGracePeriodInDays: 30,
+	}
+}
+
+func TestDeleteAccount(t *testing.T) {
+	mockUser := MockUser()
+	hashedPassword, _ := tools.HashPassword(mockUser.Password)
+	mockUser.Password = hashedPassword
+
+	deleteRequest := func(password string) (echo.Context, *httptest.ResponseRecorder) {
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          echo.New(),
+			httpMethod: http.MethodDelete,
+			url:        "/users",
+			body:       []byte(`{"password": "` + password + `"}`),
+		})
+		ctx.Set("UserGUID", mockUser.GUID.String())
+		return ctx, rec
+	}
+
+	t.Run("when success delete the account and sign it out", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), mockUser.GUID).Return(mockUser, nil)
+		mockRepo.EXPECT().DeleteUser(gomock.Any(), mockUser.ID, mockUser.GUID).Return(true, nil)
+		mockRepo.EXPECT().RevokeUserRefreshTokens(gomock.Any(), mockUser.GUID).Return(nil)
+		mockRevocations := repository.NewMockTokenRevocationRepositoryInterface(ctrl)
+		mockRevocations.EXPECT().RevokeUserAccessTokens(gomock.Any(), mockUser.GUID, gomock.Any()).Return(nil)
+
+		ctx, rec := deleteRequest("IloveVirginCo2Nut123$")
+		s := &Server{Repository: mockRepo, TokenRevocations: mockRevocations, Config: MockAccountDeletionConfig()}
+
+		err := s.DeleteAccount(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var resp generated.AccountDeletionResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.WithinDuration(t, time.Now().Add(30*24*time.Hour), resp.PurgeAt, time.Minute)
+	})
+
+	t.Run("when error the password is wrong", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), mockUser.GUID).Return(mockUser, nil)
+
+		ctx, rec := deleteRequest("WrongPassword123$")
+		s := &Server{Repository: mockRepo, Config: MockAccountDeletionConfig()}
+
+		err := s.DeleteAccount(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
+
+func TestLoginUser_DeletedAccount(t *testing.T) {
+	mockUser := MockUser()
+	deletedAt := time.Now().UTC().Add(-24 * time.Hour)
+	mockOutput := MockLoginUserOutput(mockUser)
+	mockOutput.Password, _ = tools.HashPassword(mockUser.Password)
+	mockOutput.DeletedAt = &deletedAt
+
+	restoreRequest := func() testRequestEndpointParam {
+		param := loginRequest()
+		param.body = []byte(`{"password": "IloveVirginCo2Nut123$", "phone_number": "+62345678901", "restore_account": true}`)
+		return param
+	}
+
+	t.Run("when success offer to restore the account", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber).Return(repository.LoginUserOutput{}, sql.ErrNoRows)
+		mockRepo.EXPECT().GetDeletedUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber, gomock.Any()).Return(mockOutput, nil)
+
+		ctx, rec := TestRequestEndpoint(loginRequest())
+		s := &Server{Repository: mockRepo, Config: MockAccountDeletionConfig()}
+
+		err := s.LoginUser(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+
+		var resp generated.AccountDeletionResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.WithinDuration(t, deletedAt.Add(30*24*time.Hour), resp.PurgeAt, time.Second)
+	})
+
+	t.Run("when success restore the account and log in", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber).Return(repository.LoginUserOutput{}, sql.ErrNoRows)
+		mockRepo.EXPECT().GetDeletedUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber, gomock.Any()).Return(mockOutput, nil)
+		mockRepo.EXPECT().RestoreUser(gomock.Any(), mockUser.ID).Return(true, nil)
+		mockRepo.EXPECT().GetUserAccess(gomock.Any(), mockUser.ID).Return(repository.UserAccess{}, nil)
+		mockRepo.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Return(nil)
+
+		ctx, rec := TestRequestEndpoint(restoreRequest())
+		s := &Server{Repository: mockRepo, Config: MockAccountDeletionConfig()}
+
+		err := s.LoginUser(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("when success a two-factor user is restored only once the challenge passes", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		totpOutput := mockOutput
+		totpOutput.TOTPEnabled = true
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber).Return(repository.LoginUserOutput{}, sql.ErrNoRows)
+		mockRepo.EXPECT().GetDeletedUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber, gomock.Any()).Return(totpOutput, nil)
+		mockRepo.EXPECT().CreateLoginChallenge(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ interface{}, challenge *repository.LoginChallenge) error {
+				assert.True(t, challenge.RestoreAccount)
+				return nil
+			},
+		)
+
+		ctx, rec := TestRequestEndpoint(restoreRequest())
+		cfg := MockAccountDeletionConfig()
+		cfg.LoginChallengeLifetimeInSeconds = 300
+		s := &Server{Repository: mockRepo, Config: cfg}
+
+		err := s.LoginUser(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+	})
+
+	t.Run("when error a disabled account is not restored", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		disabledAt := time.Now().UTC()
+		disabledOutput := mockOutput
+		disabledOutput.DisabledAt = &disabledAt
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber).Return(repository.LoginUserOutput{}, sql.ErrNoRows)
+		mockRepo.EXPECT().GetDeletedUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber, gomock.Any()).Return(disabledOutput, nil)
+
+		ctx, rec := TestRequestEndpoint(restoreRequest())
+		s := &Server{Repository: mockRepo, Config: MockAccountDeletionConfig()}
+
+		err := s.LoginUser(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("when error the phone number was registered again", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber).Return(repository.LoginUserOutput{}, sql.ErrNoRows)
+		mockRepo.EXPECT().GetDeletedUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber, gomock.Any()).Return(mockOutput, nil)
+		mockRepo.EXPECT().RestoreUser(gomock.Any(), mockUser.ID).Return(false, &tools.Err{Code: http.StatusConflict, Message: repository.PasswordAlreadyExistsErrMessage})
+
+		ctx, rec := TestRequestEndpoint(restoreRequest())
+		s := &Server{Repository: mockRepo, Config: MockAccountDeletionConfig()}
+
+		err := s.LoginUser(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Contains(t, rec.Body.String(), "cannot be restored")
+	})
+
+	t.Run("when error the grace period is over", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber).Return(repository.LoginUserOutput{}, sql.ErrNoRows)
+		mockRepo.EXPECT().GetDeletedUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber, gomock.Any()).Return(repository.LoginUserOutput{}, sql.ErrNoRows)
+
+		ctx, rec := TestRequestEndpoint(restoreRequest())
+		s := &Server{Repository: mockRepo, Config: MockAccountDeletionConfig()}
+
+		err := s.LoginUser(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
@@ -0,0 +1,357 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/tools"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// Page sizes of the admin user listing.
+const (
+	defaultUserListLimit = 20
+	maxUserListLimit     = 100
+)
+
+var errInvalidUserCursor = errors.New("invalid cursor")
+
+// ListAdminUsers lists accounts newest first, one page at a time. Pages are
+// keyed on the last user shown rather than an offset, so users signing up in
+// the meantime neither repeat nor skip entries.
+func (s *Server) ListAdminUsers(ctx echo.Context, params generated.ListAdminUsersParams) error {
+	input := repository.ListUsersInput{
+		CreatedFrom:   params.CreatedFrom,
+		CreatedTo:     params.CreatedTo,
+		PhoneVerified: params.PhoneVerified,
+		Disabled:      params.Disabled,
+		Limit:         defaultUserListLimit,
+	}
+	if params.Limit != nil {
+		if *params.Limit < 1 || *params.Limit > maxUserListLimit {
+			return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: fmt.Sprintf("limit must be between 1 and %d", maxUserListLimit)})
+		}
+		input.Limit = *params.Limit
+	}
+	if params.PhonePrefix != nil {
+		input.PhonePrefix = *params.PhonePrefix
+	}
+	if params.Q != nil {
+		input.NameContains = strings.TrimSpace(*params.Q)
+	}
+	if params.Cursor != nil {
+		cursor, err := decodeUserCursor(*params.Cursor)
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: err.Error()})
+		}
+		input.After = &cursor
+	}
+
+	// One extra row tells whether there is a next page.
+	input.Limit++
+	users, err := s.Repository.ListUsers(ctx.Request().Context(), input)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	resp := generated.AdminUserListResponse{Users: []generated.AdminUserSummary{}}
+	if len(users) == input.Limit {
+		users = users[:len(users)-1]
+		last := users[len(users)-1]
+		nextCursor := encodeUserCursor(repository.UserCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+		resp.NextCursor = &nextCursor
+	}
+	for _, user := range users {
+		resp.Users = append(resp.Users, generated.AdminUserSummary{
+			Guid:            user.GUID,
+			FullName:        user.FullName,
+			PhoneNumber:     user.PhoneNumber,
+			PhoneVerifiedAt: user.PhoneVerifiedAt,
+			DisabledAt:      user.DisabledAt,
+			CreatedAt:       user.CreatedAt,
+		})
+	}
+	return ctx.JSON(http.StatusOK, resp)
+}
+
+// encodeUserCursor packs the position into an opaque url-safe string.
+func encodeUserCursor(cursor repository.UserCursor) string {
+	raw := cursor.CreatedAt.Format(time.RFC3339Nano) + "|" + strconv.Itoa(cursor.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeUserCursor(s string) (cursor repository.UserCursor, err error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor, errInvalidUserCursor
+	}
+	createdAt, id, found := strings.Cut(string(raw), "|")
+	if !found {
+		return cursor, errInvalidUserCursor
+	}
+	cursor.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return cursor, errInvalidUserCursor
+	}
+	cursor.ID, err = strconv.Atoi(id)
+	if err != nil {
+		return cursor, errInvalidUserCursor
+	}
+	return cursor, nil
+}
+
+// GetAdminUser looks up any account for support staff.
+func (s *Server) GetAdminUser(ctx echo.Context, guid uuid.UUID) error {
+	rCtx := ctx.Request().Context()
+
+	user, err := s.Repository.GetUserByGUID(rCtx, guid)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ctx.JSON(http.StatusNotFound, generated.ErrorResponse{Message: "user is not found"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	resp, err := s.adminUserResponse(rCtx, user)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	s.recordAudit(ctx, repository.AuditActionUserViewed, user.ID, nil)
+	return ctx.JSON(http.StatusOK, resp)
+}
+
+// UpdateAdminUser corrects the name or phone number of any account. Unlike
+// UpdateUser the new phone number takes effect straight away, unverified.
+func (s *Server) UpdateAdminUser(ctx echo.Context, guid uuid.UUID) error {
+	rCtx := ctx.Request().Context()
+	var errData *tools.Err
+
+	var input generated.UpdateAdminUserJSONRequestBody
+	err := ctx.Bind(&input)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	err = tools.ValidateRequestPayload(input)
+	if err != nil && errors.As(err, &errData) {
+		return ctx.JSON(errData.Code, generated.ErrorWithExtraResponse{Message: errData.Message, Extra: &errData.Extra})
+	}
+
+	user, err := s.Repository.GetUserByGUID(rCtx, guid)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ctx.JSON(http.StatusNotFound, generated.ErrorResponse{Message: "user is not found"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	// Only the names of the changed fields are audited, not their values.
+	var changed []string
+	if input.FullName != user.FullName {
+		changed = append(changed, "full_name")
+		user.FullName = input.FullName
+	}
+	if input.PhoneNumber != user.PhoneNumber {
+		changed = append(changed, "phone_number")
+		user.PhoneNumber = input.PhoneNumber
+	}
+
+	err = s.Repository.UpdateUser(rCtx, user)
+	if err != nil {
+		if errors.As(err, &errData) {
+			return ctx.JSON(errData.Code, generated.ErrorResponse{Message: errData.Message})
+		}
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	resp, err := s.adminUserResponse(rCtx, user)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	s.recordAudit(ctx, repository.AuditActionUserUpdated, user.ID, map[string]string{"fields": strings.Join(changed, ",")})
+	return ctx.JSON(http.StatusOK, resp)
+}
+
+// DeleteAdminUser soft-deletes another account and signs it out everywhere.
+func (s *Server) DeleteAdminUser(ctx echo.Context, guid uuid.UUID) error {
+	rCtx := ctx.Request().Context()
+
+	if isCaller(ctx, guid) {
+		return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: "you cannot delete your own account here"})
+	}
+
+	user, err := s.Repository.GetUserByGUID(rCtx, guid)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ctx.JSON(http.StatusNotFound, generated.ErrorResponse{Message: "user is not found"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	// Machine clients have no GUID and record uuid.Nil, which like any other
+	// staff deletion the owner cannot restore.
+	deletedBy, _ := uuid.Parse(callerGUID(ctx))
+	deleted, err := s.Repository.DeleteUser(rCtx, user.ID, deletedBy)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+	if !deleted {
+		return ctx.JSON(http.StatusNotFound, generated.ErrorResponse{Message: "user is not found"})
+	}
+
+	err = s.revokeAllUserTokens(rCtx, user.GUID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	s.recordAudit(ctx, repository.AuditActionUserDeleted, user.ID, nil)
+	return ctx.JSON(http.StatusOK, generated.DefaultUpdateResponse{Message: "user deleted successfully"})
+}
+
+// DisableAdminUser keeps another account from logging in and signs it out
+// everywhere. Disabling a disabled account changes nothing.
+func (s *Server) DisableAdminUser(ctx echo.Context, guid uuid.UUID) error {
+	if isCaller(ctx, guid) {
+		return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: "you cannot disable your own account"})
+	}
+	return s.setUserDisabled(ctx, guid, true)
+}
+
+// EnableAdminUser lets a disabled account log in again.
+func (s *Server) EnableAdminUser(ctx echo.Context, guid uuid.UUID) error {
+	return s.setUserDisabled(ctx, guid, false)
+}
+
+// UnlockAdminUser forgets the account's failed logins, lifting a lockout
+// before it runs out.
+func (s *Server) UnlockAdminUser(ctx echo.Context, guid uuid.UUID) error {
+	rCtx := ctx.Request().Context()
+
+	user, err := s.Repository.GetUserByGUID(rCtx, guid)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ctx.JSON(http.StatusNotFound, generated.ErrorResponse{Message: "user is not found"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	err = s.Repository.ClearLoginThrottle(rCtx, userLoginSubject(user.ID))
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+	s.recordAudit(ctx, repository.AuditActionUserUnlocked, user.ID, nil)
+
+	resp, err := s.adminUserResponse(rCtx, user)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, resp)
+}
+
+func (s *Server) setUserDisabled(ctx echo.Context, guid uuid.UUID, disabled bool) error {
+	rCtx := ctx.Request().Context()
+
+	user, err := s.Repository.GetUserByGUID(rCtx, guid)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ctx.JSON(http.StatusNotFound, generated.ErrorResponse{Message: "user is not found"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	changed, err := s.Repository.SetUserDisabled(rCtx, user.ID, disabled)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	if changed {
+		action := repository.AuditActionUserEnabled
+		user.DisabledAt = nil
+		if disabled {
+			action = repository.AuditActionUserDisabled
+			disabledAt := time.Now().UTC()
+			user.DisabledAt = &disabledAt
+
+			err = s.revokeAllUserTokens(rCtx, user.GUID)
+			if err != nil {
+				return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+			}
+		}
+		s.recordAudit(ctx, action, user.ID, nil)
+	}
+
+	resp, err := s.adminUserResponse(rCtx, user)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, resp)
+}
+
+func (s *Server) adminUserResponse(ctx context.Context, user *repository.User) (resp generated.AdminUserResponse, err error) {
+	access, err := s.Repository.GetUserAccess(ctx, user.ID)
+	if err != nil {
+		return resp, err
+	}
+
+	roles := access.Roles
+	if roles == nil {
+		roles = []string{}
+	}
+	return generated.AdminUserResponse{
+		Guid:               user.GUID,
+		FullName:           user.FullName,
+		PhoneNumber:        user.PhoneNumber,
+		PhoneVerifiedAt:    user.PhoneVerifiedAt,
+		PendingPhoneNumber: user.PendingPhoneNumber,
+		Roles:              roles,
+		DisabledAt:         user.DisabledAt,
+		CreatedAt:          user.CreatedAt,
+	}, nil
+}
+
+// recordAudit appends what the caller did to the target user to the audit
+// trail. Machine clients have no GUID, so their client_id goes in the details.
+// The action has already happened, so a failure is only logged.
+func (s *Server) recordAudit(ctx echo.Context, action string, targetUserID int, details map[string]string) {
+	entry := &repository.AuditLog{
+		Action:       action,
+		TargetUserID: &targetUserID,
+		IPAddress:    ctx.RealIP(),
+		Details:      details,
+	}
+	if guid, err := uuid.Parse(callerGUID(ctx)); err == nil {
+		entry.ActorGUID = &guid
+	} else if clientID, _ := ctx.Get("ClientID").(string); clientID != "" {
+		entry.Details = map[string]string{"client_id": clientID}
+		for key, value := range details {
+			entry.Details[key] = value
+		}
+	}
+
+	err := s.Repository.CreateAuditLog(ctx.Request().Context(), entry)
+	if err != nil {
+		ctx.Logger().Errorf("recording audit log %s: %v", action, err)
+	}
+}
+
+func callerGUID(ctx echo.Context) string {
+	guid, _ := ctx.Get("UserGUID").(string)
+	return guid
+}
+
+// isCaller reports whether guid is the signed in user's own account.
+func isCaller(ctx echo.Context, guid uuid.UUID) bool {
+	return callerGUID(ctx) == guid.String()
+}
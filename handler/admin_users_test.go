@@ -0,0 +1,423 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/tools"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// adminRequest builds a request made by a staff member other than the target,
+// with a forged X-Forwarded-For the audit trail must not believe.
+func adminRequest(httpMethod string, url string, body []byte) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	e.IPExtractor, _ = NewIPExtractor("")
+	ctx, rec := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: httpMethod, url: url, body: body})
+	ctx.Request().Header.Set(echo.HeaderXForwardedFor, "203.0.113.9")
+	ctx.Set("UserGUID", uuid.New().String())
+	return ctx, rec
+}
+
+func expectAudit(t *testing.T, mockRepo *repository.MockRepositoryInterface, action string, targetUserID int) {
+	mockRepo.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ interface{}, entry *repository.AuditLog) error {
+			assert.Equal(t, action, entry.Action)
+			assert.Equal(t, targetUserID, *entry.TargetUserID)
+			assert.NotNil(t, entry.ActorGUID)
+			assert.Equal(t, "192.0.2.1", entry.IPAddress)
+			return nil
+		},
+	)
+}
+
+func TestListAdminUsers(t *testing.T) {
+	t.Run("when success return a page with the cursor to the next one", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		createdAt := time.Date(2024, 5, 1, 8, 30, 0, 123456000, time.UTC)
+		var users []*repository.User
+		for id := 3; id > 0; id-- {
+			user := MockUser()
+			user.ID = id
+			user.CreatedAt = createdAt
+			users = append(users, user)
+		}
+
+		verified := true
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().ListUsers(gomock.Any(), repository.ListUsersInput{
+			PhoneVerified: &verified,
+			PhonePrefix:   "+6281",
+			NameContains:  "mania",
+			Limit:         3,
+		}).Return(users, nil)
+
+		ctx, rec := adminRequest(http.MethodGet, "/admin/users", nil)
+		limit, prefix, q := 2, "+6281", " mania "
+		s := &Server{Repository: mockRepo}
+
+		err := s.ListAdminUsers(ctx, generated.ListAdminUsersParams{Limit: &limit, PhoneVerified: &verified, PhonePrefix: &prefix, Q: &q})
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var resp generated.AdminUserListResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Len(t, resp.Users, 2)
+		assert.NotNil(t, resp.NextCursor)
+
+		cursor, err := decodeUserCursor(*resp.NextCursor)
+		assert.NoError(t, err)
+		assert.Equal(t, repository.UserCursor{CreatedAt: createdAt, ID: 2}, cursor)
+	})
+
+	t.Run("when success continue after the cursor on the last page", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		after := repository.UserCursor{CreatedAt: time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC), ID: 2}
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().ListUsers(gomock.Any(), repository.ListUsersInput{After: &after, Limit: defaultUserListLimit + 1}).Return([]*repository.User{MockUser()}, nil)
+
+		ctx, rec := adminRequest(http.MethodGet, "/admin/users", nil)
+		cursor := encodeUserCursor(after)
+		s := &Server{Repository: mockRepo}
+
+		err := s.ListAdminUsers(ctx, generated.ListAdminUsersParams{Cursor: &cursor})
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var resp generated.AdminUserListResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Len(t, resp.Users, 1)
+		assert.Nil(t, resp.NextCursor)
+	})
+
+	t.Run("when error the cursor is malformed", func(t *testing.T) {
+		ctx, rec := adminRequest(http.MethodGet, "/admin/users", nil)
+		cursor := "not-a-cursor"
+		s := &Server{}
+
+		err := s.ListAdminUsers(ctx, generated.ListAdminUsersParams{Cursor: &cursor})
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("when error the limit is too large", func(t *testing.T) {
+		ctx, rec := adminRequest(http.MethodGet, "/admin/users", nil)
+		limit := maxUserListLimit + 1
+		s := &Server{}
+
+		err := s.ListAdminUsers(ctx, generated.ListAdminUsersParams{Limit: &limit})
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestGetAdminUser(t *testing.T) {
+	t.Run("when success look up the user with their roles", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), mockUser.GUID).Return(mockUser, nil)
+		mockRepo.EXPECT().GetUserAccess(gomock.Any(), mockUser.ID).Return(repository.UserAccess{Roles: []string{"support"}}, nil)
+		expectAudit(t, mockRepo, repository.AuditActionUserViewed, mockUser.ID)
+
+		ctx, rec := adminRequest(http.MethodGet, "/admin/users/"+mockUser.GUID.String(), nil)
+		s := &Server{Repository: mockRepo}
+
+		err := s.GetAdminUser(ctx, mockUser.GUID)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var resp generated.AdminUserResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, mockUser.PhoneNumber, resp.PhoneNumber)
+		assert.Equal(t, []string{"support"}, resp.Roles)
+		assert.Nil(t, resp.DisabledAt)
+	})
+
+	t.Run("when error the user is not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		guid := uuid.New()
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), guid).Return(nil, sql.ErrNoRows)
+
+		ctx, rec := adminRequest(http.MethodGet, "/admin/users/"+guid.String(), nil)
+		s := &Server{Repository: mockRepo}
+
+		err := s.GetAdminUser(ctx, guid)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestUpdateAdminUser(t *testing.T) {
+	t.Run("when success set the new phone number straight away", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), mockUser.GUID).Return(mockUser, nil)
+		mockRepo.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ interface{}, user *repository.User) error {
+				assert.Equal(t, "+62345678999", user.PhoneNumber)
+				assert.Nil(t, user.PendingPhoneNumber)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().GetUserAccess(gomock.Any(), mockUser.ID).Return(repository.UserAccess{}, nil)
+		mockRepo.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ interface{}, entry *repository.AuditLog) error {
+				assert.Equal(t, repository.AuditActionUserUpdated, entry.Action)
+				assert.Equal(t, map[string]string{"fields": "phone_number"}, entry.Details)
+				return nil
+			},
+		)
+
+		ctx, rec := adminRequest(http.MethodPut, "/admin/users/"+mockUser.GUID.String(),
+			[]byte(`{"full_name": "SawitPro Mania", "phone_number": "+62345678999"}`))
+		s := &Server{Repository: mockRepo}
+
+		err := s.UpdateAdminUser(ctx, mockUser.GUID)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var resp generated.AdminUserResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, "+62345678999", resp.PhoneNumber)
+		assert.Equal(t, []string{}, resp.Roles)
+	})
+
+	t.Run("when error the phone number belongs to another user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), mockUser.GUID).Return(mockUser, nil)
+		mockRepo.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).Return(&tools.Err{Code: http.StatusConflict, Message: "phone number is already used"})
+
+		ctx, rec := adminRequest(http.MethodPut, "/admin/users/"+mockUser.GUID.String(),
+			[]byte(`{"full_name": "SawitPro Mania", "phone_number": "+62345678999"}`))
+		s := &Server{Repository: mockRepo}
+
+		err := s.UpdateAdminUser(ctx, mockUser.GUID)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+}
+
+func TestDeleteAdminUser(t *testing.T) {
+	t.Run("when success delete the user and sign them out", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), mockUser.GUID).Return(mockUser, nil)
+		mockRepo.EXPECT().DeleteUser(gomock.Any(), mockUser.ID, gomock.Any()).Return(true, nil)
+		mockRepo.EXPECT().RevokeUserRefreshTokens(gomock.Any(), mockUser.GUID).Return(nil)
+		expectAudit(t, mockRepo, repository.AuditActionUserDeleted, mockUser.ID)
+		mockRevocations := repository.NewMockTokenRevocationRepositoryInterface(ctrl)
+		mockRevocations.EXPECT().RevokeUserAccessTokens(gomock.Any(), mockUser.GUID, gomock.Any()).Return(nil)
+
+		ctx, rec := adminRequest(http.MethodDelete, "/admin/users/"+mockUser.GUID.String(), nil)
+		s := &Server{Repository: mockRepo, TokenRevocations: mockRevocations}
+
+		err := s.DeleteAdminUser(ctx, mockUser.GUID)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("when success a machine client deletes the user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), mockUser.GUID).Return(mockUser, nil)
+		mockRepo.EXPECT().DeleteUser(gomock.Any(), mockUser.ID, uuid.Nil).Return(true, nil)
+		mockRepo.EXPECT().RevokeUserRefreshTokens(gomock.Any(), mockUser.GUID).Return(nil)
+		mockRepo.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ interface{}, entry *repository.AuditLog) error {
+				assert.Equal(t, repository.AuditActionUserDeleted, entry.Action)
+				assert.Nil(t, entry.ActorGUID)
+				assert.Equal(t, map[string]string{"client_id": "billing-job"}, entry.Details)
+				return nil
+			},
+		)
+		mockRevocations := repository.NewMockTokenRevocationRepositoryInterface(ctrl)
+		mockRevocations.EXPECT().RevokeUserAccessTokens(gomock.Any(), mockUser.GUID, gomock.Any()).Return(nil)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{e: echo.New(), httpMethod: http.MethodDelete, url: "/admin/users/" + mockUser.GUID.String()})
+		ctx.Set("PrincipalType", PrincipalTypeClient)
+		ctx.Set("ClientID", "billing-job")
+		s := &Server{Repository: mockRepo, TokenRevocations: mockRevocations}
+
+		err := s.DeleteAdminUser(ctx, mockUser.GUID)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("when error the caller deletes their own account", func(t *testing.T) {
+		guid := uuid.New()
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{e: echo.New(), httpMethod: http.MethodDelete, url: "/admin/users/" + guid.String()})
+		ctx.Set("UserGUID", guid.String())
+		s := &Server{}
+
+		err := s.DeleteAdminUser(ctx, guid)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestDisableAdminUser(t *testing.T) {
+	t.Run("when success disable the user and sign them out", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), mockUser.GUID).Return(mockUser, nil)
+		mockRepo.EXPECT().SetUserDisabled(gomock.Any(), mockUser.ID, true).Return(true, nil)
+		mockRepo.EXPECT().RevokeUserRefreshTokens(gomock.Any(), mockUser.GUID).Return(nil)
+		expectAudit(t, mockRepo, repository.AuditActionUserDisabled, mockUser.ID)
+		mockRepo.EXPECT().GetUserAccess(gomock.Any(), mockUser.ID).Return(repository.UserAccess{}, nil)
+		mockRevocations := repository.NewMockTokenRevocationRepositoryInterface(ctrl)
+		mockRevocations.EXPECT().RevokeUserAccessTokens(gomock.Any(), mockUser.GUID, gomock.Any()).Return(nil)
+
+		ctx, rec := adminRequest(http.MethodPost, "/admin/users/"+mockUser.GUID.String()+"/disable", nil)
+		s := &Server{Repository: mockRepo, TokenRevocations: mockRevocations}
+
+		err := s.DisableAdminUser(ctx, mockUser.GUID)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var resp generated.AdminUserResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.NotNil(t, resp.DisabledAt)
+	})
+
+	t.Run("when success leave a disabled user alone", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		disabledAt := time.Now().UTC().Add(-time.Hour)
+		mockUser.DisabledAt = &disabledAt
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), mockUser.GUID).Return(mockUser, nil)
+		mockRepo.EXPECT().SetUserDisabled(gomock.Any(), mockUser.ID, true).Return(false, nil)
+		mockRepo.EXPECT().GetUserAccess(gomock.Any(), mockUser.ID).Return(repository.UserAccess{}, nil)
+
+		ctx, rec := adminRequest(http.MethodPost, "/admin/users/"+mockUser.GUID.String()+"/disable", nil)
+		s := &Server{Repository: mockRepo}
+
+		err := s.DisableAdminUser(ctx, mockUser.GUID)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
+
+func TestEnableAdminUser(t *testing.T) {
+	t.Run("when success let the user log in again", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		disabledAt := time.Now().UTC().Add(-time.Hour)
+		mockUser.DisabledAt = &disabledAt
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), mockUser.GUID).Return(mockUser, nil)
+		mockRepo.EXPECT().SetUserDisabled(gomock.Any(), mockUser.ID, false).Return(true, nil)
+		expectAudit(t, mockRepo, repository.AuditActionUserEnabled, mockUser.ID)
+		mockRepo.EXPECT().GetUserAccess(gomock.Any(), mockUser.ID).Return(repository.UserAccess{}, nil)
+
+		ctx, rec := adminRequest(http.MethodPost, "/admin/users/"+mockUser.GUID.String()+"/enable", nil)
+		s := &Server{Repository: mockRepo}
+
+		err := s.EnableAdminUser(ctx, mockUser.GUID)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var resp generated.AdminUserResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Nil(t, resp.DisabledAt)
+	})
+}
+
+func TestUnlockAdminUser(t *testing.T) {
+	t.Run("when success forget the failed logins", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), mockUser.GUID).Return(mockUser, nil)
+		mockRepo.EXPECT().ClearLoginThrottle(gomock.Any(), userLoginSubject(mockUser.ID)).Return(nil)
+		expectAudit(t, mockRepo, repository.AuditActionUserUnlocked, mockUser.ID)
+		mockRepo.EXPECT().GetUserAccess(gomock.Any(), mockUser.ID).Return(repository.UserAccess{}, nil)
+
+		ctx, rec := adminRequest(http.MethodPost, "/admin/users/"+mockUser.GUID.String()+"/unlock", nil)
+		s := &Server{Repository: mockRepo}
+
+		err := s.UnlockAdminUser(ctx, mockUser.GUID)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("when error the user is not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		guid := uuid.New()
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), guid).Return(nil, sql.ErrNoRows)
+
+		ctx, rec := adminRequest(http.MethodPost, "/admin/users/"+guid.String()+"/unlock", nil)
+		s := &Server{Repository: mockRepo}
+
+		err := s.UnlockAdminUser(ctx, guid)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestLoginUser_Disabled(t *testing.T) {
+	t.Run("when error the account was disabled by staff", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		output := MockLoginUserOutput(mockUser)
+		output.Password, _ = tools.HashPassword(mockUser.Password)
+		disabledAt := time.Now().UTC()
+		output.DisabledAt = &disabledAt
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber).Return(output, nil)
+
+		ctx, rec := TestRequestEndpoint(loginRequest())
+		s := &Server{Repository: mockRepo}
+
+		err := s.LoginUser(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
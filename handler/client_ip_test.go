@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewIPExtractor(t *testing.T) {
+	request := func(remoteAddr string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/login", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set(echo.HeaderXForwardedFor, "203.0.113.9, 198.51.100.7")
+		req.Header.Set(echo.HeaderXRealIP, "203.0.113.9")
+		return req
+	}
+
+	t.Run("when no proxies are trusted ignore the forwarding headers", func(t *testing.T) {
+		extract, err := NewIPExtractor("")
+		assert.NoError(t, err)
+		assert.Equal(t, "192.0.2.1", extract(request("192.0.2.1:1234")))
+	})
+
+	t.Run("when the peer is a trusted proxy follow X-Forwarded-For back through trusted hops", func(t *testing.T) {
+		extract, err := NewIPExtractor("192.0.2.1, 198.51.100.0/24")
+		assert.NoError(t, err)
+		assert.Equal(t, "203.0.113.9", extract(request("192.0.2.1:1234")))
+	})
+
+	t.Run("when the peer is not a trusted proxy use the peer", func(t *testing.T) {
+		extract, err := NewIPExtractor("198.51.100.0/24")
+		assert.NoError(t, err)
+		assert.Equal(t, "192.0.2.1", extract(request("192.0.2.1:1234")))
+	})
+
+	t.Run("when error a trusted proxy is not an ip", func(t *testing.T) {
+		_, err := NewIPExtractor("proxy.internal")
+		assert.Error(t, err)
+	})
+}
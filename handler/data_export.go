@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/tools"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+var errDataExportNotConfigured = errors.New("data export is not configured")
+
+// dataExportContentTypes is what each export format is served as.
+var dataExportContentTypes = map[string]string{
+	repository.DataExportFormatJSON: "application/json",
+	repository.DataExportFormatZIP:  "application/zip",
+}
+
+// RequestDataExport queues a copy of everything stored about the caller. The
+// archive is built in the background; GetDataExport says when it is ready.
+func (s *Server) RequestDataExport(ctx echo.Context) error {
+	rCtx := ctx.Request().Context()
+	guid := uuid.MustParse(ctx.Get("UserGUID").(string))
+	var errData *tools.Err
+
+	_, err := s.dataExportSigningKey()
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	var input generated.RequestDataExportJSONRequestBody
+	err = ctx.Bind(&input)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	err = tools.ValidateRequestPayload(input)
+	if err != nil && errors.As(err, &errData) {
+		return ctx.JSON(errData.Code, generated.ErrorWithExtraResponse{Message: errData.Message, Extra: &errData.Extra})
+	}
+
+	export := &repository.DataExport{UserGUID: guid, Format: string(input.Format)}
+	err = s.Repository.CreateDataExport(rCtx, export)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ctx.JSON(http.StatusNotFound, generated.ErrorResponse{Message: "user is not found"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	resp, err := s.dataExportResponse(ctx, export)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+	return ctx.JSON(http.StatusAccepted, resp)
+}
+
+// GetDataExport reports on one of the caller's exports, with a fresh download
+// link once it is ready.
+func (s *Server) GetDataExport(ctx echo.Context, id uuid.UUID) error {
+	guid := uuid.MustParse(ctx.Get("UserGUID").(string))
+
+	export, err := s.Repository.GetUserDataExport(ctx.Request().Context(), guid, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ctx.JSON(http.StatusNotFound, generated.ErrorResponse{Message: "data export is not found"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	resp, err := s.dataExportResponse(ctx, export)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, resp)
+}
+
+// DownloadDataExport serves the archive of a ready export to whoever holds a
+// valid signed link, so it can be opened outside the app.
+func (s *Server) DownloadDataExport(ctx echo.Context, id uuid.UUID, params generated.DownloadDataExportParams) error {
+	key, err := s.dataExportSigningKey()
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	if !tools.VerifyLink(key, id.String(), time.Unix(params.Expires, 0), params.Signature, time.Now()) {
+		return ctx.JSON(http.StatusForbidden, generated.ErrorResponse{Message: "download link is invalid or has expired"})
+	}
+
+	export, archive, err := s.Repository.GetDataExportArchive(ctx.Request().Context(), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ctx.JSON(http.StatusNotFound, generated.ErrorResponse{Message: "data export is not found or no longer kept"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	filename := fmt.Sprintf("personal-data-%s.%s", export.CreatedAt.Format("2006-01-02"), export.Format)
+	ctx.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	ctx.Response().Header().Set("Cache-Control", "no-store")
+	return ctx.Blob(http.StatusOK, dataExportContentTypes[export.Format], archive)
+}
+
+// dataExportSigningKey is the key download links are signed with.
+func (s *Server) dataExportSigningKey() ([]byte, error) {
+	key, err := tools.ParseEncryptionKey(s.Config.DataExportSigningKey)
+	if err != nil {
+		return nil, errDataExportNotConfigured
+	}
+	return key, nil
+}
+
+func (s *Server) dataExportResponse(ctx echo.Context, export *repository.DataExport) (generated.DataExportResponse, error) {
+	resp := generated.DataExportResponse{
+		Id:          export.GUID,
+		Format:      generated.DataExportResponseFormat(export.Format),
+		Status:      generated.DataExportResponseStatus(export.Status),
+		CreatedAt:   export.CreatedAt,
+		CompletedAt: export.CompletedAt,
+	}
+
+	now := time.Now().UTC()
+	if export.Status != repository.DataExportStatusReady || export.ExpiresAt == nil {
+		return resp, nil
+	}
+	if !export.ExpiresAt.After(now) {
+		resp.Status = generated.DataExportResponseStatus(repository.DataExportStatusExpired)
+		return resp, nil
+	}
+	resp.ExpiresAt = export.ExpiresAt
+
+	key, err := s.dataExportSigningKey()
+	if err != nil {
+		return resp, err
+	}
+
+	// The link never outlives the archive it points at.
+	linkExpiresAt := *export.ExpiresAt
+	if s.Config.DataExportLinkLifetimeInMinutes > 0 {
+		lifetimeEnd := now.Add(time.Duration(s.Config.DataExportLinkLifetimeInMinutes) * time.Minute)
+		if lifetimeEnd.Before(linkExpiresAt) {
+			linkExpiresAt = lifetimeEnd
+		}
+	}
+	linkExpiresAt = linkExpiresAt.Truncate(time.Second)
+
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(linkExpiresAt.Unix(), 10))
+	query.Set("signature", tools.SignLink(key, export.GUID.String(), linkExpiresAt))
+	downloadURL := fmt.Sprintf("%s/data-exports/%s/download?%s", tokenIssuer(s.Config), export.GUID, query.Encode())
+
+	resp.DownloadUrl = &downloadURL
+	resp.DownloadUrlExpiresAt = &linkExpiresAt
+	return resp, nil
+}
@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/config"
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/tools"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+const mockDataExportSigningKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+
+func MockDataExportConfig() config.Config {
+	return config.Config{
+		JWTIssuer:                       "https://auth.example.com",
+		DataExportSigningKey:            mockDataExportSigningKey,
+		DataExportLinkLifetimeInMinutes: 15,
+	}
+}
+
+func TestRequestDataExport(t *testing.T) {
+	mockUser := MockUser()
+
+	exportRequest := func(body string) echo.Context {
+		ctx, _ := TestRequestEndpoint(testRequestEndpointParam{
+			e:          echo.New(),
+			httpMethod: http.MethodPost,
+			url:        "/users/data-export",
+			body:       []byte(body),
+		})
+		ctx.Set("UserGUID", mockUser.GUID.String())
+		return ctx
+	}
+
+	t.Run("when success queue the export", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().CreateDataExport(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ any, export *repository.DataExport) error {
+				assert.Equal(t, mockUser.GUID, export.UserGUID)
+				assert.Equal(t, repository.DataExportFormatZIP, export.Format)
+				export.GUID = uuid.New()
+				export.Status = repository.DataExportStatusPending
+				return nil
+			})
+
+		ctx := exportRequest(`{"format": "zip"}`)
+		s := &Server{Repository: mockRepo, Config: MockDataExportConfig()}
+
+		err := s.RequestDataExport(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusAccepted, ctx.Response().Status)
+	})
+
+	t.Run("when error the format is unknown", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := exportRequest(`{"format": "xml"}`)
+		s := &Server{Repository: repository.NewMockRepositoryInterface(ctrl), Config: MockDataExportConfig()}
+
+		err := s.RequestDataExport(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, ctx.Response().Status)
+	})
+
+	t.Run("when error no signing key is configured", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := exportRequest(`{"format": "json"}`)
+		s := &Server{Repository: repository.NewMockRepositoryInterface(ctrl), Config: config.Config{}}
+
+		err := s.RequestDataExport(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, ctx.Response().Status)
+	})
+}
+
+func TestGetDataExport(t *testing.T) {
+	mockUser := MockUser()
+	exportID := uuid.New()
+
+	getRequest := func() (echo.Context, *httptest.ResponseRecorder) {
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          echo.New(),
+			httpMethod: http.MethodGet,
+			url:        "/users/data-export/" + exportID.String(),
+		})
+		ctx.Set("UserGUID", mockUser.GUID.String())
+		return ctx, rec
+	}
+
+	t.Run("when success a ready export comes with a download link", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		expiresAt := time.Now().UTC().Add(72 * time.Hour)
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserDataExport(gomock.Any(), mockUser.GUID, exportID).Return(&repository.DataExport{
+			GUID:      exportID,
+			Format:    repository.DataExportFormatJSON,
+			Status:    repository.DataExportStatusReady,
+			ExpiresAt: &expiresAt,
+		}, nil)
+
+		ctx, rec := getRequest()
+		s := &Server{Repository: mockRepo, Config: MockDataExportConfig()}
+
+		err := s.GetDataExport(ctx, exportID)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, ctx.Response().Status)
+
+		var resp generated.DataExportResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.NotNil(t, resp.DownloadUrl)
+		assert.WithinDuration(t, time.Now().Add(15*time.Minute), *resp.DownloadUrlExpiresAt, 2*time.Second)
+
+		link, err := url.Parse(*resp.DownloadUrl)
+		assert.NoError(t, err)
+		assert.Equal(t, "/data-exports/"+exportID.String()+"/download", link.Path)
+		assert.Equal(t, "auth.example.com", link.Host)
+	})
+
+	t.Run("when success a pending export has no link", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserDataExport(gomock.Any(), mockUser.GUID, exportID).Return(&repository.DataExport{
+			GUID:   exportID,
+			Format: repository.DataExportFormatJSON,
+			Status: repository.DataExportStatusPending,
+		}, nil)
+
+		ctx, rec := getRequest()
+		s := &Server{Repository: mockRepo, Config: MockDataExportConfig()}
+
+		err := s.GetDataExport(ctx, exportID)
+		assert.NoError(t, err)
+		var resp generated.DataExportResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Nil(t, resp.DownloadUrl)
+	})
+
+	t.Run("when error the export belongs to someone else", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserDataExport(gomock.Any(), mockUser.GUID, exportID).Return(nil, sql.ErrNoRows)
+
+		ctx, _ := getRequest()
+		s := &Server{Repository: mockRepo, Config: MockDataExportConfig()}
+
+		err := s.GetDataExport(ctx, exportID)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, ctx.Response().Status)
+	})
+}
+
+func TestDownloadDataExport(t *testing.T) {
+	exportID := uuid.New()
+	key, _ := tools.ParseEncryptionKey(mockDataExportSigningKey)
+	linkExpiresAt := time.Now().Add(10 * time.Minute).Truncate(time.Second)
+	validParams := generated.DownloadDataExportParams{
+		Expires:   linkExpiresAt.Unix(),
+		Signature: tools.SignLink(key, exportID.String(), linkExpiresAt),
+	}
+
+	downloadRequest := func() echo.Context {
+		ctx, _ := TestRequestEndpoint(testRequestEndpointParam{
+			e:          echo.New(),
+			httpMethod: http.MethodGet,
+			url:        "/data-exports/" + exportID.String() + "/download",
+		})
+		return ctx
+	}
+
+	t.Run("when success serve the archive as an attachment", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetDataExportArchive(gomock.Any(), exportID).Return(&repository.DataExport{
+			GUID:      exportID,
+			Format:    repository.DataExportFormatZIP,
+			CreatedAt: time.Date(2024, 5, 31, 12, 0, 0, 0, time.UTC),
+		}, []byte("archive"), nil)
+
+		ctx := downloadRequest()
+		s := &Server{Repository: mockRepo, Config: MockDataExportConfig()}
+
+		err := s.DownloadDataExport(ctx, exportID, validParams)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, ctx.Response().Status)
+		assert.Equal(t, "application/zip", ctx.Response().Header().Get(echo.HeaderContentType))
+		assert.Equal(t, `attachment; filename="personal-data-2024-05-31.zip"`, ctx.Response().Header().Get(echo.HeaderContentDisposition))
+	})
+
+	t.Run("when error the signature is wrong", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		params := validParams
+		params.Expires = linkExpiresAt.Add(time.Hour).Unix()
+		ctx := downloadRequest()
+		s := &Server{Repository: repository.NewMockRepositoryInterface(ctrl), Config: MockDataExportConfig()}
+
+		err := s.DownloadDataExport(ctx, exportID, params)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, ctx.Response().Status)
+	})
+
+	t.Run("when error the archive is no longer kept", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetDataExportArchive(gomock.Any(), exportID).Return(nil, nil, sql.ErrNoRows)
+
+		ctx := downloadRequest()
+		s := &Server{Repository: mockRepo, Config: MockDataExportConfig()}
+
+		err := s.DownloadDataExport(ctx, exportID, validParams)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, ctx.Response().Status)
+	})
+}
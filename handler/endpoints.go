@@ -26,7 +26,7 @@ func (s *Server) RegisterUser(ctx echo.Context) error {
 		return ctx.JSON(errData.Code, generated.ErrorWithExtraResponse{Message: errData.Message, Extra: &errData.Extra})
 	}
 
-	hashedPassword, err := tools.HashPassword(input.Password)
+	hashedPassword, err := s.hashPassword(input.Password)
 	if err != nil {
 		return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: err.Error()})
 	}
@@ -45,6 +45,13 @@ func (s *Server) RegisterUser(ctx echo.Context) error {
 		return ctx.JSON(http.StatusInternalServerError, err.Error())
 	}
 
+	// The account exists at this point, so a failed text is only logged; the
+	// user can ask for another code through /phone/verify/resend.
+	err = s.sendOTP(ctx.Request().Context(), newUser.ID, newUser.PhoneNumber, repository.OTPPurposePhoneVerification, phoneVerificationMessageFormat)
+	if err != nil {
+		ctx.Logger().Errorf("sending phone verification code: %v", err)
+	}
+
 	resp := generated.SuccessRegisterUserResponse{
 		GUID:    newUser.GUID,
 		Message: "User registered successfully",
@@ -66,34 +73,136 @@ func (s *Server) LoginUser(ctx echo.Context) error {
 		return ctx.JSON(errData.Code, generated.ErrorWithExtraResponse{Message: errData.Message, Extra: &errData.Extra})
 	}
 
-	output, err := s.Repository.GetUserLoginByPhoneNumber(ctx.Request().Context(), input.PhoneNumber)
+	rCtx := ctx.Request().Context()
+	ipSubject := ipLoginSubject(ctx.RealIP())
+	if s.Config.LoginMaxFailedAttemptsPerIP > 0 {
+		wait, _, err := s.loginBlock(rCtx, ipSubject, s.Config.LoginMaxFailedAttemptsPerIP)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+		}
+		if wait > 0 {
+			return loginThrottledResponse(ctx, wait, false)
+		}
+	}
+
+	output, err := s.Repository.GetUserLoginByPhoneNumber(rCtx, input.PhoneNumber)
+	if err == sql.ErrNoRows {
+		output, err = s.getRestorableUserLogin(rCtx, input.PhoneNumber)
+	}
 	if err != nil {
 		if err == sql.ErrNoRows {
+			err = s.recordIPLoginFailure(rCtx, ipSubject)
+			if err != nil {
+				return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+			}
 			return ctx.JSON(http.StatusNotFound, generated.ErrorResponse{Message: "user is not found"})
 		}
 		return ctx.JSON(http.StatusInternalServerError, err.Error())
 	}
 
+	if s.Config.LoginMaxFailedAttempts > 0 {
+		wait, locked, err := s.loginBlock(rCtx, userLoginSubject(output.ID), s.Config.LoginMaxFailedAttempts)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+		}
+		if wait > 0 {
+			return loginThrottledResponse(ctx, wait, locked)
+		}
+	}
+
 	isMatched := tools.IsValidPassword(output.Password, input.Password)
 	if !isMatched {
+		err = s.recordUserLoginFailure(rCtx, output.ID)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+		}
+		err = s.recordIPLoginFailure(rCtx, ipSubject)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+		}
 		return ctx.JSON(http.StatusBadRequest, "invalid password")
 	}
 
-	tokenParam := tools.GenerateJWTTokenParams{
-		FullName: output.FullName,
-		GUID:     output.GUID,
+	// A deleted account still in its grace period is only restored when the
+	// user says so; otherwise they are told how long they have left. The
+	// restore itself waits for finishLogin, once every other check passed.
+	if output.DeletedAt != nil && (input.RestoreAccount == nil || !*input.RestoreAccount) {
+		return ctx.JSON(http.StatusConflict, generated.AccountDeletionResponse{
+			Message: "account is deleted, log in with restore_account set to restore it",
+			PurgeAt: s.accountPurgeAt(*output.DeletedAt),
+		})
 	}
 
-	token, expiredAt, err := tools.GenerateJWTToken(tokenParam, s.Config.JWTTokenLifetimeInHours, s.Config.RSAPrivateKey)
+	// The login goes ahead with the old hash if the upgrade fails; the next
+	// login tries again.
+	err = s.rehashOutdatedPassword(rCtx, output.ID, output.Password, input.Password)
 	if err != nil {
-		return ctx.JSON(http.StatusBadRequest, err.Error())
+		ctx.Logger().Errorf("rehashing password: %v", err)
 	}
-	resp := generated.SuccessLoginUserResponse{
-		Token:     token,
-		ExpiredAt: expiredAt.String(),
+
+	if s.Config.LoginRequireVerifiedPhone && output.PhoneVerifiedAt == nil {
+		return ctx.JSON(http.StatusForbidden, generated.ErrorResponse{Message: "phone number is not verified"})
+	}
+
+	if s.passwordExpired(output) {
+		return ctx.JSON(http.StatusForbidden, generated.ErrorResponse{Message: "password has expired, reset it to log in"})
+	}
+
+	return s.finishLogin(ctx, output, input.PhoneNumber)
+}
+
+// finishLogin answers a login whose first factor was accepted: users with
+// two-factor authentication get a challenge, everyone else gets tokens. The
+// account's failed logins are only forgotten, and a deleted account only
+// restored, once the login is complete, so for two-factor users that waits
+// for LoginTwoFactor.
+func (s *Server) finishLogin(ctx echo.Context, output repository.LoginUserOutput, phoneNumber string) error {
+	rCtx := ctx.Request().Context()
+	var errData *tools.Err
+
+	if output.DisabledAt != nil {
+		return ctx.JSON(http.StatusForbidden, generated.ErrorResponse{Message: "account is disabled"})
+	}
+
+	restore := output.DeletedAt != nil
+	if output.TOTPEnabled {
+		challenge, err := s.issueLoginChallenge(rCtx, output.ID, restore)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+		}
+		return ctx.JSON(http.StatusAccepted, challenge)
+	}
+
+	if restore {
+		err := s.restoreAccount(rCtx, output.ID)
+		if err != nil {
+			if errors.As(err, &errData) {
+				return ctx.JSON(errData.Code, generated.ErrorResponse{Message: errData.Message})
+			}
+			return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+		}
+	}
+
+	err := s.unlockUserLogin(rCtx, output.ID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
 	}
-	return ctx.JSON(http.StatusOK, resp)
 
+	owner := tokenOwner{
+		ID:          output.ID,
+		GUID:        output.GUID,
+		FullName:    output.FullName,
+		PhoneNumber: phoneNumber,
+	}
+
+	resp, err := s.issueTokenPair(rCtx, owner, uuid.Nil)
+	if err != nil {
+		if errors.As(err, &errData) {
+			return ctx.JSON(errData.Code, generated.ErrorResponse{Message: errData.Message})
+		}
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return ctx.JSON(http.StatusOK, resp)
 }
 
 func (s *Server) GetUserProfile(ctx echo.Context) error {
@@ -108,10 +217,12 @@ func (s *Server) GetUserProfile(ctx echo.Context) error {
 	}
 
 	resp := generated.SuccessGetUserProfileResponse{
-		Guid:        user.GUID,
-		FullName:    user.FullName,
-		PhoneNumber: user.PhoneNumber,
-		CreatedAt:   &user.CreatedAt,
+		Guid:               user.GUID,
+		FullName:           user.FullName,
+		PhoneNumber:        user.PhoneNumber,
+		PhoneVerifiedAt:    user.PhoneVerifiedAt,
+		PendingPhoneNumber: user.PendingPhoneNumber,
+		CreatedAt:          &user.CreatedAt,
 	}
 	return ctx.JSON(http.StatusOK, resp)
 }
@@ -140,8 +251,15 @@ func (s *Server) UpdateUser(ctx echo.Context) error {
 		return ctx.JSON(http.StatusInternalServerError, err.Error())
 	}
 
+	// A new phone number only becomes the user's number once the code sent
+	// to it is confirmed; until then it is kept as pending.
 	user.FullName = input.FullName
-	user.PhoneNumber = input.PhoneNumber
+	phoneChanged := input.PhoneNumber != user.PhoneNumber
+	if phoneChanged {
+		user.PendingPhoneNumber = &input.PhoneNumber
+	} else {
+		user.PendingPhoneNumber = nil
+	}
 
 	err = s.Repository.UpdateUser(rCtx, user)
 	if err != nil {
@@ -151,5 +269,73 @@ func (s *Server) UpdateUser(ctx echo.Context) error {
 		return ctx.JSON(http.StatusInternalServerError, err.Error())
 	}
 
+	if phoneChanged {
+		err = s.sendOTP(rCtx, user.ID, input.PhoneNumber, repository.OTPPurposePhoneChange, phoneChangeMessageFormat)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+		}
+		return ctx.JSON(http.StatusOK, generated.DefaultUpdateResponse{Message: "user updated successfully, confirm the new phone number with the code sent to it"})
+	}
+
 	return ctx.JSON(http.StatusOK, generated.DefaultUpdateResponse{Message: "user updated successfully"})
 }
+
+// ChangePassword asks for the current password again before replacing it, then
+// signs the user out everywhere so a stolen session does not outlive the
+// password it was opened with.
+func (s *Server) ChangePassword(ctx echo.Context) error {
+	rCtx := ctx.Request().Context()
+	guid := uuid.MustParse(ctx.Get("UserGUID").(string))
+	var errData *tools.Err
+
+	var input generated.ChangePasswordJSONRequestBody
+	err := ctx.Bind(&input)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	err = tools.ValidateRequestPayload(input)
+	if err != nil && errors.As(err, &errData) {
+		return ctx.JSON(errData.Code, generated.ErrorWithExtraResponse{Message: errData.Message, Extra: &errData.Extra})
+	}
+
+	user, err := s.Repository.GetUserByGUID(rCtx, guid)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ctx.JSON(http.StatusNotFound, generated.ErrorResponse{Message: "user is not found"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	if !tools.IsValidPassword(user.Password, input.CurrentPassword) {
+		return ctx.JSON(http.StatusForbidden, generated.ErrorResponse{Message: "invalid current password"})
+	}
+
+	err = s.checkPasswordReuse(rCtx, user.ID, user.Password, input.NewPassword)
+	if err != nil {
+		if err == errPasswordReused {
+			return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: err.Error()})
+		}
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	hashedPassword, err := s.hashPassword(input.NewPassword)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	err = s.Repository.UpdateUserPassword(rCtx, user.ID, hashedPassword, s.Config.PasswordHistorySize)
+	if err != nil {
+		if errors.As(err, &errData) {
+			return ctx.JSON(errData.Code, generated.ErrorResponse{Message: errData.Message})
+		}
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	err = s.revokeAllUserTokens(rCtx, guid)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, generated.DefaultUpdateResponse{Message: "password changed successfully, please log in again"})
+}
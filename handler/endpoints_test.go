@@ -11,7 +11,9 @@ import (
 	"time"
 
 	"github.com/SawitProRecruitment/UserService/config"
+	"github.com/SawitProRecruitment/UserService/generated"
 	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/sms"
 	"github.com/SawitProRecruitment/UserService/tools"
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
@@ -52,7 +54,22 @@ func TestRegisterUser(t *testing.T) {
 		}
 
 		mockRepo := repository.NewMockRepositoryInterface(ctrl)
-		mockRepo.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().CreateUser(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ interface{}, user *repository.User) error {
+				user.ID = 1
+				return nil
+			},
+		)
+		mockRepo.EXPECT().GetLatestOTPCode(gomock.Any(), 1, repository.OTPPurposePhoneVerification).Return(nil, sql.ErrNoRows)
+		mockRepo.EXPECT().CreateOTPCode(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ interface{}, code *repository.OTPCode) error {
+				assert.Equal(t, repository.OTPPurposePhoneVerification, code.Purpose)
+				assert.Equal(t, "+62345678901", code.PhoneNumber)
+				return nil
+			},
+		)
+		mockSender := sms.NewMockSMSSender(ctrl)
+		mockSender.EXPECT().Send(gomock.Any(), "+62345678901", gomock.Any()).Return(nil)
 
 		req := httptest.NewRequest(http.MethodPost, "/register", bytes.NewBuffer(requestJSON))
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
@@ -61,6 +78,8 @@ func TestRegisterUser(t *testing.T) {
 
 		s := &Server{
 			Repository: mockRepo,
+			SMSSender:  mockSender,
+			Config:     MockOTPConfig(),
 		}
 
 		err = s.RegisterUser(ctx)
@@ -68,6 +87,39 @@ func TestRegisterUser(t *testing.T) {
 		assert.NoError(t, err, "should be no error")
 		assert.Equal(t, http.StatusCreated, rec.Code)
 	})
+
+	t.Run("when success register user even though the verification code is not sent", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().GetLatestOTPCode(gomock.Any(), gomock.Any(), repository.OTPPurposePhoneVerification).Return(nil, sql.ErrNoRows)
+		mockRepo.EXPECT().CreateOTPCode(gomock.Any(), gomock.Any()).Return(nil)
+		mockSender := sms.NewMockSMSSender(ctrl)
+		mockSender.EXPECT().Send(gomock.Any(), "+62345678901", gomock.Any()).Return(fmt.Errorf("gateway is down"))
+
+		reqParam := testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			token:      "",
+			url:        "/register",
+			body:       []byte(`{"full_name": "SawitPro Mania", "password": "IloveVirginCo2Nut123$", "phone_number": "+62345678901"}`),
+		}
+		ctx, rec := TestRequestEndpoint(reqParam)
+
+		s := &Server{
+			Repository: mockRepo,
+			SMSSender:  mockSender,
+			Config:     MockOTPConfig(),
+		}
+
+		err := s.RegisterUser(ctx)
+
+		assert.NoError(t, err, "should be no error")
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	})
 }
 
 func TestRegisterUser_Error(t *testing.T) {
@@ -183,6 +235,8 @@ func TestLoginUser(t *testing.T) {
 		}
 		mockRepo := repository.NewMockRepositoryInterface(ctrl)
 		mockRepo.EXPECT().GetUserLoginByPhoneNumber(gomock.Any(), "+62345678901").Return(mockOutput, nil)
+		mockRepo.EXPECT().GetUserAccess(gomock.Any(), gomock.Any()).Return(repository.UserAccess{}, nil)
+		mockRepo.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Return(nil)
 
 		reqParam := testRequestEndpointParam{
 			e:          e,
@@ -194,8 +248,9 @@ func TestLoginUser(t *testing.T) {
 		ctx, rec := TestRequestEndpoint(reqParam)
 
 		mockConfig := &config.Config{
-			RSAPrivateKey:           tools.MockRSAPrivateKey(),
-			JWTTokenLifetimeInHours: 8,
+			RSAPrivateKey:               tools.MockRSAPrivateKey(),
+			JWTTokenLifetimeInHours:     8,
+			RefreshTokenLifetimeInHours: 720,
 		}
 
 		s := &Server{
@@ -208,6 +263,52 @@ func TestLoginUser(t *testing.T) {
 		assert.NoError(t, err, "should be no error")
 		assert.Equal(t, http.StatusOK, rec.Code)
 	})
+
+	t.Run("when success ask for a second factor", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		hashed, _ := tools.HashPassword("IloveVirginCo2Nut123$")
+		mockOutput := repository.LoginUserOutput{
+			ID:          1,
+			GUID:        uuid.New(),
+			FullName:    "joz gandoz",
+			Password:    hashed,
+			TOTPEnabled: true,
+		}
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserLoginByPhoneNumber(gomock.Any(), "+62345678901").Return(mockOutput, nil)
+		mockRepo.EXPECT().CreateLoginChallenge(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ interface{}, challenge *repository.LoginChallenge) error {
+				assert.Equal(t, 1, challenge.UserID)
+				return nil
+			},
+		)
+
+		reqParam := testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			token:      "",
+			url:        "/login",
+			body:       []byte(`{"password": "IloveVirginCo2Nut123$", "phone_number": "+62345678901"}`),
+		}
+		ctx, rec := TestRequestEndpoint(reqParam)
+
+		s := &Server{
+			Repository: mockRepo,
+			Config:     config.Config{LoginChallengeLifetimeInSeconds: 300},
+		}
+
+		err := s.LoginUser(ctx)
+
+		assert.NoError(t, err, "should be no error")
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+
+		var resp generated.TwoFactorChallengeResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.NotEmpty(t, resp.ChallengeToken)
+	})
 }
 
 func TestLoginUser_Error(t *testing.T) {
@@ -318,6 +419,35 @@ func TestLoginUser_Error(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, rec.Code)
 	})
 
+	t.Run("when error due to phone number is not verified", func(t *testing.T) {
+		e := echo.New()
+		reqParam := testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			token:      "",
+			url:        "/login",
+			body:       []byte(`{"password": "IloveVirginCo2Nut123$", "phone_number": "+62345678901"}`),
+		}
+		ctx, rec := TestRequestEndpoint(reqParam)
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		hashed, _ := tools.HashPassword("IloveVirginCo2Nut123$")
+		mockOutput := repository.LoginUserOutput{
+			GUID:     uuid.New(),
+			FullName: "joz gandoz",
+			Password: hashed,
+		}
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserLoginByPhoneNumber(gomock.Any(), "+62345678901").Return(mockOutput, nil)
+		s := &Server{
+			Repository: mockRepo,
+			Config:     config.Config{LoginRequireVerifiedPhone: true},
+		}
+
+		_ = s.LoginUser(ctx)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
 	t.Run("when error generating jwt token", func(t *testing.T) {
 		e := echo.New()
 		reqParam := testRequestEndpointParam{
@@ -338,6 +468,7 @@ func TestLoginUser_Error(t *testing.T) {
 		}
 		mockRepo := repository.NewMockRepositoryInterface(ctrl)
 		mockRepo.EXPECT().GetUserLoginByPhoneNumber(gomock.Any(), "+62345678901").Return(mockOutput, nil)
+		mockRepo.EXPECT().GetUserAccess(gomock.Any(), gomock.Any()).Return(repository.UserAccess{}, nil)
 		s := &Server{
 			Repository: mockRepo,
 		}
@@ -510,6 +641,58 @@ func TestUpdateUser(t *testing.T) {
 
 		assert.Equal(t, http.StatusOK, rec.Code)
 	})
+
+	t.Run("when success keep a new phone number pending", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		user := MockUser()
+		requestBody := `{
+			"full_name":   "test update user",
+			"phone_number": "+62345678999"
+		}`
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), user.GUID).Return(user, nil)
+		mockRepo.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ interface{}, updated *repository.User) error {
+				assert.Equal(t, "+62345678901", updated.PhoneNumber)
+				if assert.NotNil(t, updated.PendingPhoneNumber) {
+					assert.Equal(t, "+62345678999", *updated.PendingPhoneNumber)
+				}
+				return nil
+			},
+		)
+		mockRepo.EXPECT().GetLatestOTPCode(gomock.Any(), user.ID, repository.OTPPurposePhoneChange).Return(nil, sql.ErrNoRows)
+		mockRepo.EXPECT().CreateOTPCode(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ interface{}, code *repository.OTPCode) error {
+				assert.Equal(t, "+62345678999", code.PhoneNumber)
+				return nil
+			},
+		)
+		mockSender := sms.NewMockSMSSender(ctrl)
+		mockSender.EXPECT().Send(gomock.Any(), "+62345678999", gomock.Any()).Return(nil)
+
+		reqParam := testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPut,
+			token:      token,
+			url:        "/users",
+			body:       []byte(requestBody),
+		}
+		ctx, rec := TestRequestEndpoint(reqParam)
+		ctx.Set("UserGUID", user.GUID.String())
+
+		s := &Server{
+			Repository: mockRepo,
+			SMSSender:  mockSender,
+			Config:     MockOTPConfig(),
+		}
+
+		_ = s.UpdateUser(ctx)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
 }
 
 func TestUpdateUser_Error(t *testing.T) {
@@ -685,3 +868,124 @@ func TestUpdateUser_Error(t *testing.T) {
 		assert.Equal(t, http.StatusConflict, rec.Code)
 	})
 }
+
+func TestChangePassword(t *testing.T) {
+	t.Run("when success change password", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		hashedPassword, err := tools.HashPassword(mockUser.Password)
+		assert.NoError(t, err)
+		mockUser.Password = hashedPassword
+
+		requestBody := `{
+			"current_password": "IloveVirginCo2Nut123$",
+			"new_password":     "IloveRedPalmOil456$"
+		}`
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), mockUser.GUID).Return(mockUser, nil)
+		mockRepo.EXPECT().UpdateUserPassword(gomock.Any(), mockUser.ID, gomock.Any(), 0).DoAndReturn(
+			func(_ interface{}, _ int, newHash string, _ int) error {
+				assert.True(t, tools.IsValidPassword(newHash, "IloveRedPalmOil456$"))
+				return nil
+			},
+		)
+		mockRepo.EXPECT().RevokeUserRefreshTokens(gomock.Any(), mockUser.GUID).Return(nil)
+		mockRevocations := repository.NewMockTokenRevocationRepositoryInterface(ctrl)
+		mockRevocations.EXPECT().RevokeUserAccessTokens(gomock.Any(), mockUser.GUID, gomock.Any()).Return(nil)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodPut, url: "/users/password", body: []byte(requestBody)})
+		ctx.Set("UserGUID", mockUser.GUID.String())
+
+		s := &Server{
+			Repository:       mockRepo,
+			TokenRevocations: mockRevocations,
+		}
+
+		err = s.ChangePassword(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
+
+func TestChangePassword_Error(t *testing.T) {
+	mockUser := MockUser()
+	hashedPassword, err := tools.HashPassword(mockUser.Password)
+	assert.NoError(t, err)
+	mockUser.Password = hashedPassword
+
+	t.Run("when error validate request body", func(t *testing.T) {
+		e := echo.New()
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPut,
+			url:        "/users/password",
+			body:       []byte(`{"current_password": "IloveVirginCo2Nut123$", "new_password": "weak"}`),
+		})
+		ctx.Set("UserGUID", mockUser.GUID.String())
+
+		s := &Server{}
+		_ = s.ChangePassword(ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("when error due to new password is the current password", func(t *testing.T) {
+		e := echo.New()
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPut,
+			url:        "/users/password",
+			body:       []byte(`{"current_password": "IloveVirginCo2Nut123$", "new_password": "IloveVirginCo2Nut123$"}`),
+		})
+		ctx.Set("UserGUID", mockUser.GUID.String())
+
+		s := &Server{}
+		_ = s.ChangePassword(ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("when error due to current password is wrong", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), mockUser.GUID).Return(mockUser, nil)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPut,
+			url:        "/users/password",
+			body:       []byte(`{"current_password": "WrongPassword1$", "new_password": "IloveRedPalmOil456$"}`),
+		})
+		ctx.Set("UserGUID", mockUser.GUID.String())
+
+		s := &Server{Repository: mockRepo}
+		_ = s.ChangePassword(ctx)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("when error to update password", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), mockUser.GUID).Return(mockUser, nil)
+		mockRepo.EXPECT().UpdateUserPassword(gomock.Any(), mockUser.ID, gomock.Any(), 0).Return(&tools.Err{Code: http.StatusInternalServerError, Message: "connection refused"})
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPut,
+			url:        "/users/password",
+			body:       []byte(`{"current_password": "IloveVirginCo2Nut123$", "new_password": "IloveRedPalmOil456$"}`),
+		})
+		ctx.Set("UserGUID", mockUser.GUID.String())
+
+		s := &Server{Repository: mockRepo}
+		_ = s.ChangePassword(ctx)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
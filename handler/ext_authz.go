@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	rpcstatus "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+)
+
+// ExtAuthzServer implements Envoy's external authorization API, so a mesh can
+// check bearer tokens here with the same rules JWTMiddleware applies.
+type ExtAuthzServer struct {
+	authv3.UnimplementedAuthorizationServer
+
+	verifier *TokenVerifier
+}
+
+func NewExtAuthzServer(verifier *TokenVerifier) *ExtAuthzServer {
+	return &ExtAuthzServer{verifier: verifier}
+}
+
+// Check allows the request with the principal headers added, or denies it
+// with the status and body JWTMiddleware would have answered with.
+func (s *ExtAuthzServer) Check(ctx context.Context, req *authv3.CheckRequest) (*authv3.CheckResponse, error) {
+	// Envoy lowercases header names before sending them.
+	headers := req.GetAttributes().GetRequest().GetHttp().GetHeaders()
+	tokenString, err := parseBearerToken(headers["authorization"])
+	if err != nil {
+		return deniedCheckResponse(err), nil
+	}
+
+	claims, err := s.verifier.Verify(ctx, tokenString)
+	if err != nil {
+		return deniedCheckResponse(err), nil
+	}
+
+	okResponse := new(authv3.OkHttpResponse)
+	principal := principalHeaders(claims)
+	for _, name := range []string{HeaderUserGUID, HeaderUserName, HeaderClientID} {
+		value, ok := principal[name]
+		if !ok {
+			// Drop whatever the caller sent so upstreams cannot be fooled
+			// into trusting a forged header.
+			okResponse.HeadersToRemove = append(okResponse.HeadersToRemove, name)
+			continue
+		}
+		okResponse.Headers = append(okResponse.Headers, &corev3.HeaderValueOption{
+			Header:       &corev3.HeaderValue{Key: name, Value: value},
+			AppendAction: corev3.HeaderValueOption_OVERWRITE_IF_EXISTS_OR_ADD,
+		})
+	}
+
+	return &authv3.CheckResponse{
+		Status:       &rpcstatus.Status{Code: int32(codes.OK)},
+		HttpResponse: &authv3.CheckResponse_OkResponse{OkResponse: okResponse},
+	}, nil
+}
+
+func deniedCheckResponse(err error) *authv3.CheckResponse {
+	code, message, challenge := authenticationFailure(err)
+
+	rpcCode := codes.PermissionDenied
+	switch code {
+	case http.StatusUnauthorized:
+		rpcCode = codes.Unauthenticated
+	case http.StatusInternalServerError:
+		rpcCode = codes.Internal
+	}
+
+	deniedResponse := &authv3.DeniedHttpResponse{
+		Status: &typev3.HttpStatus{Code: typev3.StatusCode(code)},
+		Headers: []*corev3.HeaderValueOption{{
+			Header:       &corev3.HeaderValue{Key: "Content-Type", Value: "application/json"},
+			AppendAction: corev3.HeaderValueOption_OVERWRITE_IF_EXISTS_OR_ADD,
+		}},
+	}
+	if challenge != "" {
+		deniedResponse.Headers = append(deniedResponse.Headers, &corev3.HeaderValueOption{
+			Header:       &corev3.HeaderValue{Key: "WWW-Authenticate", Value: challenge},
+			AppendAction: corev3.HeaderValueOption_OVERWRITE_IF_EXISTS_OR_ADD,
+		})
+	}
+	if body, marshalErr := json.Marshal(generated.ErrorResponse{Message: message}); marshalErr == nil {
+		deniedResponse.Body = string(body)
+	}
+
+	return &authv3.CheckResponse{
+		Status:       &rpcstatus.Status{Code: int32(rpcCode), Message: message},
+		HttpResponse: &authv3.CheckResponse_DeniedResponse{DeniedResponse: deniedResponse},
+	}
+}
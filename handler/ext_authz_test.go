@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/SawitProRecruitment/UserService/config"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/tools"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// extAuthzClient serves an ExtAuthzServer over an in-memory listener and
+// returns a client connected to it, the way Envoy would call it.
+func extAuthzClient(t *testing.T, revocations repository.TokenRevocationRepositoryInterface) authv3.AuthorizationClient {
+	keyRing, err := tools.NewKeyRing(tools.KeyRingOptions{PrivateKey: tools.MockRSAPrivateKey()})
+	assert.NoError(t, err)
+
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer()
+	authv3.RegisterAuthorizationServer(grpcServer, NewExtAuthzServer(NewTokenVerifier(config.Config{}, keyRing, revocations)))
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return authv3.NewAuthorizationClient(conn)
+}
+
+func checkRequest(headers map[string]string) *authv3.CheckRequest {
+	return &authv3.CheckRequest{
+		Attributes: &authv3.AttributeContext{
+			Request: &authv3.AttributeContext_Request{
+				Http: &authv3.AttributeContext_HttpRequest{Method: http.MethodGet, Path: "/reports", Headers: headers},
+			},
+		},
+	}
+}
+
+func TestExtAuthzCheck(t *testing.T) {
+	t.Run("when success allow a user token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		userToken, _, err := tools.GenerateJWTToken(tools.GenerateJWTTokenParams{FullName: mockUser.FullName, GUID: mockUser.GUID}, 1, tools.MockRSAPrivateKey())
+		assert.NoError(t, err)
+
+		mockRevocations := repository.NewMockTokenRevocationRepositoryInterface(ctrl)
+		mockRevocations.EXPECT().IsAccessTokenRevoked(gomock.Any(), gomock.Any()).Return(false, nil)
+
+		resp, err := extAuthzClient(t, mockRevocations).Check(context.Background(), checkRequest(map[string]string{
+			"authorization": "Bearer " + userToken,
+			"x-client-id":   "forged",
+		}))
+		assert.NoError(t, err)
+		assert.Equal(t, int32(codes.OK), resp.GetStatus().GetCode())
+
+		headers := map[string]string{}
+		for _, header := range resp.GetOkResponse().GetHeaders() {
+			headers[header.GetHeader().GetKey()] = header.GetHeader().GetValue()
+		}
+		assert.Equal(t, mockUser.GUID.String(), headers[HeaderUserGUID])
+		assert.Equal(t, mockUser.FullName, headers[HeaderUserName])
+		assert.Contains(t, resp.GetOkResponse().GetHeadersToRemove(), HeaderClientID)
+	})
+
+	t.Run("when success allow a machine token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		clientToken, _, err := tools.GenerateClientJWTToken(tools.GenerateClientJWTTokenParams{ClientID: "billing-job"}, 1, tools.MockRSAPrivateKey())
+		assert.NoError(t, err)
+
+		mockRevocations := repository.NewMockTokenRevocationRepositoryInterface(ctrl)
+		mockRevocations.EXPECT().IsAccessTokenRevoked(gomock.Any(), gomock.Any()).Return(false, nil)
+
+		resp, err := extAuthzClient(t, mockRevocations).Check(context.Background(), checkRequest(map[string]string{"authorization": "Bearer " + clientToken}))
+		assert.NoError(t, err)
+		assert.Equal(t, int32(codes.OK), resp.GetStatus().GetCode())
+		assert.Equal(t, HeaderClientID, resp.GetOkResponse().GetHeaders()[0].GetHeader().GetKey())
+		assert.Equal(t, "billing-job", resp.GetOkResponse().GetHeaders()[0].GetHeader().GetValue())
+		assert.ElementsMatch(t, []string{HeaderUserGUID, HeaderUserName}, resp.GetOkResponse().GetHeadersToRemove())
+	})
+}
+
+func TestExtAuthzCheck_Error(t *testing.T) {
+	t.Run("when error due to token is missing", func(t *testing.T) {
+		resp, err := extAuthzClient(t, nil).Check(context.Background(), checkRequest(nil))
+		assert.NoError(t, err)
+		assert.Equal(t, int32(codes.Unauthenticated), resp.GetStatus().GetCode())
+		assert.Equal(t, http.StatusUnauthorized, int(resp.GetDeniedResponse().GetStatus().GetCode()))
+		assert.JSONEq(t, `{"message":"Missing JWT token"}`, resp.GetDeniedResponse().GetBody())
+	})
+
+	t.Run("when error due to token is invalid", func(t *testing.T) {
+		resp, err := extAuthzClient(t, nil).Check(context.Background(), checkRequest(map[string]string{"authorization": "Bearer invalid"}))
+		assert.NoError(t, err)
+		assert.Equal(t, int32(codes.PermissionDenied), resp.GetStatus().GetCode())
+		assert.Equal(t, http.StatusForbidden, int(resp.GetDeniedResponse().GetStatus().GetCode()))
+	})
+
+	t.Run("when error due to token has been revoked", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		userToken, _, err := tools.GenerateJWTToken(tools.GenerateJWTTokenParams{FullName: mockUser.FullName, GUID: mockUser.GUID}, 1, tools.MockRSAPrivateKey())
+		assert.NoError(t, err)
+
+		mockRevocations := repository.NewMockTokenRevocationRepositoryInterface(ctrl)
+		mockRevocations.EXPECT().IsAccessTokenRevoked(gomock.Any(), gomock.Any()).Return(true, nil)
+
+		resp, err := extAuthzClient(t, mockRevocations).Check(context.Background(), checkRequest(map[string]string{"authorization": "Bearer " + userToken}))
+		assert.NoError(t, err)
+		assert.Equal(t, int32(codes.Unauthenticated), resp.GetStatus().GetCode())
+
+		headers := map[string]string{}
+		for _, header := range resp.GetDeniedResponse().GetHeaders() {
+			headers[header.GetHeader().GetKey()] = header.GetHeader().GetValue()
+		}
+		assert.Equal(t, invalidTokenChallenge, headers["WWW-Authenticate"])
+	})
+}
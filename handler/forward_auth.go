@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/tools"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// Headers describing the caller to the upstream service.
+const (
+	HeaderUserGUID     = "X-User-GUID"
+	HeaderUserName     = "X-User-Name"
+	HeaderClientID     = "X-Client-ID"
+	HeaderAuthRedirect = "X-Auth-Redirect"
+)
+
+// VerifyForwardAuth answers nginx auth_request and Traefik ForwardAuth
+// subrequests with the same checks JWTMiddleware runs.
+func (s *Server) VerifyForwardAuth(ctx echo.Context, params generated.VerifyForwardAuthParams) error {
+	tokenString, err := s.forwardAuthToken(ctx)
+	if err == nil {
+		claims, verifyErr := s.tokenVerifier().Verify(ctx.Request().Context(), tokenString)
+		if verifyErr == nil {
+			for name, value := range principalHeaders(claims) {
+				ctx.Response().Header().Set(name, value)
+			}
+			return ctx.NoContent(http.StatusOK)
+		}
+		err = verifyErr
+	}
+
+	httpErr := authenticationError(ctx, err)
+	if httpErr.Code == http.StatusInternalServerError || s.Config.ForwardAuthLoginURL == "" {
+		return ctx.JSON(httpErr.Code, generated.ErrorResponse{Message: httpErr.Message.(string)})
+	}
+
+	loginURL := s.forwardAuthLoginURL(ctx)
+	if params.Redirect != nil && *params.Redirect {
+		// Traefik passes the response through as is, so the browser has to
+		// be redirected from here.
+		return ctx.Redirect(http.StatusFound, loginURL)
+	}
+
+	// nginx only looks at the status; the login page is picked up with
+	// auth_request_set from this header.
+	ctx.Response().Header().Set(HeaderAuthRedirect, loginURL)
+	return ctx.JSON(httpErr.Code, generated.ErrorResponse{Message: httpErr.Message.(string)})
+}
+
+// principalHeaders describes the token's principal to the upstream service:
+// the user for user tokens, otherwise the client.
+func principalHeaders(claims *tools.JWTCustomClaims) map[string]string {
+	if claims.GUID == uuid.Nil {
+		return map[string]string{HeaderClientID: claims.ClientID}
+	}
+	return map[string]string{
+		HeaderUserGUID: claims.GUID.String(),
+		HeaderUserName: claims.FullName,
+	}
+}
+
+// forwardAuthToken reads the bearer token, falling back to the configured
+// cookie since browsers visiting a proxied service do not send one.
+func (s *Server) forwardAuthToken(ctx echo.Context) (string, error) {
+	tokenString, err := bearerToken(ctx)
+	if err != errMissingToken || s.Config.ForwardAuthTokenCookie == "" {
+		return tokenString, err
+	}
+
+	cookie, cookieErr := ctx.Cookie(s.Config.ForwardAuthTokenCookie)
+	if cookieErr != nil || cookie.Value == "" {
+		return "", errMissingToken
+	}
+	return cookie.Value, nil
+}
+
+// forwardAuthLoginURL points at the login page with the originally requested
+// URL in the rd parameter, taken from the headers the proxy forwards.
+func (s *Server) forwardAuthLoginURL(ctx echo.Context) string {
+	loginURL, err := url.Parse(s.Config.ForwardAuthLoginURL)
+	if err != nil {
+		return s.Config.ForwardAuthLoginURL
+	}
+
+	header := ctx.Request().Header
+	originalURL := header.Get("X-Original-URL")
+	if originalURL == "" && header.Get("X-Forwarded-Host") != "" {
+		scheme := header.Get(echo.HeaderXForwardedProto)
+		if scheme == "" {
+			scheme = "https"
+		}
+		originalURL = scheme + "://" + header.Get("X-Forwarded-Host") + header.Get("X-Forwarded-Uri")
+	}
+	if originalURL == "" {
+		return loginURL.String()
+	}
+
+	query := loginURL.Query()
+	query.Set("rd", originalURL)
+	loginURL.RawQuery = query.Encode()
+	return loginURL.String()
+}
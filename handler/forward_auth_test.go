@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/tools"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyForwardAuth(t *testing.T) {
+	mockUser := MockUser()
+	userToken, _, err := tools.GenerateJWTToken(tools.GenerateJWTTokenParams{FullName: mockUser.FullName, GUID: mockUser.GUID}, 1, tools.MockRSAPrivateKey())
+	assert.NoError(t, err)
+
+	t.Run("when success verify a user token", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRevocations := repository.NewMockTokenRevocationRepositoryInterface(ctrl)
+		mockRevocations.EXPECT().IsAccessTokenRevoked(gomock.Any(), gomock.Any()).Return(false, nil)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodGet, url: "/auth/verify", token: userToken})
+
+		err := tokenVerifyingServer(t, nil, mockRevocations).VerifyForwardAuth(ctx, generated.VerifyForwardAuthParams{})
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, mockUser.GUID.String(), rec.Header().Get(HeaderUserGUID))
+		assert.Equal(t, mockUser.FullName, rec.Header().Get(HeaderUserName))
+	})
+
+	t.Run("when success verify a token from the configured cookie", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRevocations := repository.NewMockTokenRevocationRepositoryInterface(ctrl)
+		mockRevocations.EXPECT().IsAccessTokenRevoked(gomock.Any(), gomock.Any()).Return(false, nil)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodGet, url: "/auth/verify"})
+		ctx.Request().AddCookie(&http.Cookie{Name: "access_token", Value: userToken})
+
+		s := tokenVerifyingServer(t, nil, mockRevocations)
+		s.Config.ForwardAuthTokenCookie = "access_token"
+
+		err := s.VerifyForwardAuth(ctx, generated.VerifyForwardAuthParams{})
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, mockUser.GUID.String(), rec.Header().Get(HeaderUserGUID))
+	})
+
+	t.Run("when success verify a machine token", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		clientToken, _, err := tools.GenerateClientJWTToken(tools.GenerateClientJWTTokenParams{ClientID: "billing-job"}, 1, tools.MockRSAPrivateKey())
+		assert.NoError(t, err)
+
+		mockRevocations := repository.NewMockTokenRevocationRepositoryInterface(ctrl)
+		mockRevocations.EXPECT().IsAccessTokenRevoked(gomock.Any(), gomock.Any()).Return(false, nil)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodGet, url: "/auth/verify", token: clientToken})
+
+		err = tokenVerifyingServer(t, nil, mockRevocations).VerifyForwardAuth(ctx, generated.VerifyForwardAuthParams{})
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "billing-job", rec.Header().Get(HeaderClientID))
+		assert.Empty(t, rec.Header().Get(HeaderUserGUID))
+	})
+}
+
+func TestVerifyForwardAuth_Error(t *testing.T) {
+	t.Run("when error due to token is missing", func(t *testing.T) {
+		e := echo.New()
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodGet, url: "/auth/verify"})
+
+		err := tokenVerifyingServer(t, nil, nil).VerifyForwardAuth(ctx, generated.VerifyForwardAuthParams{})
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Empty(t, rec.Header().Get(HeaderAuthRedirect))
+	})
+
+	t.Run("when error due to token is invalid with a login page configured", func(t *testing.T) {
+		e := echo.New()
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodGet, url: "/auth/verify", token: "invalid"})
+		ctx.Request().Header.Set("X-Original-URL", "https://legacy.example.com/reports?id=1")
+
+		s := tokenVerifyingServer(t, nil, nil)
+		s.Config.ForwardAuthLoginURL = "https://login.example.com/"
+
+		err := s.VerifyForwardAuth(ctx, generated.VerifyForwardAuthParams{})
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Equal(t, "https://login.example.com/?rd=https%3A%2F%2Flegacy.example.com%2Freports%3Fid%3D1", rec.Header().Get(HeaderAuthRedirect))
+	})
+
+	t.Run("when error due to token is missing and a redirect is asked for", func(t *testing.T) {
+		e := echo.New()
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodGet, url: "/auth/verify?redirect=true"})
+		ctx.Request().Header.Set("X-Forwarded-Proto", "https")
+		ctx.Request().Header.Set("X-Forwarded-Host", "legacy.example.com")
+		ctx.Request().Header.Set("X-Forwarded-Uri", "/reports")
+
+		s := tokenVerifyingServer(t, nil, nil)
+		s.Config.ForwardAuthLoginURL = "https://login.example.com/"
+
+		redirect := true
+		err := s.VerifyForwardAuth(ctx, generated.VerifyForwardAuthParams{Redirect: &redirect})
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusFound, rec.Code)
+		assert.Equal(t, "https://login.example.com/?rd=https%3A%2F%2Flegacy.example.com%2Freports", rec.Header().Get(echo.HeaderLocation))
+	})
+
+	t.Run("when error to check revocation", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		userToken, _, err := tools.GenerateJWTToken(tools.GenerateJWTTokenParams{FullName: mockUser.FullName, GUID: mockUser.GUID}, 1, tools.MockRSAPrivateKey())
+		assert.NoError(t, err)
+
+		mockRevocations := repository.NewMockTokenRevocationRepositoryInterface(ctrl)
+		mockRevocations.EXPECT().IsAccessTokenRevoked(gomock.Any(), gomock.Any()).Return(false, assert.AnError)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodGet, url: "/auth/verify", token: userToken})
+
+		s := tokenVerifyingServer(t, nil, mockRevocations)
+		s.Config.ForwardAuthLoginURL = "https://login.example.com/"
+
+		err = s.VerifyForwardAuth(ctx, generated.VerifyForwardAuthParams{})
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Empty(t, rec.Header().Get(HeaderAuthRedirect))
+	})
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/labstack/echo/v4"
+)
+
+// Password logins are throttled per account and per client IP. Both counters
+// live in the database so a lock holds across restarts and replicas.
+
+func userLoginSubject(userID int) string {
+	return fmt.Sprintf("user:%d", userID)
+}
+
+func ipLoginSubject(ip string) string {
+	return "ip:" + ip
+}
+
+// loginBlock reports how long subject has to wait before its next password
+// login, and whether that is because it hit maxAttempts rather than a backoff.
+func (s *Server) loginBlock(ctx context.Context, subject string, maxAttempts int) (wait time.Duration, locked bool, err error) {
+	throttle, err := s.Repository.GetLoginThrottle(ctx, subject)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	if throttle.BlockedUntil == nil {
+		return 0, false, nil
+	}
+
+	wait = throttle.BlockedUntil.Sub(time.Now().UTC())
+	if wait <= 0 {
+		return 0, false, nil
+	}
+	return wait, throttle.FailedAttempts >= maxAttempts, nil
+}
+
+// recordLoginFailure counts a failed password for subject and blocks it for
+// the backoff the new count earns.
+func (s *Server) recordLoginFailure(ctx context.Context, subject string, maxAttempts int) error {
+	lockout := time.Duration(s.Config.LoginLockoutDurationInSeconds) * time.Second
+
+	failedAttempts, err := s.Repository.RecordLoginFailure(ctx, subject, lockout)
+	if err != nil {
+		return err
+	}
+
+	delay := s.loginBackoff(failedAttempts, maxAttempts)
+	if delay == 0 {
+		return nil
+	}
+	return s.Repository.BlockLoginSubject(ctx, subject, time.Now().UTC().Add(delay))
+}
+
+// recordUserLoginFailure counts a wrong password against the account.
+func (s *Server) recordUserLoginFailure(ctx context.Context, userID int) error {
+	if s.Config.LoginMaxFailedAttempts <= 0 {
+		return nil
+	}
+	return s.recordLoginFailure(ctx, userLoginSubject(userID), s.Config.LoginMaxFailedAttempts)
+}
+
+// recordIPLoginFailure counts a failed login against the client IP, which
+// catches guessing spread over many accounts.
+func (s *Server) recordIPLoginFailure(ctx context.Context, subject string) error {
+	if s.Config.LoginMaxFailedAttemptsPerIP <= 0 {
+		return nil
+	}
+	return s.recordLoginFailure(ctx, subject, s.Config.LoginMaxFailedAttemptsPerIP)
+}
+
+// loginBackoff doubles the wait with every failure after the first, so a typo
+// costs nothing, and locks the subject for the full lockout at maxAttempts.
+func (s *Server) loginBackoff(failedAttempts int, maxAttempts int) time.Duration {
+	lockout := time.Duration(s.Config.LoginLockoutDurationInSeconds) * time.Second
+	if failedAttempts >= maxAttempts {
+		return lockout
+	}
+	if failedAttempts < 2 {
+		return 0
+	}
+
+	delay := time.Duration(s.Config.LoginBackoffBaseInSeconds) * time.Second << (failedAttempts - 2)
+	if delay > lockout {
+		return lockout
+	}
+	return delay
+}
+
+func loginThrottledResponse(ctx echo.Context, wait time.Duration, locked bool) error {
+	ctx.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	message := "too many failed logins, try again later"
+	if locked {
+		message = "account is temporarily locked after too many failed logins, reset the password or log in with an SMS code to unlock it"
+	}
+	return ctx.JSON(http.StatusTooManyRequests, generated.ErrorResponse{Message: message})
+}
+
+// unlockUserLogin forgets the account's failed logins after a successful one,
+// and lifts a lock once the user proved they hold the phone through a
+// password reset or an SMS login.
+func (s *Server) unlockUserLogin(ctx context.Context, userID int) error {
+	if s.Config.LoginMaxFailedAttempts <= 0 {
+		return nil
+	}
+	return s.Repository.ClearLoginThrottle(ctx, userLoginSubject(userID))
+}
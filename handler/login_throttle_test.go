@@ -0,0 +1,273 @@
+package handler
+
+import (
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/config"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/tools"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func MockLoginThrottleConfig() config.Config {
+	return config.Config{
+		RSAPrivateKey:                 tools.MockRSAPrivateKey(),
+		JWTTokenLifetimeInHours:       8,
+		RefreshTokenLifetimeInHours:   720,
+		LoginMaxFailedAttempts:        5,
+		LoginMaxFailedAttemptsPerIP:   50,
+		LoginBackoffBaseInSeconds:     1,
+		LoginLockoutDurationInSeconds: 900,
+	}
+}
+
+// The test requests come from httptest's default remote address.
+const mockClientIPSubject = "ip:192.0.2.1"
+
+func loginRequest() testRequestEndpointParam {
+	return testRequestEndpointParam{
+		e:          echo.New(),
+		httpMethod: http.MethodPost,
+		url:        "/login",
+		body:       []byte(`{"password": "IloveVirginCo2Nut123$", "phone_number": "+62345678901"}`),
+	}
+}
+
+func TestLoginUser_Throttle(t *testing.T) {
+	mockUser := MockUser()
+	hashed, _ := tools.HashPassword(mockUser.Password)
+	mockOutput := MockLoginUserOutput(mockUser)
+	mockOutput.Password = hashed
+
+	t.Run("when success clear the failed logins of the account", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetLoginThrottle(gomock.Any(), mockClientIPSubject).Return(nil, sql.ErrNoRows)
+		mockRepo.EXPECT().GetUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber).Return(mockOutput, nil)
+		mockRepo.EXPECT().GetLoginThrottle(gomock.Any(), "user:1").Return(&repository.LoginThrottle{Subject: "user:1", FailedAttempts: 2}, nil)
+		mockRepo.EXPECT().ClearLoginThrottle(gomock.Any(), "user:1").Return(nil)
+		mockRepo.EXPECT().GetUserAccess(gomock.Any(), gomock.Any()).Return(repository.UserAccess{}, nil)
+		mockRepo.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Return(nil)
+
+		ctx, rec := TestRequestEndpoint(loginRequest())
+		s := &Server{Repository: mockRepo, Config: MockLoginThrottleConfig()}
+
+		err := s.LoginUser(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("when error wrong password count against the account and the ip", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		wrongPassword := mockOutput
+		wrongPassword.Password, _ = tools.HashPassword("SomethingElse123$")
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetLoginThrottle(gomock.Any(), mockClientIPSubject).Return(nil, sql.ErrNoRows)
+		mockRepo.EXPECT().GetUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber).Return(wrongPassword, nil)
+		mockRepo.EXPECT().GetLoginThrottle(gomock.Any(), "user:1").Return(nil, sql.ErrNoRows)
+		mockRepo.EXPECT().RecordLoginFailure(gomock.Any(), "user:1", 900*time.Second).Return(3, nil)
+		mockRepo.EXPECT().BlockLoginSubject(gomock.Any(), "user:1", gomock.Any()).DoAndReturn(
+			func(_ interface{}, _ string, until time.Time) error {
+				assert.WithinDuration(t, time.Now().UTC().Add(2*time.Second), until, time.Second)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().RecordLoginFailure(gomock.Any(), mockClientIPSubject, 900*time.Second).Return(1, nil)
+
+		ctx, rec := TestRequestEndpoint(loginRequest())
+		s := &Server{Repository: mockRepo, Config: MockLoginThrottleConfig()}
+
+		err := s.LoginUser(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("when error the account is locked", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		blockedUntil := time.Now().UTC().Add(10 * time.Minute)
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetLoginThrottle(gomock.Any(), mockClientIPSubject).Return(nil, sql.ErrNoRows)
+		mockRepo.EXPECT().GetUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber).Return(mockOutput, nil)
+		mockRepo.EXPECT().GetLoginThrottle(gomock.Any(), "user:1").Return(&repository.LoginThrottle{Subject: "user:1", FailedAttempts: 5, BlockedUntil: &blockedUntil}, nil)
+
+		ctx, rec := TestRequestEndpoint(loginRequest())
+		s := &Server{Repository: mockRepo, Config: MockLoginThrottleConfig()}
+
+		err := s.LoginUser(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "600", rec.Header().Get("Retry-After"))
+		assert.Contains(t, rec.Body.String(), "temporarily locked")
+	})
+
+	t.Run("when error the client ip is blocked", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		blockedUntil := time.Now().UTC().Add(30 * time.Second)
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetLoginThrottle(gomock.Any(), mockClientIPSubject).Return(&repository.LoginThrottle{Subject: mockClientIPSubject, FailedAttempts: 7, BlockedUntil: &blockedUntil}, nil)
+
+		ctx, rec := TestRequestEndpoint(loginRequest())
+		s := &Server{Repository: mockRepo, Config: MockLoginThrottleConfig()}
+
+		err := s.LoginUser(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+	})
+
+	t.Run("when error a spoofed forwarding header does not escape the ip block", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		blockedUntil := time.Now().UTC().Add(30 * time.Second)
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetLoginThrottle(gomock.Any(), mockClientIPSubject).Return(&repository.LoginThrottle{Subject: mockClientIPSubject, FailedAttempts: 50, BlockedUntil: &blockedUntil}, nil)
+
+		param := loginRequest()
+		param.e.IPExtractor, _ = NewIPExtractor("")
+		ctx, rec := TestRequestEndpoint(param)
+		ctx.Request().Header.Set(echo.HeaderXForwardedFor, "203.0.113.9")
+		ctx.Request().Header.Set(echo.HeaderXRealIP, "203.0.113.9")
+		s := &Server{Repository: mockRepo, Config: MockLoginThrottleConfig()}
+
+		err := s.LoginUser(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	})
+
+	t.Run("when error unknown phone number count against the ip", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetLoginThrottle(gomock.Any(), mockClientIPSubject).Return(nil, sql.ErrNoRows)
+		mockRepo.EXPECT().GetUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber).Return(repository.LoginUserOutput{}, sql.ErrNoRows)
+		mockRepo.EXPECT().RecordLoginFailure(gomock.Any(), mockClientIPSubject, 900*time.Second).Return(50, nil)
+		mockRepo.EXPECT().BlockLoginSubject(gomock.Any(), mockClientIPSubject, gomock.Any()).Return(nil)
+
+		ctx, rec := TestRequestEndpoint(loginRequest())
+		s := &Server{Repository: mockRepo, Config: MockLoginThrottleConfig()}
+
+		err := s.LoginUser(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestLoginBackoff(t *testing.T) {
+	s := &Server{Config: MockLoginThrottleConfig()}
+
+	tests := []struct {
+		failedAttempts int
+		want           time.Duration
+	}{
+		{failedAttempts: 1, want: 0},
+		{failedAttempts: 2, want: time.Second},
+		{failedAttempts: 3, want: 2 * time.Second},
+		{failedAttempts: 4, want: 4 * time.Second},
+		{failedAttempts: 5, want: 900 * time.Second},
+		{failedAttempts: 8, want: 900 * time.Second},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, s.loginBackoff(tt.failedAttempts, 5), "after %d failures", tt.failedAttempts)
+	}
+
+	assert.Equal(t, 900*time.Second, s.loginBackoff(20, 50), "delay is capped at the lockout")
+}
+
+func TestLoginTwoFactor_Throttle(t *testing.T) {
+	mockUser := MockUser()
+	cfg := MockTwoFactorConfig()
+	cfg.LoginMaxFailedAttempts = 5
+	cfg.LoginBackoffBaseInSeconds = 1
+	cfg.LoginLockoutDurationInSeconds = 900
+
+	twoFactorRequest := func(code string) testRequestEndpointParam {
+		return testRequestEndpointParam{
+			e:          echo.New(),
+			httpMethod: http.MethodPost,
+			url:        "/login/2fa",
+			body:       []byte(`{"challenge_token": "challenge-token", "code": "` + code + `"}`),
+		}
+	}
+
+	t.Run("when success the password alone does not clear the failed logins", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockOutput := MockLoginUserOutput(mockUser)
+		mockOutput.Password, _ = tools.HashPassword(mockUser.Password)
+		mockOutput.TOTPEnabled = true
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber).Return(mockOutput, nil)
+		mockRepo.EXPECT().GetLoginThrottle(gomock.Any(), "user:1").Return(&repository.LoginThrottle{Subject: "user:1", FailedAttempts: 3}, nil)
+		mockRepo.EXPECT().CreateLoginChallenge(gomock.Any(), gomock.Any()).Return(nil)
+
+		ctx, rec := TestRequestEndpoint(loginRequest())
+		s := &Server{Repository: mockRepo, Config: cfg}
+
+		err := s.LoginUser(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+	})
+
+	t.Run("when success the second factor clears the failed logins", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		totp, secret := MockUserTOTP(t, mockUser, true)
+		challenge := MockLoginChallenge(mockUser)
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetLoginChallengeByTokenHash(gomock.Any(), gomock.Any()).Return(challenge, nil)
+		mockRepo.EXPECT().UseLoginChallengeAttempt(gomock.Any(), challenge.ID, cfg.OTPMaxAttempts).Return(true, nil)
+		mockRepo.EXPECT().GetUserTOTP(gomock.Any(), mockUser.ID).Return(totp, nil)
+		mockRepo.EXPECT().MarkUserTOTPStepUsed(gomock.Any(), mockUser.ID, gomock.Any()).Return(true, nil)
+		mockRepo.EXPECT().ConsumeLoginChallenge(gomock.Any(), challenge.ID).Return(true, nil)
+		mockRepo.EXPECT().ClearLoginThrottle(gomock.Any(), "user:1").Return(nil)
+		mockRepo.EXPECT().GetUserAccess(gomock.Any(), gomock.Any()).Return(repository.UserAccess{}, nil)
+		mockRepo.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Return(nil)
+
+		ctx, rec := TestRequestEndpoint(twoFactorRequest(currentTOTPCode(t, secret)))
+		s := &Server{Repository: mockRepo, Config: cfg}
+
+		err := s.LoginTwoFactor(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("when error a wrong code counts against the account", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		totp, secret := MockUserTOTP(t, mockUser, true)
+		code, _ := tools.TOTPCode(secret, tools.TOTPStep(time.Now())-10)
+		challenge := MockLoginChallenge(mockUser)
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetLoginChallengeByTokenHash(gomock.Any(), gomock.Any()).Return(challenge, nil)
+		mockRepo.EXPECT().UseLoginChallengeAttempt(gomock.Any(), challenge.ID, cfg.OTPMaxAttempts).Return(true, nil)
+		mockRepo.EXPECT().GetUserTOTP(gomock.Any(), mockUser.ID).Return(totp, nil)
+		mockRepo.EXPECT().RecordLoginFailure(gomock.Any(), "user:1", 900*time.Second).Return(5, nil)
+		mockRepo.EXPECT().BlockLoginSubject(gomock.Any(), "user:1", gomock.Any()).Return(nil)
+
+		ctx, rec := TestRequestEndpoint(twoFactorRequest(code))
+		s := &Server{Repository: mockRepo, Config: cfg}
+
+		err := s.LoginTwoFactor(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
@@ -1,48 +1,177 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/SawitProRecruitment/UserService/config"
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/tools"
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
 
-func JWTMiddleware(cfg config.Config) echo.MiddlewareFunc {
+var (
+	errMissingToken                 = errors.New("missing token")
+	errMalformedAuthorizationHeader = errors.New("malformed authorization header")
+)
+
+// Principal types set under the "PrincipalType" context key. User tokens also
+// set "UserGUID", "FullName", "Roles" and "Permissions"; client tokens only
+// identify the client.
+const (
+	PrincipalTypeUser   = "user"
+	PrincipalTypeClient = "client"
+)
+
+const invalidTokenChallenge = `Bearer error="invalid_token"`
+
+func JWTMiddleware(cfg config.Config, keyRing *tools.KeyRing, revocations repository.TokenRevocationRepositoryInterface) echo.MiddlewareFunc {
+	verifier := NewTokenVerifier(cfg, keyRing, revocations)
+
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			authHeader := c.Request().Header.Get("Authorization")
-			if authHeader == "" {
-				return echo.NewHTTPError(http.StatusUnauthorized, "Missing JWT token")
+			tokenString, err := bearerToken(c)
+			if err != nil {
+				return authenticationError(c, err)
 			}
 
-			tokenString := strings.Split(authHeader, " ")[1]
-
-			key, err := jwt.ParseRSAPublicKeyFromPEM([]byte(cfg.RSAPublicKey))
+			claims, err := verifier.Verify(c.Request().Context(), tokenString)
 			if err != nil {
-				return echo.NewHTTPError(http.StatusUnauthorized, "Invalid JWT token")
+				return authenticationError(c, err)
 			}
 
-			token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-				if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
-					return nil, jwt.ErrSignatureInvalid
-				}
-				return key, nil
-			})
-
-			if err != nil {
-				return echo.NewHTTPError(http.StatusForbidden, "Invalid JWT token")
+			if claims.GUID == uuid.Nil {
+				c.Set("PrincipalType", PrincipalTypeClient)
+			} else {
+				c.Set("PrincipalType", PrincipalTypeUser)
+				c.Set("UserGUID", claims.GUID.String())
+				c.Set("FullName", claims.FullName)
+				c.Set("Roles", claims.Roles)
+				c.Set("Permissions", claims.Permissions)
 			}
+			c.Set("TokenID", claims.ID)
+			c.Set("TokenExpiresAt", claims.ExpiresAt.Time.UTC())
+			c.Set("Scope", claims.Scope)
+			c.Set("ClientID", claims.ClientID)
+			return next(c)
+		}
+	}
+}
+
+// bearerToken reads the token from the Authorization header.
+func bearerToken(c echo.Context) (string, error) {
+	return parseBearerToken(c.Request().Header.Get(echo.HeaderAuthorization))
+}
+
+func parseBearerToken(authHeader string) (string, error) {
+	if authHeader == "" {
+		return "", errMissingToken
+	}
+
+	headerParts := strings.SplitN(authHeader, " ", 2)
+	if len(headerParts) != 2 {
+		return "", errMalformedAuthorizationHeader
+	}
+	return headerParts[1], nil
+}
+
+// authenticationError turns a bearerToken or TokenVerifier error into the
+// response JWTMiddleware rejects the request with.
+func authenticationError(c echo.Context, err error) *echo.HTTPError {
+	code, message, challenge := authenticationFailure(err)
+	if challenge != "" {
+		c.Response().Header().Set(echo.HeaderWWWAuthenticate, challenge)
+	}
+	return echo.NewHTTPError(code, message)
+}
+
+// authenticationFailure maps a bearerToken or TokenVerifier error to the
+// status, message and WWW-Authenticate challenge to reject the request with,
+// independent of the transport it came in on.
+func authenticationFailure(err error) (code int, message string, challenge string) {
+	switch err {
+	case errMissingToken:
+		return http.StatusUnauthorized, "Missing JWT token", "Bearer"
+	case errMalformedAuthorizationHeader:
+		return http.StatusUnauthorized, "Invalid JWT token", invalidTokenChallenge
+	case ErrInvalidToken:
+		return http.StatusForbidden, "Invalid JWT token", ""
+	case ErrInvalidTokenClaims:
+		return http.StatusUnauthorized, "Invalid JWT claims", invalidTokenChallenge
+	case ErrTokenRevoked:
+		return http.StatusUnauthorized, "JWT token has been revoked", invalidTokenChallenge
+	default:
+		return http.StatusInternalServerError, err.Error(), ""
+	}
+}
 
-			claims, ok := token.Claims.(jwt.MapClaims)
-			if !ok || !token.Valid {
-				return echo.NewHTTPError(http.StatusUnauthorized, "Invalid JWT claims")
+// RequireUserPrincipal rejects client tokens on endpoints that act on the
+// signed in user. It must run after JWTMiddleware.
+func RequireUserPrincipal(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if c.Get("PrincipalType") != PrincipalTypeUser {
+			return echo.NewHTTPError(http.StatusForbidden, "This endpoint requires a user token")
+		}
+		return next(c)
+	}
+}
+
+// RequirePermission rejects tokens that were not granted every one of the
+// permissions. It must run after JWTMiddleware.
+func RequirePermission(permissions ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			granted, _ := c.Get("Permissions").([]string)
+			for _, permission := range permissions {
+				if !slices.Contains(granted, permission) {
+					return echo.NewHTTPError(http.StatusForbidden, "Missing permission "+permission)
+				}
 			}
+			return next(c)
+		}
+	}
+}
 
-			c.Set("UserGUID", claims["user_guid"])
-			c.Set("FullName", claims["full_name"])
+// RequireScope rejects tokens whose scope lacks any of the scopes. It must run
+// after JWTMiddleware.
+func RequireScope(scopes ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			granted, _ := c.Get("Scope").(string)
+			for _, scope := range scopes {
+				if !hasScope(granted, scope) {
+					return echo.NewHTTPError(http.StatusForbidden, "Missing scope "+scope)
+				}
+			}
 			return next(c)
 		}
 	}
 }
+
+// RequirePermissionOrScope lets users through with the permission and machine
+// clients with the scope of the same name. Scopes a user delegated to an
+// OAuth2 client never count. It must run after JWTMiddleware.
+func RequirePermissionOrScope(name string) echo.MiddlewareFunc {
+	requirePermission := RequirePermission(name)
+	requireScope := RequireScope(name)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		forUser := requirePermission(next)
+		forClient := requireScope(next)
+		return func(c echo.Context) error {
+			if c.Get("PrincipalType") == PrincipalTypeClient {
+				return forClient(c)
+			}
+			return forUser(c)
+		}
+	}
+}
+
+// invalidTokenError rejects the request with the RFC 6750 challenge so bearer
+// token clients, OpenID Connect libraries included, know to get a new token.
+func invalidTokenError(c echo.Context, message string) *echo.HTTPError {
+	c.Response().Header().Set(echo.HeaderWWWAuthenticate, invalidTokenChallenge)
+	return echo.NewHTTPError(http.StatusUnauthorized, message)
+}
@@ -0,0 +1,299 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/SawitProRecruitment/UserService/config"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/tools"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJWTMiddleware(t *testing.T) {
+	mockConfig := config.Config{
+		RSAPrivateKey: tools.MockRSAPrivateKey(),
+		RSAPublicKey:  tools.MockRSAPublicKey(),
+	}
+	keyRing, err := tools.NewKeyRing(tools.KeyRingOptions{PublicKey: tools.MockRSAPublicKey()})
+	assert.NoError(t, err)
+	mockUser := MockUser()
+	tokenParams := tools.GenerateJWTTokenParams{
+		FullName: mockUser.FullName,
+		GUID:     mockUser.GUID,
+	}
+	token, _, err := tools.GenerateJWTToken(tokenParams, 1, tools.MockRSAPrivateKey())
+	assert.NoError(t, err)
+
+	okHandler := func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}
+
+	t.Run("when token is valid and not revoked", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRevocations := repository.NewMockTokenRevocationRepositoryInterface(ctrl)
+		mockRevocations.EXPECT().IsAccessTokenRevoked(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ interface{}, input repository.IsAccessTokenRevokedInput) (bool, error) {
+				assert.NotEmpty(t, input.JTI)
+				assert.Equal(t, mockUser.GUID, input.UserGUID)
+				return false, nil
+			},
+		)
+
+		ctx, _ := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodGet, url: "/users", token: token})
+
+		err := JWTMiddleware(mockConfig, keyRing, mockRevocations)(okHandler)(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, mockUser.GUID.String(), ctx.Get("UserGUID"))
+		assert.Equal(t, mockUser.FullName, ctx.Get("FullName"))
+		assert.NotEmpty(t, ctx.Get("TokenID"))
+		assert.Equal(t, PrincipalTypeUser, ctx.Get("PrincipalType"))
+	})
+
+	t.Run("when token carries roles and permissions", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		params := tokenParams
+		params.Roles = []string{"support"}
+		params.Permissions = []string{"users:read"}
+		roleToken, _, err := tools.GenerateJWTToken(params, 1, tools.MockRSAPrivateKey())
+		assert.NoError(t, err)
+
+		mockRevocations := repository.NewMockTokenRevocationRepositoryInterface(ctrl)
+		mockRevocations.EXPECT().IsAccessTokenRevoked(gomock.Any(), gomock.Any()).Return(false, nil)
+
+		ctx, _ := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodGet, url: "/users", token: roleToken})
+
+		err = JWTMiddleware(mockConfig, keyRing, mockRevocations)(okHandler)(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"support"}, ctx.Get("Roles"))
+		assert.Equal(t, []string{"users:read"}, ctx.Get("Permissions"))
+	})
+
+	t.Run("when token was issued to a machine client", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		clientToken, _, err := tools.GenerateClientJWTToken(tools.GenerateClientJWTTokenParams{ClientID: "billing-job", Scope: "users:read"}, 1, tools.MockRSAPrivateKey())
+		assert.NoError(t, err)
+
+		mockRevocations := repository.NewMockTokenRevocationRepositoryInterface(ctrl)
+		mockRevocations.EXPECT().IsAccessTokenRevoked(gomock.Any(), gomock.Any()).Return(false, nil)
+
+		ctx, _ := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodGet, url: "/users", token: clientToken})
+
+		err = JWTMiddleware(mockConfig, keyRing, mockRevocations)(okHandler)(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, PrincipalTypeClient, ctx.Get("PrincipalType"))
+		assert.Equal(t, "billing-job", ctx.Get("ClientID"))
+		assert.Equal(t, "users:read", ctx.Get("Scope"))
+		assert.Nil(t, ctx.Get("UserGUID"))
+	})
+
+	t.Run("when token is missing", func(t *testing.T) {
+		e := echo.New()
+		ctx, _ := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodGet, url: "/users"})
+
+		err := JWTMiddleware(mockConfig, keyRing, nil)(okHandler)(ctx)
+		assert.Equal(t, http.StatusUnauthorized, err.(*echo.HTTPError).Code)
+	})
+
+	t.Run("when authorization header is malformed", func(t *testing.T) {
+		e := echo.New()
+		ctx, _ := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodGet, url: "/users"})
+		ctx.Request().Header.Set(echo.HeaderAuthorization, token)
+
+		err := JWTMiddleware(mockConfig, keyRing, nil)(okHandler)(ctx)
+		assert.Equal(t, http.StatusUnauthorized, err.(*echo.HTTPError).Code)
+	})
+
+	t.Run("when token is invalid", func(t *testing.T) {
+		e := echo.New()
+		ctx, _ := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodGet, url: "/users", token: "invalid"})
+
+		err := JWTMiddleware(mockConfig, keyRing, nil)(okHandler)(ctx)
+		assert.Equal(t, http.StatusForbidden, err.(*echo.HTTPError).Code)
+	})
+
+	t.Run("when token is revoked", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRevocations := repository.NewMockTokenRevocationRepositoryInterface(ctrl)
+		mockRevocations.EXPECT().IsAccessTokenRevoked(gomock.Any(), gomock.Any()).Return(true, nil)
+
+		ctx, _ := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodGet, url: "/users", token: token})
+
+		err := JWTMiddleware(mockConfig, keyRing, mockRevocations)(okHandler)(ctx)
+		assert.Equal(t, http.StatusUnauthorized, err.(*echo.HTTPError).Code)
+	})
+
+	t.Run("when error to check revocation", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRevocations := repository.NewMockTokenRevocationRepositoryInterface(ctrl)
+		mockRevocations.EXPECT().IsAccessTokenRevoked(gomock.Any(), gomock.Any()).Return(false, fmt.Errorf("error db"))
+
+		ctx, _ := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodGet, url: "/users", token: token})
+
+		err := JWTMiddleware(mockConfig, keyRing, mockRevocations)(okHandler)(ctx)
+		assert.Equal(t, http.StatusInternalServerError, err.(*echo.HTTPError).Code)
+	})
+
+	t.Run("when token was issued for another issuer or audience", func(t *testing.T) {
+		e := echo.New()
+		strictConfig := mockConfig
+		strictConfig.JWTIssuer = "https://auth.sawitpro.com"
+		strictConfig.JWTAudience = "user-service"
+
+		params := tokenParams
+		params.Issuer = "https://auth.sawitpro.com"
+		params.Audience = []string{"another-service"}
+		foreignToken, _, err := tools.GenerateJWTToken(params, 1, tools.MockRSAPrivateKey())
+		assert.NoError(t, err)
+
+		ctx, _ := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodGet, url: "/users", token: foreignToken})
+
+		err = JWTMiddleware(strictConfig, keyRing, nil)(okHandler)(ctx)
+		assert.Equal(t, http.StatusForbidden, err.(*echo.HTTPError).Code)
+	})
+}
+
+func TestRequireUserPrincipal(t *testing.T) {
+	okHandler := func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}
+
+	t.Run("when principal is a user", func(t *testing.T) {
+		e := echo.New()
+		ctx, _ := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodGet, url: "/users"})
+		ctx.Set("PrincipalType", PrincipalTypeUser)
+
+		err := RequireUserPrincipal(okHandler)(ctx)
+		assert.NoError(t, err)
+	})
+
+	t.Run("when principal is a machine client", func(t *testing.T) {
+		e := echo.New()
+		ctx, _ := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodGet, url: "/users"})
+		ctx.Set("PrincipalType", PrincipalTypeClient)
+
+		err := RequireUserPrincipal(okHandler)(ctx)
+		assert.Equal(t, http.StatusForbidden, err.(*echo.HTTPError).Code)
+	})
+}
+
+func TestRequirePermission(t *testing.T) {
+	okHandler := func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}
+
+	t.Run("when every permission was granted", func(t *testing.T) {
+		e := echo.New()
+		ctx, _ := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodGet, url: "/users"})
+		ctx.Set("Permissions", []string{"users:read", "users:write"})
+
+		err := RequirePermission("users:read", "users:write")(okHandler)(ctx)
+		assert.NoError(t, err)
+	})
+
+	t.Run("when a permission is missing", func(t *testing.T) {
+		e := echo.New()
+		ctx, _ := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodGet, url: "/users"})
+		ctx.Set("Permissions", []string{"users:read"})
+
+		err := RequirePermission("users:read", "users:write")(okHandler)(ctx)
+		assert.Equal(t, http.StatusForbidden, err.(*echo.HTTPError).Code)
+	})
+
+	t.Run("when the token carries no permissions", func(t *testing.T) {
+		e := echo.New()
+		ctx, _ := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodGet, url: "/users"})
+		ctx.Set("PrincipalType", PrincipalTypeClient)
+
+		err := RequirePermission("users:read")(okHandler)(ctx)
+		assert.Equal(t, http.StatusForbidden, err.(*echo.HTTPError).Code)
+	})
+}
+
+func TestRequireScope(t *testing.T) {
+	okHandler := func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}
+
+	t.Run("when every scope was granted", func(t *testing.T) {
+		e := echo.New()
+		ctx, _ := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodGet, url: "/admin/users"})
+		ctx.Set("Scope", "users:read users:write")
+
+		err := RequireScope("users:read", "users:write")(okHandler)(ctx)
+		assert.NoError(t, err)
+	})
+
+	t.Run("when a scope is missing", func(t *testing.T) {
+		e := echo.New()
+		ctx, _ := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodGet, url: "/admin/users"})
+		ctx.Set("Scope", "users:read")
+
+		err := RequireScope("users:write")(okHandler)(ctx)
+		assert.Equal(t, http.StatusForbidden, err.(*echo.HTTPError).Code)
+	})
+}
+
+func TestRequirePermissionOrScope(t *testing.T) {
+	okHandler := func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}
+
+	t.Run("when a user was granted the permission", func(t *testing.T) {
+		e := echo.New()
+		ctx, _ := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodGet, url: "/admin/users"})
+		ctx.Set("PrincipalType", PrincipalTypeUser)
+		ctx.Set("Permissions", []string{"users:read"})
+
+		err := RequirePermissionOrScope("users:read")(okHandler)(ctx)
+		assert.NoError(t, err)
+	})
+
+	t.Run("when a user only delegated the scope to a client", func(t *testing.T) {
+		e := echo.New()
+		ctx, _ := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodGet, url: "/admin/users"})
+		ctx.Set("PrincipalType", PrincipalTypeUser)
+		ctx.Set("Scope", "users:read")
+
+		err := RequirePermissionOrScope("users:read")(okHandler)(ctx)
+		assert.Equal(t, http.StatusForbidden, err.(*echo.HTTPError).Code)
+	})
+
+	t.Run("when a machine client was granted the scope", func(t *testing.T) {
+		e := echo.New()
+		ctx, _ := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodGet, url: "/admin/users"})
+		ctx.Set("PrincipalType", PrincipalTypeClient)
+		ctx.Set("Scope", "users:read")
+
+		err := RequirePermissionOrScope("users:read")(okHandler)(ctx)
+		assert.NoError(t, err)
+	})
+
+	t.Run("when a machine client lacks the scope", func(t *testing.T) {
+		e := echo.New()
+		ctx, _ := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodGet, url: "/admin/users"})
+		ctx.Set("PrincipalType", PrincipalTypeClient)
+		ctx.Set("Scope", "users:read")
+
+		err := RequirePermissionOrScope("users:write")(okHandler)(ctx)
+		assert.Equal(t, http.StatusForbidden, err.(*echo.HTTPError).Code)
+	})
+}
@@ -0,0 +1,555 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/tools"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	oauthScopeOpenID  = "openid"
+	oauthScopeProfile = "profile"
+	oauthScopePhone   = "phone"
+)
+
+const (
+	oauthGrantAuthorizationCode = "authorization_code"
+	oauthGrantRefreshToken      = "refresh_token"
+	oauthGrantClientCredentials = "client_credentials"
+)
+
+// Error codes from RFC 6749 sections 4.1.2.1 and 5.2.
+const (
+	oauthErrInvalidRequest          = "invalid_request"
+	oauthErrInvalidClient           = "invalid_client"
+	oauthErrInvalidGrant            = "invalid_grant"
+	oauthErrInvalidScope            = "invalid_scope"
+	oauthErrUnauthorizedClient      = "unauthorized_client"
+	oauthErrAccessDenied            = "access_denied"
+	oauthErrUnsupportedGrantType    = "unsupported_grant_type"
+	oauthErrUnsupportedResponseType = "unsupported_response_type"
+	oauthErrServerError             = "server_error"
+)
+
+type oauthError struct {
+	Code        int
+	ErrorCode   string
+	Description string
+}
+
+func (e *oauthError) Error() string {
+	return e.Description
+}
+
+// authorizationRequest holds the parameters of an authorization request, which
+// arrive as a query string on GET and as a JSON body on POST.
+type authorizationRequest struct {
+	ResponseType        string
+	ClientID            string
+	RedirectURI         string
+	Scope               string
+	State               string
+	Nonce               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+}
+
+func (s *Server) AuthorizeOAuthClient(ctx echo.Context, params generated.AuthorizeOAuthClientParams) error {
+	req := authorizationRequest{
+		ResponseType:        stringValue(params.ResponseType),
+		ClientID:            stringValue(params.ClientId),
+		RedirectURI:         stringValue(params.RedirectUri),
+		Scope:               stringValue(params.Scope),
+		State:               stringValue(params.State),
+		Nonce:               stringValue(params.Nonce),
+		CodeChallenge:       stringValue(params.CodeChallenge),
+		CodeChallengeMethod: stringValue(params.CodeChallengeMethod),
+	}
+	return s.authorize(ctx, req, nil)
+}
+
+func (s *Server) ConsentOAuthClient(ctx echo.Context) error {
+	var input generated.ConsentOAuthClientJSONRequestBody
+	err := ctx.Bind(&input)
+	if err != nil {
+		return oauthErrorJSON(ctx, &oauthError{Code: http.StatusBadRequest, ErrorCode: oauthErrInvalidRequest, Description: err.Error()})
+	}
+
+	req := authorizationRequest{
+		ResponseType:        stringValue(input.ResponseType),
+		ClientID:            input.ClientId,
+		RedirectURI:         stringValue(input.RedirectUri),
+		Scope:               stringValue(input.Scope),
+		State:               stringValue(input.State),
+		Nonce:               stringValue(input.Nonce),
+		CodeChallenge:       stringValue(input.CodeChallenge),
+		CodeChallengeMethod: stringValue(input.CodeChallengeMethod),
+	}
+	return s.authorize(ctx, req, &input.Approved)
+}
+
+// authorize validates an authorization request for the signed in user. With a
+// nil approval it only issues a code when the user already consented to the
+// requested scopes, otherwise it records the user's decision first.
+func (s *Server) authorize(ctx echo.Context, req authorizationRequest, approved *bool) error {
+	rCtx := ctx.Request().Context()
+
+	if clientID, _ := ctx.Get("ClientID").(string); clientID != "" {
+		return ctx.JSON(http.StatusForbidden, generated.ErrorResponse{Message: "tokens issued to OAuth2 clients cannot authorize other clients"})
+	}
+
+	client, err := s.Repository.GetOAuthClientByClientID(rCtx, req.ClientID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return oauthErrorJSON(ctx, &oauthError{Code: http.StatusBadRequest, ErrorCode: oauthErrInvalidClient, Description: "client is not found"})
+		}
+		return oauthErrorJSON(ctx, err)
+	}
+
+	// Until the redirect URI is known to belong to the client, errors must
+	// not be sent to it.
+	redirectURI, ok := resolveRedirectURI(client, req.RedirectURI)
+	if !ok {
+		return oauthErrorJSON(ctx, &oauthError{Code: http.StatusBadRequest, ErrorCode: oauthErrInvalidRequest, Description: "redirect_uri is not registered for the client"})
+	}
+
+	resp := generated.OAuthAuthorizeResponse{
+		ClientId:   client.ClientID,
+		ClientName: client.Name,
+		Scopes:     []string{},
+	}
+	redirectError := func(errorCode string, description string) error {
+		redirectTo := buildRedirectURI(redirectURI, url.Values{"error": {errorCode}, "error_description": {description}}, req.State)
+		resp.RedirectTo = &redirectTo
+		return ctx.JSON(http.StatusOK, resp)
+	}
+
+	if !containsString(client.GrantTypes, oauthGrantAuthorizationCode) {
+		return redirectError(oauthErrUnauthorizedClient, "client is not allowed to use the authorization code grant")
+	}
+	if req.ResponseType != "code" {
+		return redirectError(oauthErrUnsupportedResponseType, "response_type must be code")
+	}
+	if req.CodeChallengeMethod != tools.PKCEMethodS256 {
+		return redirectError(oauthErrInvalidRequest, "code_challenge_method must be S256")
+	}
+	if !tools.IsValidPKCEValue(req.CodeChallenge) {
+		return redirectError(oauthErrInvalidRequest, "code_challenge is missing or malformed")
+	}
+
+	scopes, unknownScope := resolveScopes(client, req.Scope)
+	if unknownScope != "" {
+		return redirectError(oauthErrInvalidScope, "scope "+unknownScope+" is not allowed for the client")
+	}
+	resp.Scopes = scopes
+
+	guid := uuid.MustParse(ctx.Get("UserGUID").(string))
+	user, err := s.Repository.GetUserByGUID(rCtx, guid)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return invalidTokenError(ctx, "user is not found")
+		}
+		return oauthErrorJSON(ctx, err)
+	}
+
+	if approved == nil {
+		consent, err := s.Repository.GetOAuthConsent(rCtx, user.ID, client.ID)
+		if err != nil && err != sql.ErrNoRows {
+			return oauthErrorJSON(ctx, err)
+		}
+		if err == sql.ErrNoRows || !containsAllStrings(consent.Scopes, scopes) {
+			resp.ConsentRequired = true
+			return ctx.JSON(http.StatusOK, resp)
+		}
+	} else {
+		if !*approved {
+			return redirectError(oauthErrAccessDenied, "the user denied the request")
+		}
+
+		err = s.Repository.UpsertOAuthConsent(rCtx, &repository.OAuthConsent{
+			UserID:        user.ID,
+			OAuthClientID: client.ID,
+			Scopes:        scopes,
+		})
+		if err != nil {
+			return oauthErrorJSON(ctx, err)
+		}
+	}
+
+	code, codeHash, err := tools.GenerateOpaqueToken()
+	if err != nil {
+		return oauthErrorJSON(ctx, err)
+	}
+
+	err = s.Repository.CreateOAuthAuthorizationCode(rCtx, &repository.OAuthAuthorizationCode{
+		CodeHash:            codeHash,
+		OAuthClientID:       client.ID,
+		UserID:              user.ID,
+		RedirectURI:         redirectURI,
+		RedirectURISent:     req.RedirectURI != "",
+		Scope:               strings.Join(scopes, " "),
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+		Nonce:               req.Nonce,
+		ExpiresAt:           time.Now().UTC().Add(time.Duration(s.Config.OAuthAuthorizationCodeLifetimeInSeconds) * time.Second),
+	})
+	if err != nil {
+		return oauthErrorJSON(ctx, err)
+	}
+
+	redirectTo := buildRedirectURI(redirectURI, url.Values{"code": {code}}, req.State)
+	resp.RedirectTo = &redirectTo
+	return ctx.JSON(http.StatusOK, resp)
+}
+
+func (s *Server) IssueOAuthToken(ctx echo.Context) error {
+	rCtx := ctx.Request().Context()
+	ctx.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+
+	client, err := s.authenticateOAuthClient(ctx)
+	if err != nil {
+		return oauthErrorJSON(ctx, err)
+	}
+
+	grantType := ctx.FormValue("grant_type")
+	switch grantType {
+	case oauthGrantAuthorizationCode, oauthGrantRefreshToken, oauthGrantClientCredentials:
+	case "":
+		return oauthErrorJSON(ctx, &oauthError{Code: http.StatusBadRequest, ErrorCode: oauthErrInvalidRequest, Description: "grant_type is required"})
+	default:
+		return oauthErrorJSON(ctx, &oauthError{Code: http.StatusBadRequest, ErrorCode: oauthErrUnsupportedGrantType, Description: "grant_type is not supported"})
+	}
+
+	if !containsString(client.GrantTypes, grantType) {
+		return oauthErrorJSON(ctx, &oauthError{Code: http.StatusBadRequest, ErrorCode: oauthErrUnauthorizedClient, Description: "client is not allowed to use the " + grantType + " grant"})
+	}
+
+	if grantType == oauthGrantClientCredentials {
+		return s.issueClientCredentialsToken(ctx, client)
+	}
+
+	var owner *tokenOwner
+	var familyID uuid.UUID
+	if grantType == oauthGrantAuthorizationCode {
+		owner, familyID, err = s.exchangeAuthorizationCode(ctx, client)
+	} else {
+		owner, familyID, err = s.exchangeOAuthRefreshToken(rCtx, client, ctx.FormValue("refresh_token"))
+	}
+	if err != nil {
+		return oauthErrorJSON(ctx, err)
+	}
+
+	pair, err := s.issueTokenPair(rCtx, *owner, familyID)
+	if err != nil {
+		return oauthErrorJSON(ctx, err)
+	}
+
+	resp := generated.OAuthTokenResponse{
+		AccessToken:  pair.Token,
+		TokenType:    "Bearer",
+		ExpiresIn:    s.accessTokenLifetimeInSeconds(),
+		RefreshToken: &pair.RefreshToken,
+		Scope:        owner.Scope,
+	}
+	if hasScope(owner.Scope, oauthScopeOpenID) {
+		idToken, _, err := tools.GenerateIDToken(tools.GenerateIDTokenParams{
+			GUID:     owner.GUID,
+			ClientID: client.ClientID,
+			Nonce:    owner.Nonce,
+			Issuer:   tokenIssuer(s.Config),
+		}, s.Config.JWTTokenLifetimeInHours, s.Config.RSAPrivateKey)
+		if err != nil {
+			return oauthErrorJSON(ctx, err)
+		}
+		resp.IdToken = &idToken
+	}
+
+	return ctx.JSON(http.StatusOK, resp)
+}
+
+// issueClientCredentialsToken issues an access token to a confidential client
+// acting on its own behalf. No refresh token is issued since the client can
+// always authenticate again.
+func (s *Server) issueClientCredentialsToken(ctx echo.Context, client *repository.OAuthClient) error {
+	if client.ClientSecretHash == nil {
+		return oauthErrorJSON(ctx, &oauthError{Code: http.StatusBadRequest, ErrorCode: oauthErrUnauthorizedClient, Description: "public clients cannot use the client_credentials grant"})
+	}
+
+	scopes, unknownScope := resolveScopes(client, ctx.FormValue("scope"))
+	if unknownScope != "" {
+		return oauthErrorJSON(ctx, &oauthError{Code: http.StatusBadRequest, ErrorCode: oauthErrInvalidScope, Description: "scope " + unknownScope + " is not allowed for the client"})
+	}
+
+	params := tools.GenerateClientJWTTokenParams{
+		ClientID: client.ClientID,
+		Scope:    strings.Join(scopes, " "),
+		Issuer:   tokenIssuer(s.Config),
+	}
+	if s.Config.JWTAudience != "" {
+		params.Audience = []string{s.Config.JWTAudience}
+	}
+
+	token, _, err := tools.GenerateClientJWTToken(params, s.Config.JWTTokenLifetimeInHours, s.Config.RSAPrivateKey)
+	if err != nil {
+		return oauthErrorJSON(ctx, err)
+	}
+
+	return ctx.JSON(http.StatusOK, generated.OAuthTokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   s.accessTokenLifetimeInSeconds(),
+		Scope:       params.Scope,
+	})
+}
+
+func (s *Server) accessTokenLifetimeInSeconds() int {
+	return s.Config.JWTTokenLifetimeInHours * int(time.Hour/time.Second)
+}
+
+// authenticateOAuthClient identifies the client from HTTP Basic credentials or
+// the client_id and client_secret form fields. Public clients only send their
+// client_id and are held to PKCE instead.
+func (s *Server) authenticateOAuthClient(ctx echo.Context) (*repository.OAuthClient, error) {
+	clientID, clientSecret, basicAuth := ctx.Request().BasicAuth()
+	if basicAuth {
+		// RFC 6749 section 2.3.1 form-encodes the credentials before Basic encoding.
+		clientID, _ = url.QueryUnescape(clientID)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientID = ctx.FormValue("client_id")
+		clientSecret = ctx.FormValue("client_secret")
+	}
+
+	invalidClient := &oauthError{Code: http.StatusUnauthorized, ErrorCode: oauthErrInvalidClient, Description: "client authentication failed"}
+	if clientID == "" {
+		return nil, invalidClient
+	}
+
+	client, err := s.Repository.GetOAuthClientByClientID(ctx.Request().Context(), clientID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, invalidClient
+		}
+		return nil, err
+	}
+
+	if client.ClientSecretHash != nil && !tools.IsValidPassword(*client.ClientSecretHash, clientSecret) {
+		return nil, invalidClient
+	}
+	return client, nil
+}
+
+func (s *Server) exchangeAuthorizationCode(ctx echo.Context, client *repository.OAuthClient) (*tokenOwner, uuid.UUID, error) {
+	rCtx := ctx.Request().Context()
+
+	code := ctx.FormValue("code")
+	if code == "" {
+		return nil, uuid.Nil, &oauthError{Code: http.StatusBadRequest, ErrorCode: oauthErrInvalidRequest, Description: "code is required"}
+	}
+
+	invalidGrant := func(description string) error {
+		return &oauthError{Code: http.StatusBadRequest, ErrorCode: oauthErrInvalidGrant, Description: description}
+	}
+
+	stored, err := s.Repository.GetOAuthAuthorizationCodeByHash(rCtx, tools.HashOpaqueToken(code))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, uuid.Nil, invalidGrant("authorization code is invalid")
+		}
+		return nil, uuid.Nil, err
+	}
+
+	if stored.OAuthClientID != client.ID {
+		return nil, uuid.Nil, invalidGrant("authorization code was issued to another client")
+	}
+
+	if stored.UsedAt != nil {
+		// A replayed code may have been intercepted, so the tokens it was
+		// exchanged for are revoked as RFC 6749 section 4.1.2 advises.
+		if stored.RefreshTokenFamilyID != nil {
+			err = s.Repository.RevokeRefreshTokenFamily(rCtx, *stored.RefreshTokenFamilyID)
+			if err != nil {
+				return nil, uuid.Nil, err
+			}
+		}
+		return nil, uuid.Nil, invalidGrant("authorization code has already been used")
+	}
+
+	if time.Now().UTC().After(stored.ExpiresAt) {
+		return nil, uuid.Nil, invalidGrant("authorization code has expired")
+	}
+
+	// RFC 6749 section 4.1.3 requires redirect_uri only when the
+	// authorization request included it, but a redirect_uri that is sent has
+	// to match the one the code went to.
+	redirectURI := ctx.FormValue("redirect_uri")
+	if redirectURI == "" && stored.RedirectURISent {
+		return nil, uuid.Nil, &oauthError{Code: http.StatusBadRequest, ErrorCode: oauthErrInvalidRequest, Description: "redirect_uri is required"}
+	}
+	if redirectURI != "" && redirectURI != stored.RedirectURI {
+		return nil, uuid.Nil, invalidGrant("redirect_uri does not match the authorization request")
+	}
+
+	if !tools.VerifyPKCES256(ctx.FormValue("code_verifier"), stored.CodeChallenge) {
+		return nil, uuid.Nil, invalidGrant("code_verifier does not match the code challenge")
+	}
+
+	familyID := uuid.New()
+	marked, err := s.Repository.MarkOAuthAuthorizationCodeUsed(rCtx, stored.ID, familyID)
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+	if !marked {
+		return nil, uuid.Nil, invalidGrant("authorization code has already been used")
+	}
+
+	return &tokenOwner{
+		ID:          stored.UserID,
+		GUID:        stored.UserGUID,
+		FullName:    stored.FullName,
+		PhoneNumber: stored.PhoneNumber,
+		OAuthClient: client,
+		Scope:       stored.Scope,
+		Nonce:       stored.Nonce,
+	}, familyID, nil
+}
+
+func (s *Server) exchangeOAuthRefreshToken(ctx context.Context, client *repository.OAuthClient, refreshToken string) (*tokenOwner, uuid.UUID, error) {
+	if refreshToken == "" {
+		return nil, uuid.Nil, &oauthError{Code: http.StatusBadRequest, ErrorCode: oauthErrInvalidRequest, Description: "refresh_token is required"}
+	}
+
+	invalidGrant := &oauthError{Code: http.StatusBadRequest, ErrorCode: oauthErrInvalidGrant, Description: "refresh token is invalid"}
+
+	stored, err := s.Repository.GetRefreshTokenByHash(ctx, tools.HashOpaqueToken(refreshToken))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, uuid.Nil, invalidGrant
+		}
+		return nil, uuid.Nil, err
+	}
+
+	if stored.OAuthClientID == nil || *stored.OAuthClientID != client.ID {
+		return nil, uuid.Nil, invalidGrant
+	}
+
+	err = s.consumeRefreshToken(ctx, stored)
+	if err != nil {
+		if err == errRefreshTokenRevoked || err == errRefreshTokenExpired || err == errRefreshTokenReused {
+			return nil, uuid.Nil, &oauthError{Code: http.StatusBadRequest, ErrorCode: oauthErrInvalidGrant, Description: err.Error()}
+		}
+		return nil, uuid.Nil, err
+	}
+
+	return &tokenOwner{
+		ID:          stored.UserID,
+		GUID:        stored.UserGUID,
+		FullName:    stored.FullName,
+		PhoneNumber: stored.PhoneNumber,
+		OAuthClient: client,
+		Scope:       stored.Scope,
+	}, stored.FamilyID, nil
+}
+
+// oauthErrorJSON writes err in the RFC 6749 error format. Errors that are not
+// an *oauthError are reported as server_error.
+func oauthErrorJSON(ctx echo.Context, err error) error {
+	oauthErr, ok := err.(*oauthError)
+	if !ok {
+		oauthErr = &oauthError{Code: http.StatusInternalServerError, ErrorCode: oauthErrServerError, Description: err.Error()}
+	}
+
+	if oauthErr.Code == http.StatusUnauthorized {
+		ctx.Response().Header().Set(echo.HeaderWWWAuthenticate, "Basic")
+	}
+
+	resp := generated.OAuthErrorResponse{Error: oauthErr.ErrorCode}
+	if oauthErr.Description != "" {
+		resp.ErrorDescription = &oauthErr.Description
+	}
+	return ctx.JSON(oauthErr.Code, resp)
+}
+
+// resolveRedirectURI requires an exact match against the registered redirect
+// URIs and falls back to the only one registered when none is given.
+func resolveRedirectURI(client *repository.OAuthClient, redirectURI string) (string, bool) {
+	if redirectURI == "" {
+		if len(client.RedirectURIs) == 1 {
+			return client.RedirectURIs[0], true
+		}
+		return "", false
+	}
+	return redirectURI, containsString(client.RedirectURIs, redirectURI)
+}
+
+func buildRedirectURI(redirectURI string, params url.Values, state string) string {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		return redirectURI
+	}
+
+	query := u.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+	if state != "" {
+		query.Set("state", state)
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// resolveScopes parses the requested scopes, defaulting to every scope the
+// client is registered with. It returns the first scope the client may not
+// request, if any.
+func resolveScopes(client *repository.OAuthClient, requested string) (scopes []string, unknownScope string) {
+	scopes = strings.Fields(requested)
+	if len(scopes) == 0 {
+		return client.Scopes, ""
+	}
+	for _, scope := range scopes {
+		if !containsString(client.Scopes, scope) {
+			return nil, scope
+		}
+	}
+	return scopes, ""
+}
+
+// hasScope reports whether the space separated scope contains want.
+func hasScope(scope string, want string) bool {
+	return containsString(strings.Fields(scope), want)
+}
+
+func containsString(values []string, want string) bool {
+	for _, value := range values {
+		if value == want {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAllStrings(values []string, wants []string) bool {
+	for _, want := range wants {
+		if !containsString(values, want) {
+			return false
+		}
+	}
+	return true
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
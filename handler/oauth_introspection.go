@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/tools"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	tokenTypeHintAccessToken  = "access_token"
+	tokenTypeHintRefreshToken = "refresh_token"
+)
+
+// tokenLookup answers for one kind of token. It reports handled as false when
+// the token is not of its kind so the next lookup can be tried.
+type tokenLookup func(ctx context.Context, token string) (handled bool, err error)
+
+func (s *Server) tokenVerifier() *TokenVerifier {
+	return NewTokenVerifier(s.Config, s.KeyRing, s.TokenRevocations)
+}
+
+func (s *Server) IntrospectOAuthToken(ctx echo.Context) error {
+	ctx.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+
+	client, err := s.authenticateOAuthClient(ctx)
+	if err != nil {
+		return oauthErrorJSON(ctx, err)
+	}
+
+	// Public clients cannot prove who they are, so they may not learn anything
+	// about tokens.
+	if client.ClientSecretHash == nil {
+		return oauthErrorJSON(ctx, &oauthError{Code: http.StatusUnauthorized, ErrorCode: oauthErrInvalidClient, Description: "client authentication failed"})
+	}
+
+	token := ctx.FormValue("token")
+	if token == "" {
+		return oauthErrorJSON(ctx, &oauthError{Code: http.StatusBadRequest, ErrorCode: oauthErrInvalidRequest, Description: "token is required"})
+	}
+
+	resp := generated.OAuthIntrospectionResponse{Active: false}
+	introspectAccessToken := func(rCtx context.Context, token string) (bool, error) {
+		claims, err := s.tokenVerifier().Verify(rCtx, token)
+		if err != nil {
+			switch err {
+			case ErrInvalidToken:
+				return false, nil
+			case ErrInvalidTokenClaims, ErrTokenRevoked:
+				return true, nil
+			}
+			return false, err
+		}
+
+		subject := claims.Subject
+		if subject == "" {
+			// Tokens issued before the sub claim was added only carry user_guid.
+			subject = claims.GUID.String()
+		}
+		exp := claims.ExpiresAt.Unix()
+		iat := claims.IssuedAt.Unix()
+		tokenType := "Bearer"
+
+		resp = generated.OAuthIntrospectionResponse{
+			Active:    true,
+			Sub:       &subject,
+			Exp:       &exp,
+			Iat:       &iat,
+			TokenType: &tokenType,
+		}
+		if claims.Scope != "" {
+			resp.Scope = &claims.Scope
+		}
+		if claims.ClientID != "" {
+			resp.ClientId = &claims.ClientID
+		}
+		if claims.RegisteredClaims.Issuer != "" {
+			resp.Iss = &claims.RegisteredClaims.Issuer
+		}
+		if len(claims.RegisteredClaims.Audience) > 0 {
+			aud := []string(claims.RegisteredClaims.Audience)
+			resp.Aud = &aud
+		}
+		if claims.ID != "" {
+			resp.Jti = &claims.ID
+		}
+		return true, nil
+	}
+	introspectRefreshToken := func(rCtx context.Context, token string) (bool, error) {
+		stored, err := s.Repository.GetRefreshTokenByHash(rCtx, tools.HashOpaqueToken(token))
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return false, nil
+			}
+			return false, err
+		}
+
+		// Refresh tokens are only described to the client holding them.
+		if !isActiveClientRefreshToken(stored, client) {
+			return true, nil
+		}
+
+		subject := stored.UserGUID.String()
+		exp := stored.ExpiresAt.Unix()
+		iat := stored.CreatedAt.Unix()
+		tokenType := tokenTypeHintRefreshToken
+		resp = generated.OAuthIntrospectionResponse{
+			Active:    true,
+			Sub:       &subject,
+			Exp:       &exp,
+			Iat:       &iat,
+			TokenType: &tokenType,
+			ClientId:  &client.ClientID,
+			Scope:     &stored.Scope,
+		}
+		return true, nil
+	}
+
+	err = lookupToken(ctx, token, introspectAccessToken, introspectRefreshToken)
+	if err != nil {
+		return oauthErrorJSON(ctx, err)
+	}
+	return ctx.JSON(http.StatusOK, resp)
+}
+
+func (s *Server) RevokeOAuthToken(ctx echo.Context) error {
+	client, err := s.authenticateOAuthClient(ctx)
+	if err != nil {
+		return oauthErrorJSON(ctx, err)
+	}
+
+	token := ctx.FormValue("token")
+	if token == "" {
+		return oauthErrorJSON(ctx, &oauthError{Code: http.StatusBadRequest, ErrorCode: oauthErrInvalidRequest, Description: "token is required"})
+	}
+
+	// Tokens issued to other clients are left alone without telling the
+	// caller, as are unknown tokens, so revocation cannot be used to probe.
+	revokeAccessToken := func(rCtx context.Context, token string) (bool, error) {
+		claims, err := s.tokenVerifier().Verify(rCtx, token)
+		if err != nil {
+			switch err {
+			case ErrInvalidToken:
+				return false, nil
+			case ErrInvalidTokenClaims, ErrTokenRevoked:
+				return true, nil
+			}
+			return false, err
+		}
+
+		if claims.ClientID != client.ClientID || claims.ID == "" {
+			return true, nil
+		}
+		return true, s.TokenRevocations.RevokeAccessToken(rCtx, repository.RevokedAccessToken{
+			JTI:       claims.ID,
+			UserGUID:  claims.GUID,
+			ExpiresAt: claims.ExpiresAt.Time.UTC(),
+		})
+	}
+	revokeRefreshToken := func(rCtx context.Context, token string) (bool, error) {
+		stored, err := s.Repository.GetRefreshTokenByHash(rCtx, tools.HashOpaqueToken(token))
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return false, nil
+			}
+			return false, err
+		}
+
+		if stored.OAuthClientID == nil || *stored.OAuthClientID != client.ID {
+			return true, nil
+		}
+		return true, s.Repository.RevokeRefreshTokenFamily(rCtx, stored.FamilyID)
+	}
+
+	err = lookupToken(ctx, token, revokeAccessToken, revokeRefreshToken)
+	if err != nil {
+		return oauthErrorJSON(ctx, err)
+	}
+	return ctx.NoContent(http.StatusOK)
+}
+
+// lookupToken tries the access token lookup first, or the refresh token one
+// when the client hinted so, until one of them recognises the token.
+func lookupToken(ctx echo.Context, token string, accessToken tokenLookup, refreshToken tokenLookup) error {
+	lookups := []tokenLookup{accessToken, refreshToken}
+	if ctx.FormValue("token_type_hint") == tokenTypeHintRefreshToken {
+		lookups = []tokenLookup{refreshToken, accessToken}
+	}
+
+	for _, lookup := range lookups {
+		handled, err := lookup(ctx.Request().Context(), token)
+		if err != nil {
+			return err
+		}
+		if handled {
+			return nil
+		}
+	}
+	return nil
+}
+
+func isActiveClientRefreshToken(stored *repository.RefreshToken, client *repository.OAuthClient) bool {
+	return stored.OAuthClientID != nil &&
+		*stored.OAuthClientID == client.ID &&
+		stored.UsedAt == nil &&
+		stored.RevokedAt == nil &&
+		time.Now().UTC().Before(stored.ExpiresAt)
+}
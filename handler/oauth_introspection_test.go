@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/SawitProRecruitment/UserService/config"
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/tools"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func tokenVerifyingServer(t *testing.T, mockRepo repository.RepositoryInterface, revocations repository.TokenRevocationRepositoryInterface) *Server {
+	keyRing, err := tools.NewKeyRing(tools.KeyRingOptions{PrivateKey: tools.MockRSAPrivateKey()})
+	assert.NoError(t, err)
+
+	return &Server{
+		Repository:       mockRepo,
+		TokenRevocations: revocations,
+		KeyRing:          keyRing,
+		Config:           config.Config{RSAPrivateKey: tools.MockRSAPrivateKey()},
+	}
+}
+
+func TestIntrospectOAuthToken(t *testing.T) {
+	mockUser := MockUser()
+	userToken, _, err := tools.GenerateJWTToken(tools.GenerateJWTTokenParams{FullName: mockUser.FullName, GUID: mockUser.GUID}, 1, tools.MockRSAPrivateKey())
+	assert.NoError(t, err)
+
+	t.Run("when success introspect an active access token", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetOAuthClientByClientID(gomock.Any(), "billing-job").Return(MockMachineClient(t), nil)
+		mockRevocations := repository.NewMockTokenRevocationRepositoryInterface(ctrl)
+		mockRevocations.EXPECT().IsAccessTokenRevoked(gomock.Any(), gomock.Any()).Return(false, nil)
+
+		ctx, rec := tokenRequest(e, url.Values{"token": {userToken}})
+		ctx.Request().SetBasicAuth("billing-job", "client-secret")
+
+		err := tokenVerifyingServer(t, mockRepo, mockRevocations).IntrospectOAuthToken(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var resp generated.OAuthIntrospectionResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.True(t, resp.Active)
+		assert.Equal(t, mockUser.GUID.String(), *resp.Sub)
+		assert.Equal(t, "Bearer", *resp.TokenType)
+		assert.NotEmpty(t, *resp.Jti)
+	})
+
+	t.Run("when success introspect a revoked access token", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetOAuthClientByClientID(gomock.Any(), "billing-job").Return(MockMachineClient(t), nil)
+		mockRevocations := repository.NewMockTokenRevocationRepositoryInterface(ctrl)
+		mockRevocations.EXPECT().IsAccessTokenRevoked(gomock.Any(), gomock.Any()).Return(true, nil)
+
+		ctx, rec := tokenRequest(e, url.Values{"token": {userToken}})
+		ctx.Request().SetBasicAuth("billing-job", "client-secret")
+
+		err := tokenVerifyingServer(t, mockRepo, mockRevocations).IntrospectOAuthToken(ctx)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"active":false}`, rec.Body.String())
+	})
+
+	t.Run("when success introspect a refresh token of the client", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := MockMachineClient(t)
+		stored := MockRefreshToken()
+		stored.OAuthClientID = &client.ID
+		stored.Scope = "openid"
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetOAuthClientByClientID(gomock.Any(), "billing-job").Return(client, nil)
+		mockRepo.EXPECT().GetRefreshTokenByHash(gomock.Any(), tools.HashOpaqueToken("refresh-token")).Return(stored, nil)
+
+		ctx, rec := tokenRequest(e, url.Values{"token": {"refresh-token"}, "token_type_hint": {"refresh_token"}})
+		ctx.Request().SetBasicAuth("billing-job", "client-secret")
+
+		err := tokenVerifyingServer(t, mockRepo, nil).IntrospectOAuthToken(ctx)
+		assert.NoError(t, err)
+
+		var resp generated.OAuthIntrospectionResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.True(t, resp.Active)
+		assert.Equal(t, "refresh_token", *resp.TokenType)
+		assert.Equal(t, "openid", *resp.Scope)
+		assert.Equal(t, stored.UserGUID.String(), *resp.Sub)
+	})
+}
+
+func TestIntrospectOAuthToken_Error(t *testing.T) {
+	t.Run("when error due to client is public", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetOAuthClientByClientID(gomock.Any(), "third-party-app").Return(MockOAuthClient(), nil)
+
+		ctx, rec := tokenRequest(e, url.Values{"token": {"token"}, "client_id": {"third-party-app"}})
+
+		err := tokenVerifyingServer(t, mockRepo, nil).IntrospectOAuthToken(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), oauthErrInvalidClient)
+	})
+
+	t.Run("when error due to token is missing", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetOAuthClientByClientID(gomock.Any(), "billing-job").Return(MockMachineClient(t), nil)
+
+		ctx, rec := tokenRequest(e, url.Values{})
+		ctx.Request().SetBasicAuth("billing-job", "client-secret")
+
+		err := tokenVerifyingServer(t, mockRepo, nil).IntrospectOAuthToken(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestRevokeOAuthToken(t *testing.T) {
+	t.Run("when success revoke an access token issued to the client", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		clientToken, _, err := tools.GenerateClientJWTToken(tools.GenerateClientJWTTokenParams{ClientID: "billing-job"}, 1, tools.MockRSAPrivateKey())
+		assert.NoError(t, err)
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetOAuthClientByClientID(gomock.Any(), "billing-job").Return(MockMachineClient(t), nil)
+		mockRevocations := repository.NewMockTokenRevocationRepositoryInterface(ctrl)
+		mockRevocations.EXPECT().IsAccessTokenRevoked(gomock.Any(), gomock.Any()).Return(false, nil)
+		mockRevocations.EXPECT().RevokeAccessToken(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ interface{}, token repository.RevokedAccessToken) error {
+				assert.NotEmpty(t, token.JTI)
+				return nil
+			},
+		)
+
+		ctx, rec := tokenRequest(e, url.Values{"token": {clientToken}})
+		ctx.Request().SetBasicAuth("billing-job", "client-secret")
+
+		err = tokenVerifyingServer(t, mockRepo, mockRevocations).RevokeOAuthToken(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("when success ignore an access token issued to someone else", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		userToken, _, err := tools.GenerateJWTToken(tools.GenerateJWTTokenParams{FullName: mockUser.FullName, GUID: mockUser.GUID}, 1, tools.MockRSAPrivateKey())
+		assert.NoError(t, err)
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetOAuthClientByClientID(gomock.Any(), "billing-job").Return(MockMachineClient(t), nil)
+		mockRevocations := repository.NewMockTokenRevocationRepositoryInterface(ctrl)
+		mockRevocations.EXPECT().IsAccessTokenRevoked(gomock.Any(), gomock.Any()).Return(false, nil)
+
+		ctx, rec := tokenRequest(e, url.Values{"token": {userToken}})
+		ctx.Request().SetBasicAuth("billing-job", "client-secret")
+
+		err = tokenVerifyingServer(t, mockRepo, mockRevocations).RevokeOAuthToken(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("when success revoke a refresh token of a public client", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := MockOAuthClient()
+		stored := MockRefreshToken()
+		stored.OAuthClientID = &client.ID
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetOAuthClientByClientID(gomock.Any(), "third-party-app").Return(client, nil)
+		mockRepo.EXPECT().GetRefreshTokenByHash(gomock.Any(), tools.HashOpaqueToken("refresh-token")).Return(stored, nil)
+		mockRepo.EXPECT().RevokeRefreshTokenFamily(gomock.Any(), stored.FamilyID).Return(nil)
+
+		ctx, rec := tokenRequest(e, url.Values{"token": {"refresh-token"}, "token_type_hint": {"refresh_token"}, "client_id": {"third-party-app"}})
+
+		err := tokenVerifyingServer(t, mockRepo, nil).RevokeOAuthToken(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
@@ -0,0 +1,829 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/config"
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/tools"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	mockCodeVerifier = "M25iVXpKU3puUjFaYWg3T1NDTDQtcW1ROUY5YXlwalNoc0hhakxifmZHag"
+	mockRedirectURI  = "https://app.example.com/callback"
+)
+
+func MockOAuthClient() *repository.OAuthClient {
+	return &repository.OAuthClient{
+		ID:           1,
+		ClientID:     "third-party-app",
+		Name:         "Third Party App",
+		RedirectURIs: []string{mockRedirectURI},
+		Scopes:       []string{"openid", "profile", "phone"},
+		GrantTypes:   []string{"authorization_code", "refresh_token"},
+	}
+}
+
+func MockOAuthAuthorizationCode() *repository.OAuthAuthorizationCode {
+	return &repository.OAuthAuthorizationCode{
+		ID:                  1,
+		CodeHash:            tools.HashOpaqueToken("auth-code"),
+		OAuthClientID:       1,
+		UserID:              1,
+		RedirectURI:         mockRedirectURI,
+		RedirectURISent:     true,
+		Scope:               "openid profile",
+		CodeChallenge:       tools.PKCEChallengeS256(mockCodeVerifier),
+		CodeChallengeMethod: tools.PKCEMethodS256,
+		ExpiresAt:           time.Now().UTC().Add(time.Minute),
+		CreatedAt:           time.Now().UTC(),
+		UserGUID:            uuid.New(),
+		FullName:            "SawitPro Mania",
+		PhoneNumber:         "+628123456789",
+	}
+}
+
+func mockAuthorizeParams() generated.AuthorizeOAuthClientParams {
+	responseType := "code"
+	clientID := "third-party-app"
+	scope := "openid profile"
+	state := "xyz"
+	nonce := "n-0S6_WzA2Mj"
+	codeChallenge := tools.PKCEChallengeS256(mockCodeVerifier)
+	codeChallengeMethod := tools.PKCEMethodS256
+	return generated.AuthorizeOAuthClientParams{
+		ResponseType:        &responseType,
+		ClientId:            &clientID,
+		Scope:               &scope,
+		State:               &state,
+		Nonce:               &nonce,
+		CodeChallenge:       &codeChallenge,
+		CodeChallengeMethod: &codeChallengeMethod,
+	}
+}
+
+func decodeAuthorizeResponse(t *testing.T, body []byte) (generated.OAuthAuthorizeResponse, url.Values) {
+	var resp generated.OAuthAuthorizeResponse
+	assert.NoError(t, json.Unmarshal(body, &resp))
+	if resp.RedirectTo == nil {
+		return resp, nil
+	}
+
+	redirectTo, err := url.Parse(*resp.RedirectTo)
+	assert.NoError(t, err)
+	return resp, redirectTo.Query()
+}
+
+func TestAuthorizeOAuthClient(t *testing.T) {
+	mockConfig := config.Config{OAuthAuthorizationCodeLifetimeInSeconds: 60}
+
+	t.Run("when success issue code for a client the user consented to", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetOAuthClientByClientID(gomock.Any(), "third-party-app").Return(MockOAuthClient(), nil)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), mockUser.GUID).Return(mockUser, nil)
+		mockRepo.EXPECT().GetOAuthConsent(gomock.Any(), mockUser.ID, 1).Return(&repository.OAuthConsent{Scopes: []string{"openid", "profile", "phone"}}, nil)
+		mockRepo.EXPECT().CreateOAuthAuthorizationCode(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ interface{}, code *repository.OAuthAuthorizationCode) error {
+				assert.Equal(t, mockUser.ID, code.UserID)
+				assert.Equal(t, mockRedirectURI, code.RedirectURI)
+				assert.False(t, code.RedirectURISent)
+				assert.Equal(t, "openid profile", code.Scope)
+				assert.Equal(t, tools.PKCEChallengeS256(mockCodeVerifier), code.CodeChallenge)
+				assert.Equal(t, "n-0S6_WzA2Mj", code.Nonce)
+				return nil
+			},
+		)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodGet, url: "/oauth/authorize"})
+		ctx.Set("UserGUID", mockUser.GUID.String())
+
+		s := &Server{Repository: mockRepo, Config: mockConfig}
+
+		err := s.AuthorizeOAuthClient(ctx, mockAuthorizeParams())
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		resp, query := decodeAuthorizeResponse(t, rec.Body.Bytes())
+		assert.False(t, resp.ConsentRequired)
+		assert.True(t, strings.HasPrefix(*resp.RedirectTo, mockRedirectURI+"?"))
+		assert.NotEmpty(t, query.Get("code"))
+		assert.Equal(t, "xyz", query.Get("state"))
+	})
+
+	t.Run("when success ask for consent", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetOAuthClientByClientID(gomock.Any(), "third-party-app").Return(MockOAuthClient(), nil)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), mockUser.GUID).Return(mockUser, nil)
+		mockRepo.EXPECT().GetOAuthConsent(gomock.Any(), mockUser.ID, 1).Return(nil, sql.ErrNoRows)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodGet, url: "/oauth/authorize"})
+		ctx.Set("UserGUID", mockUser.GUID.String())
+
+		s := &Server{Repository: mockRepo, Config: mockConfig}
+
+		err := s.AuthorizeOAuthClient(ctx, mockAuthorizeParams())
+		assert.NoError(t, err)
+
+		resp, _ := decodeAuthorizeResponse(t, rec.Body.Bytes())
+		assert.True(t, resp.ConsentRequired)
+		assert.Nil(t, resp.RedirectTo)
+		assert.Equal(t, "Third Party App", resp.ClientName)
+		assert.Equal(t, []string{"openid", "profile"}, resp.Scopes)
+	})
+}
+
+func TestAuthorizeOAuthClient_Error(t *testing.T) {
+	mockUser := MockUser()
+
+	t.Run("when error due to client is not found", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetOAuthClientByClientID(gomock.Any(), "third-party-app").Return(nil, sql.ErrNoRows)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodGet, url: "/oauth/authorize"})
+		ctx.Set("UserGUID", mockUser.GUID.String())
+
+		s := &Server{Repository: mockRepo}
+
+		err := s.AuthorizeOAuthClient(ctx, mockAuthorizeParams())
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), oauthErrInvalidClient)
+	})
+
+	t.Run("when error due to redirect uri is not registered", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetOAuthClientByClientID(gomock.Any(), "third-party-app").Return(MockOAuthClient(), nil)
+
+		params := mockAuthorizeParams()
+		redirectURI := "https://evil.example.com/callback"
+		params.RedirectUri = &redirectURI
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodGet, url: "/oauth/authorize"})
+		ctx.Set("UserGUID", mockUser.GUID.String())
+
+		s := &Server{Repository: mockRepo}
+
+		err := s.AuthorizeOAuthClient(ctx, params)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.NotContains(t, rec.Body.String(), "redirect_to")
+	})
+
+	t.Run("when error due to pkce is not S256", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetOAuthClientByClientID(gomock.Any(), "third-party-app").Return(MockOAuthClient(), nil)
+
+		params := mockAuthorizeParams()
+		plain := "plain"
+		params.CodeChallengeMethod = &plain
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodGet, url: "/oauth/authorize"})
+		ctx.Set("UserGUID", mockUser.GUID.String())
+
+		s := &Server{Repository: mockRepo}
+
+		err := s.AuthorizeOAuthClient(ctx, params)
+		assert.NoError(t, err)
+
+		_, query := decodeAuthorizeResponse(t, rec.Body.Bytes())
+		assert.Equal(t, oauthErrInvalidRequest, query.Get("error"))
+		assert.Equal(t, "xyz", query.Get("state"))
+	})
+
+	t.Run("when error due to scope is not allowed for the client", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetOAuthClientByClientID(gomock.Any(), "third-party-app").Return(MockOAuthClient(), nil)
+
+		params := mockAuthorizeParams()
+		scope := "openid admin"
+		params.Scope = &scope
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodGet, url: "/oauth/authorize"})
+		ctx.Set("UserGUID", mockUser.GUID.String())
+
+		s := &Server{Repository: mockRepo}
+
+		err := s.AuthorizeOAuthClient(ctx, params)
+		assert.NoError(t, err)
+
+		_, query := decodeAuthorizeResponse(t, rec.Body.Bytes())
+		assert.Equal(t, oauthErrInvalidScope, query.Get("error"))
+	})
+
+	t.Run("when error due to caller is an oauth client", func(t *testing.T) {
+		e := echo.New()
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodGet, url: "/oauth/authorize"})
+		ctx.Set("UserGUID", mockUser.GUID.String())
+		ctx.Set("ClientID", "another-app")
+
+		s := &Server{}
+
+		err := s.AuthorizeOAuthClient(ctx, mockAuthorizeParams())
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
+
+func TestConsentOAuthClient(t *testing.T) {
+	mockConfig := config.Config{OAuthAuthorizationCodeLifetimeInSeconds: 60}
+	body := func(approved bool) []byte {
+		payload, _ := json.Marshal(map[string]interface{}{
+			"response_type":         "code",
+			"client_id":             "third-party-app",
+			"scope":                 "openid phone",
+			"state":                 "xyz",
+			"code_challenge":        tools.PKCEChallengeS256(mockCodeVerifier),
+			"code_challenge_method": tools.PKCEMethodS256,
+			"approved":              approved,
+		})
+		return payload
+	}
+
+	t.Run("when success approve the client", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetOAuthClientByClientID(gomock.Any(), "third-party-app").Return(MockOAuthClient(), nil)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), mockUser.GUID).Return(mockUser, nil)
+		mockRepo.EXPECT().UpsertOAuthConsent(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ interface{}, consent *repository.OAuthConsent) error {
+				assert.Equal(t, mockUser.ID, consent.UserID)
+				assert.Equal(t, []string{"openid", "phone"}, consent.Scopes)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().CreateOAuthAuthorizationCode(gomock.Any(), gomock.Any()).Return(nil)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodPost, url: "/oauth/authorize", body: body(true)})
+		ctx.Set("UserGUID", mockUser.GUID.String())
+
+		s := &Server{Repository: mockRepo, Config: mockConfig}
+
+		err := s.ConsentOAuthClient(ctx)
+		assert.NoError(t, err)
+
+		_, query := decodeAuthorizeResponse(t, rec.Body.Bytes())
+		assert.NotEmpty(t, query.Get("code"))
+	})
+
+	t.Run("when success deny the client", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetOAuthClientByClientID(gomock.Any(), "third-party-app").Return(MockOAuthClient(), nil)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), mockUser.GUID).Return(mockUser, nil)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodPost, url: "/oauth/authorize", body: body(false)})
+		ctx.Set("UserGUID", mockUser.GUID.String())
+
+		s := &Server{Repository: mockRepo, Config: mockConfig}
+
+		err := s.ConsentOAuthClient(ctx)
+		assert.NoError(t, err)
+
+		_, query := decodeAuthorizeResponse(t, rec.Body.Bytes())
+		assert.Equal(t, oauthErrAccessDenied, query.Get("error"))
+		assert.Empty(t, query.Get("code"))
+	})
+}
+
+func tokenRequest(e *echo.Echo, form url.Values) (echo.Context, *httptest.ResponseRecorder) {
+	ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+		e:          e,
+		httpMethod: http.MethodPost,
+		url:        "/oauth/token",
+		body:       []byte(form.Encode()),
+	})
+	ctx.Request().Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	return ctx, rec
+}
+
+func TestIssueOAuthToken(t *testing.T) {
+	mockConfig := config.Config{
+		RSAPrivateKey:               tools.MockRSAPrivateKey(),
+		JWTTokenLifetimeInHours:     1,
+		RefreshTokenLifetimeInHours: 720,
+	}
+
+	t.Run("when success exchange an authorization code", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		code := MockOAuthAuthorizationCode()
+		code.Nonce = "n-0S6_WzA2Mj"
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetOAuthClientByClientID(gomock.Any(), "third-party-app").Return(MockOAuthClient(), nil)
+		mockRepo.EXPECT().GetOAuthAuthorizationCodeByHash(gomock.Any(), tools.HashOpaqueToken("auth-code")).Return(code, nil)
+		mockRepo.EXPECT().MarkOAuthAuthorizationCodeUsed(gomock.Any(), code.ID, gomock.Any()).Return(true, nil)
+		mockRepo.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ interface{}, token *repository.RefreshToken) error {
+				assert.Equal(t, 1, *token.OAuthClientID)
+				assert.Equal(t, "openid profile", token.Scope)
+				return nil
+			},
+		)
+
+		ctx, rec := tokenRequest(e, url.Values{
+			"grant_type":    {oauthGrantAuthorizationCode},
+			"client_id":     {"third-party-app"},
+			"code":          {"auth-code"},
+			"redirect_uri":  {mockRedirectURI},
+			"code_verifier": {mockCodeVerifier},
+		})
+
+		s := &Server{Repository: mockRepo, Config: mockConfig}
+
+		err := s.IssueOAuthToken(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "no-store", rec.Header().Get(echo.HeaderCacheControl))
+
+		var resp generated.OAuthTokenResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, "Bearer", resp.TokenType)
+		assert.Equal(t, 3600, resp.ExpiresIn)
+		assert.Equal(t, "openid profile", resp.Scope)
+		assert.NotEmpty(t, resp.RefreshToken)
+
+		keyRing, err := tools.NewKeyRing(tools.KeyRingOptions{PrivateKey: tools.MockRSAPrivateKey()})
+		assert.NoError(t, err)
+		revocations := repository.NewMockTokenRevocationRepositoryInterface(ctrl)
+		revocations.EXPECT().IsAccessTokenRevoked(gomock.Any(), gomock.Any()).Return(false, nil)
+
+		verifyCtx, _ := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodGet, url: "/userinfo", token: resp.AccessToken})
+		err = JWTMiddleware(mockConfig, keyRing, revocations)(func(c echo.Context) error { return nil })(verifyCtx)
+		assert.NoError(t, err)
+		assert.Equal(t, code.UserGUID.String(), verifyCtx.Get("UserGUID"))
+		assert.Equal(t, "third-party-app", verifyCtx.Get("ClientID"))
+		assert.Equal(t, "openid profile", verifyCtx.Get("Scope"))
+
+		assert.NotNil(t, resp.IdToken)
+		idClaims := jwt.MapClaims{}
+		_, err = jwt.ParseWithClaims(*resp.IdToken, idClaims, func(token *jwt.Token) (interface{}, error) {
+			return jwt.ParseRSAPublicKeyFromPEM([]byte(tools.MockRSAPublicKey()))
+		}, jwt.WithAudience("third-party-app"))
+		assert.NoError(t, err)
+		assert.Equal(t, code.UserGUID.String(), idClaims["sub"])
+		assert.Equal(t, "n-0S6_WzA2Mj", idClaims["nonce"])
+	})
+
+	t.Run("when success no ID token without the openid scope", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		code := MockOAuthAuthorizationCode()
+		code.Scope = "profile"
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetOAuthClientByClientID(gomock.Any(), "third-party-app").Return(MockOAuthClient(), nil)
+		mockRepo.EXPECT().GetOAuthAuthorizationCodeByHash(gomock.Any(), gomock.Any()).Return(code, nil)
+		mockRepo.EXPECT().MarkOAuthAuthorizationCodeUsed(gomock.Any(), code.ID, gomock.Any()).Return(true, nil)
+		mockRepo.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Return(nil)
+
+		ctx, rec := tokenRequest(e, url.Values{
+			"grant_type":    {oauthGrantAuthorizationCode},
+			"client_id":     {"third-party-app"},
+			"code":          {"auth-code"},
+			"redirect_uri":  {mockRedirectURI},
+			"code_verifier": {mockCodeVerifier},
+		})
+
+		s := &Server{Repository: mockRepo, Config: mockConfig}
+
+		err := s.IssueOAuthToken(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var resp generated.OAuthTokenResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Nil(t, resp.IdToken)
+	})
+
+	t.Run("when success rotate a refresh token with client secret basic", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		secretHash, err := tools.HashPassword("client-secret")
+		assert.NoError(t, err)
+		client := MockOAuthClient()
+		client.ClientSecretHash = &secretHash
+
+		stored := MockRefreshToken()
+		stored.OAuthClientID = &client.ID
+		stored.Scope = "openid"
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetOAuthClientByClientID(gomock.Any(), "third-party-app").Return(client, nil)
+		mockRepo.EXPECT().GetRefreshTokenByHash(gomock.Any(), tools.HashOpaqueToken("refresh-token")).Return(stored, nil)
+		mockRepo.EXPECT().MarkRefreshTokenUsed(gomock.Any(), stored.ID).Return(true, nil)
+		mockRepo.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ interface{}, token *repository.RefreshToken) error {
+				assert.Equal(t, stored.FamilyID, token.FamilyID)
+				assert.Equal(t, "openid", token.Scope)
+				return nil
+			},
+		)
+
+		ctx, rec := tokenRequest(e, url.Values{
+			"grant_type":    {oauthGrantRefreshToken},
+			"refresh_token": {"refresh-token"},
+		})
+		ctx.Request().SetBasicAuth("third-party-app", "client-secret")
+
+		s := &Server{Repository: mockRepo, Config: mockConfig}
+
+		err = s.IssueOAuthToken(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
+
+func MockMachineClient(t *testing.T) *repository.OAuthClient {
+	secretHash, err := tools.HashPassword("client-secret")
+	assert.NoError(t, err)
+	return &repository.OAuthClient{
+		ID:               2,
+		ClientID:         "billing-job",
+		Name:             "Billing Job",
+		ClientSecretHash: &secretHash,
+		Scopes:           []string{"users:read", "users:write"},
+		GrantTypes:       []string{"client_credentials"},
+	}
+}
+
+func TestIssueOAuthToken_ClientCredentials(t *testing.T) {
+	mockConfig := config.Config{
+		RSAPrivateKey:           tools.MockRSAPrivateKey(),
+		JWTTokenLifetimeInHours: 1,
+	}
+
+	t.Run("when success issue a machine token", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetOAuthClientByClientID(gomock.Any(), "billing-job").Return(MockMachineClient(t), nil)
+
+		ctx, rec := tokenRequest(e, url.Values{
+			"grant_type":    {oauthGrantClientCredentials},
+			"client_id":     {"billing-job"},
+			"client_secret": {"client-secret"},
+			"scope":         {"users:read"},
+		})
+
+		s := &Server{Repository: mockRepo, Config: mockConfig}
+
+		err := s.IssueOAuthToken(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var resp generated.OAuthTokenResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, "users:read", resp.Scope)
+		assert.Nil(t, resp.RefreshToken)
+		assert.NotEmpty(t, resp.AccessToken)
+	})
+
+	t.Run("when error due to scope is not registered for the client", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetOAuthClientByClientID(gomock.Any(), "billing-job").Return(MockMachineClient(t), nil)
+
+		ctx, rec := tokenRequest(e, url.Values{
+			"grant_type": {oauthGrantClientCredentials},
+			"scope":      {"users:delete"},
+		})
+		ctx.Request().SetBasicAuth("billing-job", "client-secret")
+
+		s := &Server{Repository: mockRepo, Config: mockConfig}
+
+		err := s.IssueOAuthToken(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), oauthErrInvalidScope)
+	})
+
+	t.Run("when error due to client is not registered for the grant", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetOAuthClientByClientID(gomock.Any(), "third-party-app").Return(MockOAuthClient(), nil)
+
+		ctx, rec := tokenRequest(e, url.Values{
+			"grant_type": {oauthGrantClientCredentials},
+			"client_id":  {"third-party-app"},
+		})
+
+		s := &Server{Repository: mockRepo, Config: mockConfig}
+
+		err := s.IssueOAuthToken(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), oauthErrUnauthorizedClient)
+	})
+
+	t.Run("when error due to public client asks for the grant", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client := MockMachineClient(t)
+		client.ClientSecretHash = nil
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetOAuthClientByClientID(gomock.Any(), "billing-job").Return(client, nil)
+
+		ctx, rec := tokenRequest(e, url.Values{
+			"grant_type": {oauthGrantClientCredentials},
+			"client_id":  {"billing-job"},
+		})
+
+		s := &Server{Repository: mockRepo, Config: mockConfig}
+
+		err := s.IssueOAuthToken(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), oauthErrUnauthorizedClient)
+	})
+}
+
+func TestIssueOAuthToken_Error(t *testing.T) {
+	mockConfig := config.Config{
+		RSAPrivateKey:               tools.MockRSAPrivateKey(),
+		JWTTokenLifetimeInHours:     1,
+		RefreshTokenLifetimeInHours: 720,
+	}
+	codeForm := url.Values{
+		"grant_type":    {oauthGrantAuthorizationCode},
+		"client_id":     {"third-party-app"},
+		"code":          {"auth-code"},
+		"redirect_uri":  {mockRedirectURI},
+		"code_verifier": {mockCodeVerifier},
+	}
+
+	t.Run("when error due to client secret is wrong", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		secretHash, err := tools.HashPassword("client-secret")
+		assert.NoError(t, err)
+		client := MockOAuthClient()
+		client.ClientSecretHash = &secretHash
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetOAuthClientByClientID(gomock.Any(), "third-party-app").Return(client, nil)
+
+		ctx, rec := tokenRequest(e, codeForm)
+		ctx.Request().SetBasicAuth("third-party-app", "wrong-secret")
+
+		s := &Server{Repository: mockRepo, Config: mockConfig}
+
+		err = s.IssueOAuthToken(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), oauthErrInvalidClient)
+	})
+
+	t.Run("when error due to code verifier does not match", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetOAuthClientByClientID(gomock.Any(), "third-party-app").Return(MockOAuthClient(), nil)
+		mockRepo.EXPECT().GetOAuthAuthorizationCodeByHash(gomock.Any(), gomock.Any()).Return(MockOAuthAuthorizationCode(), nil)
+
+		form := url.Values{}
+		for key, values := range codeForm {
+			form[key] = values
+		}
+		form.Set("code_verifier", strings.Repeat("a", 43))
+		ctx, rec := tokenRequest(e, form)
+
+		s := &Server{Repository: mockRepo, Config: mockConfig}
+
+		err := s.IssueOAuthToken(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), oauthErrInvalidGrant)
+	})
+
+	t.Run("when error due to redirect uri is missing", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetOAuthClientByClientID(gomock.Any(), "third-party-app").Return(MockOAuthClient(), nil)
+		mockRepo.EXPECT().GetOAuthAuthorizationCodeByHash(gomock.Any(), gomock.Any()).Return(MockOAuthAuthorizationCode(), nil)
+
+		form := url.Values{}
+		for key, values := range codeForm {
+			form[key] = values
+		}
+		form.Del("redirect_uri")
+		ctx, rec := tokenRequest(e, form)
+
+		s := &Server{Repository: mockRepo, Config: mockConfig}
+
+		err := s.IssueOAuthToken(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), oauthErrInvalidRequest)
+	})
+
+	t.Run("when success redirect uri may be left out when the authorization request did", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		code := MockOAuthAuthorizationCode()
+		code.RedirectURISent = false
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetOAuthClientByClientID(gomock.Any(), "third-party-app").Return(MockOAuthClient(), nil)
+		mockRepo.EXPECT().GetOAuthAuthorizationCodeByHash(gomock.Any(), gomock.Any()).Return(code, nil)
+		mockRepo.EXPECT().MarkOAuthAuthorizationCodeUsed(gomock.Any(), code.ID, gomock.Any()).Return(true, nil)
+		mockRepo.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Return(nil)
+
+		form := url.Values{}
+		for key, values := range codeForm {
+			form[key] = values
+		}
+		form.Del("redirect_uri")
+		ctx, rec := tokenRequest(e, form)
+
+		s := &Server{Repository: mockRepo, Config: mockConfig}
+
+		err := s.IssueOAuthToken(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("when error due to redirect uri does not match", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetOAuthClientByClientID(gomock.Any(), "third-party-app").Return(MockOAuthClient(), nil)
+		mockRepo.EXPECT().GetOAuthAuthorizationCodeByHash(gomock.Any(), gomock.Any()).Return(MockOAuthAuthorizationCode(), nil)
+
+		form := url.Values{}
+		for key, values := range codeForm {
+			form[key] = values
+		}
+		form.Set("redirect_uri", "https://evil.example.com/callback")
+		ctx, rec := tokenRequest(e, form)
+
+		s := &Server{Repository: mockRepo, Config: mockConfig}
+
+		err := s.IssueOAuthToken(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), oauthErrInvalidGrant)
+	})
+
+	t.Run("when code is reused then the issued tokens are revoked", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		code := MockOAuthAuthorizationCode()
+		usedAt := time.Now().UTC()
+		familyID := uuid.New()
+		code.UsedAt = &usedAt
+		code.RefreshTokenFamilyID = &familyID
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetOAuthClientByClientID(gomock.Any(), "third-party-app").Return(MockOAuthClient(), nil)
+		mockRepo.EXPECT().GetOAuthAuthorizationCodeByHash(gomock.Any(), gomock.Any()).Return(code, nil)
+		mockRepo.EXPECT().RevokeRefreshTokenFamily(gomock.Any(), familyID).Return(nil)
+
+		ctx, rec := tokenRequest(e, codeForm)
+
+		s := &Server{Repository: mockRepo, Config: mockConfig}
+
+		err := s.IssueOAuthToken(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), oauthErrInvalidGrant)
+	})
+
+	t.Run("when error due to code was issued to another client", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		code := MockOAuthAuthorizationCode()
+		code.OAuthClientID = 2
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetOAuthClientByClientID(gomock.Any(), "third-party-app").Return(MockOAuthClient(), nil)
+		mockRepo.EXPECT().GetOAuthAuthorizationCodeByHash(gomock.Any(), gomock.Any()).Return(code, nil)
+
+		ctx, rec := tokenRequest(e, codeForm)
+
+		s := &Server{Repository: mockRepo, Config: mockConfig}
+
+		err := s.IssueOAuthToken(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("when error due to refresh token belongs to the first-party login", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetOAuthClientByClientID(gomock.Any(), "third-party-app").Return(MockOAuthClient(), nil)
+		mockRepo.EXPECT().GetRefreshTokenByHash(gomock.Any(), gomock.Any()).Return(MockRefreshToken(), nil)
+
+		ctx, rec := tokenRequest(e, url.Values{
+			"grant_type":    {oauthGrantRefreshToken},
+			"client_id":     {"third-party-app"},
+			"refresh_token": {"refresh-token"},
+		})
+
+		s := &Server{Repository: mockRepo, Config: mockConfig}
+
+		err := s.IssueOAuthToken(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), oauthErrInvalidGrant)
+	})
+
+	t.Run("when error due to grant type is not supported", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetOAuthClientByClientID(gomock.Any(), "third-party-app").Return(MockOAuthClient(), nil)
+
+		ctx, rec := tokenRequest(e, url.Values{
+			"grant_type": {"password"},
+			"client_id":  {"third-party-app"},
+		})
+
+		s := &Server{Repository: mockRepo, Config: mockConfig}
+
+		err := s.IssueOAuthToken(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), oauthErrUnsupportedGrantType)
+	})
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/tools"
+)
+
+var errInvalidOTP = errors.New("invalid or expired code")
+
+// sendOTP texts a new code for purpose to phoneNumber. A code sent to the same
+// number less than the resend interval ago is left to be used instead, and a
+// number that already got OTPMaxSendsPerHour codes gets no more until the hour
+// rolls over, so the endpoints cannot be used to flood a phone with messages.
+func (s *Server) sendOTP(ctx context.Context, userID int, phoneNumber string, purpose string, messageFormat string) error {
+	latest, err := s.Repository.GetLatestOTPCode(ctx, userID, purpose)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	now := time.Now().UTC()
+	if err == nil && latest.PhoneNumber == phoneNumber && now.Before(latest.CreatedAt.Add(time.Duration(s.Config.OTPResendIntervalInSeconds)*time.Second)) {
+		return nil
+	}
+
+	if s.Config.OTPMaxSendsPerHour > 0 {
+		sent, err := s.Repository.CountOTPCodesSentSince(ctx, phoneNumber, now.Add(-time.Hour))
+		if err != nil {
+			return err
+		}
+		if sent >= s.Config.OTPMaxSendsPerHour {
+			return nil
+		}
+	}
+
+	code, err := tools.GenerateOTP(tools.OTPLength)
+	if err != nil {
+		return err
+	}
+	codeHash, err := s.hashPassword(code)
+	if err != nil {
+		return err
+	}
+
+	err = s.Repository.CreateOTPCode(ctx, &repository.OTPCode{
+		UserID:      userID,
+		Purpose:     purpose,
+		PhoneNumber: phoneNumber,
+		CodeHash:    codeHash,
+		ExpiresAt:   now.Add(time.Duration(s.Config.OTPLifetimeInSeconds) * time.Second),
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.SMSSender.Send(ctx, phoneNumber, fmt.Sprintf(messageFormat, code))
+}
+
+// checkOTP matches code against the latest code sent to phoneNumber for
+// purpose. Every guess spends one of the code's attempts before it is
+// compared, so once they are gone the code is dead even if the right one is
+// entered. It returns errInvalidOTP for any kind of mismatch.
+func (s *Server) checkOTP(ctx context.Context, userID int, purpose string, phoneNumber string, code string) (*repository.OTPCode, error) {
+	otp, err := s.Repository.GetLatestOTPCode(ctx, userID, purpose)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errInvalidOTP
+		}
+		return nil, err
+	}
+
+	if otp.PhoneNumber != phoneNumber || time.Now().UTC().After(otp.ExpiresAt) {
+		return nil, errInvalidOTP
+	}
+
+	used, err := s.Repository.UseOTPCodeAttempt(ctx, otp.ID, s.Config.OTPMaxAttempts)
+	if err != nil {
+		return nil, err
+	}
+	if !used || !tools.IsValidPassword(otp.CodeHash, code) {
+		return nil, errInvalidOTP
+	}
+	return otp, nil
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/tools"
+	"github.com/labstack/echo/v4"
+)
+
+const loginCodeMessageFormat = "Your login code is %s. Do not share it with anyone."
+
+// RequestLoginCode texts a one-time login code to the phone number, for users
+// who would rather not type a password. Like the password reset request it
+// answers the same way for every number.
+func (s *Server) RequestLoginCode(ctx echo.Context) error {
+	rCtx := ctx.Request().Context()
+	var input generated.RequestLoginCodeJSONRequestBody
+	var errData *tools.Err
+
+	err := ctx.Bind(&input)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	err = tools.ValidateRequestPayload(input)
+	if err != nil && errors.As(err, &errData) {
+		return ctx.JSON(errData.Code, generated.ErrorWithExtraResponse{Message: errData.Message, Extra: &errData.Extra})
+	}
+
+	accepted := generated.DefaultUpdateResponse{Message: "a login code has been sent if the phone number is registered"}
+
+	user, err := s.Repository.GetUserLoginByPhoneNumber(rCtx, input.PhoneNumber)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ctx.JSON(http.StatusAccepted, accepted)
+		}
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	err = s.sendOTP(rCtx, user.ID, input.PhoneNumber, repository.OTPPurposeLogin, loginCodeMessageFormat)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusAccepted, accepted)
+}
+
+// LoginWithCode logs the user in with a code from RequestLoginCode. Entering
+// the code proves the user holds the phone, so it also verifies the number and
+// lifts a lock left by failed password logins.
+func (s *Server) LoginWithCode(ctx echo.Context) error {
+	rCtx := ctx.Request().Context()
+	var input generated.LoginWithCodeJSONRequestBody
+	var errData *tools.Err
+
+	err := ctx.Bind(&input)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	err = tools.ValidateRequestPayload(input)
+	if err != nil && errors.As(err, &errData) {
+		return ctx.JSON(errData.Code, generated.ErrorWithExtraResponse{Message: errData.Message, Extra: &errData.Extra})
+	}
+
+	user, err := s.Repository.GetUserLoginByPhoneNumber(rCtx, input.PhoneNumber)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: errInvalidOTP.Error()})
+		}
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	otp, err := s.checkOTP(rCtx, user.ID, repository.OTPPurposeLogin, input.PhoneNumber, input.Code)
+	if err != nil {
+		if err == errInvalidOTP {
+			return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: err.Error()})
+		}
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	consumed, err := s.Repository.ConsumeOTPCode(rCtx, otp.ID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+	if !consumed {
+		return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: errInvalidOTP.Error()})
+	}
+
+	if user.PhoneVerifiedAt == nil {
+		err = s.Repository.MarkUserPhoneVerified(rCtx, user.ID)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+		}
+	}
+
+	return s.finishLogin(ctx, user, input.PhoneNumber)
+}
@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/sms"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+var loginCodePattern = regexp.MustCompile(`[0-9]{6}`)
+
+func TestLoginWithCode(t *testing.T) {
+	t.Run("when success log in with the code that was texted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		sender := sms.NewMemorySender()
+		cfg := MockTwoFactorConfig()
+		cfg.OTPMaxSendsPerHour = 5
+
+		var sent *repository.OTPCode
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber).Return(MockLoginUserOutput(mockUser), nil).Times(2)
+		mockRepo.EXPECT().GetLatestOTPCode(gomock.Any(), mockUser.ID, repository.OTPPurposeLogin).Return(nil, sql.ErrNoRows)
+		mockRepo.EXPECT().CountOTPCodesSentSince(gomock.Any(), mockUser.PhoneNumber, gomock.Any()).Return(1, nil)
+		mockRepo.EXPECT().CreateOTPCode(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ interface{}, code *repository.OTPCode) error {
+				code.ID = 9
+				code.CreatedAt = time.Now().UTC()
+				sent = code
+				return nil
+			},
+		)
+
+		s := &Server{Repository: mockRepo, SMSSender: sender, Config: cfg}
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          echo.New(),
+			httpMethod: http.MethodPost,
+			url:        "/login/otp",
+			body:       []byte(`{"phone_number": "+62345678901"}`),
+		})
+		err := s.RequestLoginCode(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+
+		message, ok := sender.LastMessageTo(mockUser.PhoneNumber)
+		assert.True(t, ok)
+		code := loginCodePattern.FindString(message.Message)
+
+		mockRepo.EXPECT().GetLatestOTPCode(gomock.Any(), mockUser.ID, repository.OTPPurposeLogin).Return(sent, nil)
+		mockRepo.EXPECT().UseOTPCodeAttempt(gomock.Any(), sent.ID, MockOTPConfig().OTPMaxAttempts).Return(true, nil)
+		mockRepo.EXPECT().ConsumeOTPCode(gomock.Any(), 9).Return(true, nil)
+		mockRepo.EXPECT().MarkUserPhoneVerified(gomock.Any(), mockUser.ID).Return(nil)
+		mockRepo.EXPECT().GetUserAccess(gomock.Any(), gomock.Any()).Return(repository.UserAccess{}, nil)
+		mockRepo.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Return(nil)
+
+		ctx, rec = TestRequestEndpoint(testRequestEndpointParam{
+			e:          echo.New(),
+			httpMethod: http.MethodPost,
+			url:        "/login/otp/verify",
+			body:       []byte(`{"phone_number": "+62345678901", "code": "` + code + `"}`),
+		})
+		err = s.LoginWithCode(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var resp generated.SuccessLoginUserResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.NotEmpty(t, resp.Token)
+	})
+
+	t.Run("when success ask for a second factor", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		output := MockLoginUserOutput(mockUser)
+		verifiedAt := time.Now().UTC()
+		output.PhoneVerifiedAt = &verifiedAt
+		output.TOTPEnabled = true
+		otp := MockOTPCode(t, mockUser, repository.OTPPurposeLogin, "123456")
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber).Return(output, nil)
+		mockRepo.EXPECT().GetLatestOTPCode(gomock.Any(), mockUser.ID, repository.OTPPurposeLogin).Return(otp, nil)
+		mockRepo.EXPECT().UseOTPCodeAttempt(gomock.Any(), otp.ID, MockOTPConfig().OTPMaxAttempts).Return(true, nil)
+		mockRepo.EXPECT().ConsumeOTPCode(gomock.Any(), otp.ID).Return(true, nil)
+		mockRepo.EXPECT().CreateLoginChallenge(gomock.Any(), gomock.Any()).Return(nil)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          echo.New(),
+			httpMethod: http.MethodPost,
+			url:        "/login/otp/verify",
+			body:       []byte(`{"phone_number": "+62345678901", "code": "123456"}`),
+		})
+
+		s := &Server{Repository: mockRepo, Config: MockTwoFactorConfig()}
+		err := s.LoginWithCode(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+	})
+}
+
+func TestLoginWithCode_Error(t *testing.T) {
+	mockUser := MockUser()
+
+	t.Run("when error due to code is wrong", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		otp := MockOTPCode(t, mockUser, repository.OTPPurposeLogin, "123456")
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber).Return(MockLoginUserOutput(mockUser), nil)
+		mockRepo.EXPECT().GetLatestOTPCode(gomock.Any(), mockUser.ID, repository.OTPPurposeLogin).Return(otp, nil)
+		mockRepo.EXPECT().UseOTPCodeAttempt(gomock.Any(), otp.ID, gomock.Any()).Return(true, nil)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          echo.New(),
+			httpMethod: http.MethodPost,
+			url:        "/login/otp/verify",
+			body:       []byte(`{"phone_number": "+62345678901", "code": "654321"}`),
+		})
+
+		s := &Server{Repository: mockRepo, Config: MockTwoFactorConfig()}
+		_ = s.LoginWithCode(ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("when error due to attempts are used up", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		otp := MockOTPCode(t, mockUser, repository.OTPPurposeLogin, "123456")
+		otp.Attempts = MockOTPConfig().OTPMaxAttempts
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber).Return(MockLoginUserOutput(mockUser), nil)
+		mockRepo.EXPECT().GetLatestOTPCode(gomock.Any(), mockUser.ID, repository.OTPPurposeLogin).Return(otp, nil)
+		mockRepo.EXPECT().UseOTPCodeAttempt(gomock.Any(), otp.ID, MockOTPConfig().OTPMaxAttempts).Return(false, nil)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          echo.New(),
+			httpMethod: http.MethodPost,
+			url:        "/login/otp/verify",
+			body:       []byte(`{"phone_number": "+62345678901", "code": "123456"}`),
+		})
+
+		s := &Server{Repository: mockRepo, Config: MockTwoFactorConfig()}
+		_ = s.LoginWithCode(ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestRequestLoginCode(t *testing.T) {
+	mockUser := MockUser()
+	requestBody := []byte(`{"phone_number": "+62345678901"}`)
+
+	t.Run("when success accept an unknown phone number without sending", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		sender := sms.NewMemorySender()
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber).Return(repository.LoginUserOutput{}, sql.ErrNoRows)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          echo.New(),
+			httpMethod: http.MethodPost,
+			url:        "/login/otp",
+			body:       requestBody,
+		})
+
+		s := &Server{Repository: mockRepo, SMSSender: sender, Config: MockOTPConfig()}
+		err := s.RequestLoginCode(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		assert.Empty(t, sender.Messages())
+	})
+
+	t.Run("when success skip sending once the hourly limit is reached", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		sender := sms.NewMemorySender()
+		cfg := MockOTPConfig()
+		cfg.OTPMaxSendsPerHour = 5
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber).Return(MockLoginUserOutput(mockUser), nil)
+		mockRepo.EXPECT().GetLatestOTPCode(gomock.Any(), mockUser.ID, repository.OTPPurposeLogin).Return(nil, sql.ErrNoRows)
+		mockRepo.EXPECT().CountOTPCodesSentSince(gomock.Any(), mockUser.PhoneNumber, gomock.Any()).Return(5, nil)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          echo.New(),
+			httpMethod: http.MethodPost,
+			url:        "/login/otp",
+			body:       requestBody,
+		})
+
+		s := &Server{Repository: mockRepo, SMSSender: sender, Config: cfg}
+		err := s.RequestLoginCode(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		assert.Empty(t, sender.Messages())
+	})
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/SawitProRecruitment/UserService/config"
+	"github.com/SawitProRecruitment/UserService/tools"
+)
+
+// defaultPasswordHasher is used by servers built without a hasher: bcrypt at
+// the default cost, which never fails to build.
+var defaultPasswordHasher, _ = tools.NewPasswordHasher(tools.PasswordHasherOptions{})
+
+// NewPasswordHasher builds the hasher for the configured algorithm, so a bad
+// PASSWORD_HASH_* setting is reported once at startup.
+func NewPasswordHasher(cfg config.Config) (*tools.PasswordHasher, error) {
+	return tools.NewPasswordHasher(tools.PasswordHasherOptions{
+		Algorithm:         cfg.PasswordHashAlgorithm,
+		BcryptCost:        cfg.PasswordBcryptCost,
+		Argon2Memory:      cfg.PasswordArgon2MemoryKiB,
+		Argon2Iterations:  cfg.PasswordArgon2Iterations,
+		Argon2Parallelism: cfg.PasswordArgon2Parallelism,
+	})
+}
+
+func (s *Server) passwordHasher() *tools.PasswordHasher {
+	if s.PasswordHasher == nil {
+		return defaultPasswordHasher
+	}
+	return s.PasswordHasher
+}
+
+// hashPassword hashes a user's new password, or a one-time code, with the
+// configured algorithm.
+func (s *Server) hashPassword(pwd string) (string, error) {
+	return s.passwordHasher().Hash(pwd)
+}
+
+// rehashOutdatedPassword upgrades a hash made with another algorithm or cost
+// while the plain password is at hand, which is only right after it was
+// checked. Users are migrated as they log in, without a reset.
+func (s *Server) rehashOutdatedPassword(ctx context.Context, userID int, currentHash string, pwd string) error {
+	hasher := s.passwordHasher()
+	if !hasher.NeedsRehash(currentHash) {
+		return nil
+	}
+
+	newHash, err := hasher.Hash(pwd)
+	if err != nil {
+		return err
+	}
+	_, err = s.Repository.RehashUserPassword(ctx, userID, currentHash, newHash)
+	return err
+}
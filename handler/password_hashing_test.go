@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/SawitProRecruitment/UserService/config"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/tools"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func MockArgon2idConfig() config.Config {
+	return config.Config{
+		RSAPrivateKey:               tools.MockRSAPrivateKey(),
+		JWTTokenLifetimeInHours:     8,
+		RefreshTokenLifetimeInHours: 720,
+		PasswordHashAlgorithm:       tools.PasswordHashArgon2id,
+		PasswordArgon2MemoryKiB:     1024,
+		PasswordArgon2Iterations:    1,
+	}
+}
+
+func MockArgon2idHasher(t *testing.T) *tools.PasswordHasher {
+	hasher, err := NewPasswordHasher(MockArgon2idConfig())
+	assert.NoError(t, err)
+	return hasher
+}
+
+func TestNewPasswordHasher(t *testing.T) {
+	t.Run("when success default to bcrypt", func(t *testing.T) {
+		hasher, err := NewPasswordHasher(config.Config{})
+		assert.NoError(t, err)
+
+		hashed, err := hasher.Hash("IloveVirginCo2Nut123$")
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(hashed, "$2a$"))
+	})
+
+	t.Run("when error the algorithm is unknown", func(t *testing.T) {
+		_, err := NewPasswordHasher(config.Config{PasswordHashAlgorithm: "md5"})
+		assert.Error(t, err)
+	})
+
+	t.Run("when error the bcrypt cost is out of range", func(t *testing.T) {
+		_, err := NewPasswordHasher(config.Config{PasswordBcryptCost: 40})
+		assert.Error(t, err)
+	})
+}
+
+func TestLoginUser_Rehash(t *testing.T) {
+	mockUser := MockUser()
+
+	t.Run("when success upgrade a bcrypt hash to argon2id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		bcryptHash, _ := tools.HashPassword(mockUser.Password)
+		output := MockLoginUserOutput(mockUser)
+		output.Password = bcryptHash
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber).Return(output, nil)
+		mockRepo.EXPECT().RehashUserPassword(gomock.Any(), mockUser.ID, bcryptHash, gomock.Any()).DoAndReturn(
+			func(_ interface{}, _ int, _ string, newHash string) (bool, error) {
+				assert.True(t, strings.HasPrefix(newHash, "$argon2id$"))
+				assert.True(t, tools.IsValidPassword(newHash, mockUser.Password))
+				return true, nil
+			},
+		)
+		mockRepo.EXPECT().GetUserAccess(gomock.Any(), gomock.Any()).Return(repository.UserAccess{}, nil)
+		mockRepo.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Return(nil)
+
+		ctx, rec := TestRequestEndpoint(loginRequest())
+		s := &Server{Repository: mockRepo, Config: MockArgon2idConfig(), PasswordHasher: MockArgon2idHasher(t)}
+
+		err := s.LoginUser(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("when success leave an up to date hash alone", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		s := &Server{Config: MockArgon2idConfig(), PasswordHasher: MockArgon2idHasher(t)}
+		argon2Hash, err := s.hashPassword(mockUser.Password)
+		assert.NoError(t, err)
+		output := MockLoginUserOutput(mockUser)
+		output.Password = argon2Hash
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber).Return(output, nil)
+		mockRepo.EXPECT().GetUserAccess(gomock.Any(), gomock.Any()).Return(repository.UserAccess{}, nil)
+		mockRepo.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Return(nil)
+		s.Repository = mockRepo
+
+		ctx, rec := TestRequestEndpoint(loginRequest())
+		err = s.LoginUser(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("when success log in even though the upgrade failed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		bcryptHash, _ := tools.HashPassword(mockUser.Password)
+		output := MockLoginUserOutput(mockUser)
+		output.Password = bcryptHash
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber).Return(output, nil)
+		mockRepo.EXPECT().RehashUserPassword(gomock.Any(), mockUser.ID, bcryptHash, gomock.Any()).Return(false, errors.New("connection reset"))
+		mockRepo.EXPECT().GetUserAccess(gomock.Any(), gomock.Any()).Return(repository.UserAccess{}, nil)
+		mockRepo.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Return(nil)
+
+		ctx, rec := TestRequestEndpoint(loginRequest())
+		s := &Server{Repository: mockRepo, Config: MockArgon2idConfig(), PasswordHasher: MockArgon2idHasher(t)}
+
+		err := s.LoginUser(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
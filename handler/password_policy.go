@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/config"
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/tools"
+	"github.com/labstack/echo/v4"
+)
+
+// passwordMaxLength matches the max=64 on the password fields in api.yml.
+const passwordMaxLength = 64
+
+var errPasswordReused = errors.New("password was used recently, choose another one")
+
+// PasswordPolicy builds the policy the pwd validation enforces from the
+// config. Every setting left out keeps its value from the default policy.
+func PasswordPolicy(cfg config.Config) tools.PasswordPolicy {
+	policy := tools.DefaultPasswordPolicy
+	if cfg.PasswordMinLength > 0 {
+		policy.MinLength = cfg.PasswordMinLength
+	}
+	if cfg.PasswordRequireUppercase != nil {
+		policy.RequireUppercase = *cfg.PasswordRequireUppercase
+	}
+	if cfg.PasswordRequireLowercase != nil {
+		policy.RequireLowercase = *cfg.PasswordRequireLowercase
+	}
+	if cfg.PasswordRequireNumber != nil {
+		policy.RequireNumber = *cfg.PasswordRequireNumber
+	}
+	if cfg.PasswordRequireSpecial != nil {
+		policy.RequireSpecial = *cfg.PasswordRequireSpecial
+	}
+	return policy
+}
+
+// GetPasswordPolicy describes the rules a new password has to follow, so
+// clients can show them before the user submits one.
+func (s *Server) GetPasswordPolicy(ctx echo.Context) error {
+	policy := PasswordPolicy(s.Config)
+
+	return ctx.JSON(http.StatusOK, generated.PasswordPolicyResponse{
+		MinLength:        policy.MinLength,
+		MaxLength:        passwordMaxLength,
+		RequireUppercase: policy.RequireUppercase,
+		RequireLowercase: policy.RequireLowercase,
+		RequireNumber:    policy.RequireNumber,
+		RequireSpecial:   policy.RequireSpecial,
+		RejectBreached:   s.Config.PasswordCommonListFile != "" || s.Config.PasswordBreachedHashesPath != "",
+		MaxAgeInDays:     s.Config.PasswordMaxAgeInDays,
+		HistorySize:      s.Config.PasswordHistorySize,
+	})
+}
+
+// checkPasswordReuse refuses pwd when it is the current password or one of
+// the replaced ones still kept in the history.
+func (s *Server) checkPasswordReuse(ctx context.Context, userID int, currentHash string, pwd string) error {
+	if s.Config.PasswordHistorySize <= 0 {
+		return nil
+	}
+	if tools.IsValidPassword(currentHash, pwd) {
+		return errPasswordReused
+	}
+	if s.Config.PasswordHistorySize == 1 {
+		return nil
+	}
+
+	hashes, err := s.Repository.GetPasswordHistory(ctx, userID, s.Config.PasswordHistorySize-1)
+	if err != nil {
+		return err
+	}
+	for _, hash := range hashes {
+		if tools.IsValidPassword(hash, pwd) {
+			return errPasswordReused
+		}
+	}
+	return nil
+}
+
+// passwordExpired reports whether the password is older than the configured
+// maximum age.
+func (s *Server) passwordExpired(output repository.LoginUserOutput) bool {
+	if s.Config.PasswordMaxAgeInDays <= 0 {
+		return false
+	}
+	maxAge := time.Duration(s.Config.PasswordMaxAgeInDays) * 24 * time.Hour
+	return time.Now().UTC().After(output.PasswordChangedAt.Add(maxAge))
+}
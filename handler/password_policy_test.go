@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/config"
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/tools"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPasswordPolicy(t *testing.T) {
+	enabled, disabled := true, false
+
+	t.Run("when success describe the configured policy", func(t *testing.T) {
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{e: echo.New(), httpMethod: http.MethodGet, url: "/password-policy"})
+
+		s := &Server{Config: config.Config{
+			PasswordMinLength:          10,
+			PasswordRequireUppercase:   &disabled,
+			PasswordRequireLowercase:   &enabled,
+			PasswordRequireNumber:      &enabled,
+			PasswordRequireSpecial:     &disabled,
+			PasswordMaxAgeInDays:       90,
+			PasswordHistorySize:        5,
+			PasswordBreachedHashesPath: "/data/pwned-passwords",
+		}}
+		err := s.GetPasswordPolicy(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var resp generated.PasswordPolicyResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, generated.PasswordPolicyResponse{
+			MinLength:        10,
+			MaxLength:        64,
+			RequireLowercase: true,
+			RequireNumber:    true,
+			RejectBreached:   true,
+			MaxAgeInDays:     90,
+			HistorySize:      5,
+		}, resp)
+	})
+
+	t.Run("when success an empty config keeps the default policy", func(t *testing.T) {
+		assert.Equal(t, tools.DefaultPasswordPolicy, PasswordPolicy(config.Config{}))
+	})
+
+	t.Run("when success a rule left out keeps its default", func(t *testing.T) {
+		policy := PasswordPolicy(config.Config{PasswordRequireSpecial: &disabled})
+		assert.False(t, policy.RequireSpecial)
+		assert.True(t, policy.RequireUppercase)
+		assert.True(t, policy.RequireNumber)
+	})
+}
+
+func TestChangePassword_History(t *testing.T) {
+	mockUser := MockUser()
+	hashedPassword, _ := tools.HashPassword(mockUser.Password)
+	mockUser.Password = hashedPassword
+	previousHash, _ := tools.HashPassword("IloveRedPalmOil456$")
+
+	changePassword := func(t *testing.T, mockRepo *repository.MockRepositoryInterface, mockRevocations *repository.MockTokenRevocationRepositoryInterface, newPassword string) int {
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          echo.New(),
+			httpMethod: http.MethodPut,
+			url:        "/users/password",
+			body:       []byte(`{"current_password": "IloveVirginCo2Nut123$", "new_password": "` + newPassword + `"}`),
+		})
+		ctx.Set("UserGUID", mockUser.GUID.String())
+
+		s := &Server{Repository: mockRepo, TokenRevocations: mockRevocations, Config: config.Config{PasswordHistorySize: 3}}
+		err := s.ChangePassword(ctx)
+		assert.NoError(t, err)
+		return rec.Code
+	}
+
+	t.Run("when success record the replaced password", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), mockUser.GUID).Return(mockUser, nil)
+		mockRepo.EXPECT().GetPasswordHistory(gomock.Any(), mockUser.ID, 2).Return([]string{previousHash}, nil)
+		mockRepo.EXPECT().UpdateUserPassword(gomock.Any(), mockUser.ID, gomock.Any(), 3).Return(nil)
+		mockRepo.EXPECT().RevokeUserRefreshTokens(gomock.Any(), mockUser.GUID).Return(nil)
+		mockRevocations := repository.NewMockTokenRevocationRepositoryInterface(ctrl)
+		mockRevocations.EXPECT().RevokeUserAccessTokens(gomock.Any(), mockUser.GUID, gomock.Any()).Return(nil)
+
+		assert.Equal(t, http.StatusOK, changePassword(t, mockRepo, mockRevocations, "IloveSagoPalm789$"))
+	})
+
+	t.Run("when error the password is in the history", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), mockUser.GUID).Return(mockUser, nil)
+		mockRepo.EXPECT().GetPasswordHistory(gomock.Any(), mockUser.ID, 2).Return([]string{previousHash}, nil)
+
+		assert.Equal(t, http.StatusBadRequest, changePassword(t, mockRepo, nil, "IloveRedPalmOil456$"))
+	})
+}
+
+func TestResetPassword_History(t *testing.T) {
+	t.Run("when error the new password was used before", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		currentHash, _ := tools.HashPassword("IloveRedPalmOil456$")
+		mockUser.Password = currentHash
+		otp := MockOTPCode(t, mockUser, repository.OTPPurposePasswordReset, "123456")
+		verifiedAt := time.Now().UTC()
+		otp.VerifiedAt = &verifiedAt
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetOTPCodeByVerificationTokenHash(gomock.Any(), tools.HashOpaqueToken("reset-token"), repository.OTPPurposePasswordReset).Return(otp, nil)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), mockUser.GUID).Return(mockUser, nil)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          echo.New(),
+			httpMethod: http.MethodPost,
+			url:        "/password/reset",
+			body:       []byte(`{"reset_token": "reset-token", "new_password": "IloveRedPalmOil456$"}`),
+		})
+
+		cfg := MockOTPConfig()
+		cfg.PasswordHistorySize = 1
+		s := &Server{Repository: mockRepo, Config: cfg}
+		err := s.ResetPassword(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), errPasswordReused.Error())
+	})
+}
+
+func TestLoginUser_PasswordExpired(t *testing.T) {
+	t.Run("when error the password is older than the maximum age", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		output := MockLoginUserOutput(mockUser)
+		output.Password, _ = tools.HashPassword(mockUser.Password)
+		output.PasswordChangedAt = time.Now().UTC().Add(-91 * 24 * time.Hour)
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber).Return(output, nil)
+
+		ctx, rec := TestRequestEndpoint(loginRequest())
+		s := &Server{Repository: mockRepo, Config: config.Config{PasswordMaxAgeInDays: 90}}
+
+		err := s.LoginUser(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/tools"
+	"github.com/labstack/echo/v4"
+)
+
+const passwordResetMessageFormat = "Your password reset code is %s. Do not share it with anyone."
+
+// RequestPasswordReset texts a reset code to the phone number. The response
+// is the same whether or not the number is registered, so it cannot be used
+// to find out who has an account.
+func (s *Server) RequestPasswordReset(ctx echo.Context) error {
+	rCtx := ctx.Request().Context()
+	var input generated.RequestPasswordResetJSONRequestBody
+	var errData *tools.Err
+
+	err := ctx.Bind(&input)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	err = tools.ValidateRequestPayload(input)
+	if err != nil && errors.As(err, &errData) {
+		return ctx.JSON(errData.Code, generated.ErrorWithExtraResponse{Message: errData.Message, Extra: &errData.Extra})
+	}
+
+	accepted := generated.DefaultUpdateResponse{Message: "a reset code has been sent if the phone number is registered"}
+
+	user, err := s.Repository.GetUserLoginByPhoneNumber(rCtx, input.PhoneNumber)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ctx.JSON(http.StatusAccepted, accepted)
+		}
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	err = s.sendOTP(rCtx, user.ID, input.PhoneNumber, repository.OTPPurposePasswordReset, passwordResetMessageFormat)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusAccepted, accepted)
+}
+
+// VerifyPasswordResetCode exchanges a correct code for a short-lived reset
+// token, so the new password does not have to travel with the code.
+func (s *Server) VerifyPasswordResetCode(ctx echo.Context) error {
+	rCtx := ctx.Request().Context()
+	var input generated.VerifyPasswordResetCodeJSONRequestBody
+	var errData *tools.Err
+
+	err := ctx.Bind(&input)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	err = tools.ValidateRequestPayload(input)
+	if err != nil && errors.As(err, &errData) {
+		return ctx.JSON(errData.Code, generated.ErrorWithExtraResponse{Message: errData.Message, Extra: &errData.Extra})
+	}
+
+	user, err := s.Repository.GetUserLoginByPhoneNumber(rCtx, input.PhoneNumber)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: errInvalidOTP.Error()})
+		}
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	otp, err := s.checkOTP(rCtx, user.ID, repository.OTPPurposePasswordReset, input.PhoneNumber, input.Code)
+	if err != nil {
+		if err == errInvalidOTP {
+			return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: err.Error()})
+		}
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	resetToken, resetTokenHash, err := tools.GenerateOpaqueToken()
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	marked, err := s.Repository.MarkOTPCodeVerified(rCtx, otp.ID, resetTokenHash)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+	if !marked {
+		return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: errInvalidOTP.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, generated.PasswordResetVerifyResponse{
+		ResetToken: resetToken,
+		ExpiresAt:  time.Now().UTC().Add(time.Duration(s.Config.PasswordResetTokenLifetimeInSeconds) * time.Second),
+	})
+}
+
+// ResetPassword sets the new password and signs the user out everywhere, in
+// case whoever locked them out is still holding a session.
+func (s *Server) ResetPassword(ctx echo.Context) error {
+	rCtx := ctx.Request().Context()
+	var input generated.ResetPasswordJSONRequestBody
+	var errData *tools.Err
+
+	err := ctx.Bind(&input)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	err = tools.ValidateRequestPayload(input)
+	if err != nil && errors.As(err, &errData) {
+		return ctx.JSON(errData.Code, generated.ErrorWithExtraResponse{Message: errData.Message, Extra: &errData.Extra})
+	}
+
+	invalidResetToken := generated.ErrorResponse{Message: "invalid or expired reset token"}
+
+	otp, err := s.Repository.GetOTPCodeByVerificationTokenHash(rCtx, tools.HashOpaqueToken(input.ResetToken), repository.OTPPurposePasswordReset)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ctx.JSON(http.StatusBadRequest, invalidResetToken)
+		}
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	lifetime := time.Duration(s.Config.PasswordResetTokenLifetimeInSeconds) * time.Second
+	if otp.ConsumedAt != nil || otp.VerifiedAt == nil || time.Now().UTC().After(otp.VerifiedAt.Add(lifetime)) {
+		return ctx.JSON(http.StatusBadRequest, invalidResetToken)
+	}
+
+	if s.Config.PasswordHistorySize > 0 {
+		user, err := s.Repository.GetUserByGUID(rCtx, otp.UserGUID)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+		}
+		err = s.checkPasswordReuse(rCtx, otp.UserID, user.Password, input.NewPassword)
+		if err != nil {
+			if err == errPasswordReused {
+				return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: err.Error()})
+			}
+			return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+		}
+	}
+
+	hashedPassword, err := s.hashPassword(input.NewPassword)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	// Consuming first means two requests racing with the same token cannot
+	// both set a password.
+	consumed, err := s.Repository.ConsumeOTPCode(rCtx, otp.ID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+	if !consumed {
+		return ctx.JSON(http.StatusBadRequest, invalidResetToken)
+	}
+
+	err = s.Repository.UpdateUserPassword(rCtx, otp.UserID, hashedPassword, s.Config.PasswordHistorySize)
+	if err != nil {
+		if errors.As(err, &errData) {
+			return ctx.JSON(errData.Code, generated.ErrorResponse{Message: errData.Message})
+		}
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	err = s.revokeAllUserTokens(rCtx, otp.UserGUID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	err = s.unlockUserLogin(rCtx, otp.UserID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, generated.DefaultUpdateResponse{Message: "password reset successfully, please log in again"})
+}
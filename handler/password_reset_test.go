@@ -0,0 +1,323 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/config"
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/sms"
+	"github.com/SawitProRecruitment/UserService/tools"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func MockOTPConfig() config.Config {
+	return config.Config{
+		OTPLifetimeInSeconds:                300,
+		OTPMaxAttempts:                      5,
+		OTPResendIntervalInSeconds:          60,
+		PasswordResetTokenLifetimeInSeconds: 600,
+	}
+}
+
+func MockOTPCode(t *testing.T, user *repository.User, purpose string, code string) *repository.OTPCode {
+	codeHash, err := tools.HashPassword(code)
+	assert.NoError(t, err)
+
+	return &repository.OTPCode{
+		ID:          7,
+		UserID:      user.ID,
+		Purpose:     purpose,
+		PhoneNumber: user.PhoneNumber,
+		CodeHash:    codeHash,
+		ExpiresAt:   time.Now().UTC().Add(5 * time.Minute),
+		CreatedAt:   time.Now().UTC().Add(-2 * time.Minute),
+		UserGUID:    user.GUID,
+	}
+}
+
+func MockLoginUserOutput(user *repository.User) repository.LoginUserOutput {
+	return repository.LoginUserOutput{ID: user.ID, GUID: user.GUID, FullName: user.FullName, Password: user.Password}
+}
+
+func TestRequestPasswordReset(t *testing.T) {
+	mockUser := MockUser()
+	requestBody := []byte(`{"phone_number": "+62345678901"}`)
+
+	t.Run("when success send a reset code", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var sentCodeHash string
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber).Return(MockLoginUserOutput(mockUser), nil)
+		mockRepo.EXPECT().GetLatestOTPCode(gomock.Any(), mockUser.ID, repository.OTPPurposePasswordReset).Return(nil, sql.ErrNoRows)
+		mockRepo.EXPECT().CreateOTPCode(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ interface{}, code *repository.OTPCode) error {
+				assert.Equal(t, repository.OTPPurposePasswordReset, code.Purpose)
+				assert.True(t, code.ExpiresAt.After(time.Now().UTC()))
+				sentCodeHash = code.CodeHash
+				return nil
+			},
+		)
+		mockSender := sms.NewMockSMSSender(ctrl)
+		mockSender.EXPECT().Send(gomock.Any(), mockUser.PhoneNumber, gomock.Any()).DoAndReturn(
+			func(_ interface{}, _ string, message string) error {
+				code := regexp.MustCompile(`[0-9]{6}`).FindString(message)
+				assert.True(t, tools.IsValidPassword(sentCodeHash, code))
+				return nil
+			},
+		)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodPost, url: "/password/forgot", body: requestBody})
+
+		s := &Server{Repository: mockRepo, SMSSender: mockSender, Config: MockOTPConfig()}
+		err := s.RequestPasswordReset(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+	})
+
+	t.Run("when success accept an unknown phone number without sending", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber).Return(repository.LoginUserOutput{}, sql.ErrNoRows)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodPost, url: "/password/forgot", body: requestBody})
+
+		s := &Server{Repository: mockRepo, SMSSender: sms.NewMockSMSSender(ctrl), Config: MockOTPConfig()}
+		err := s.RequestPasswordReset(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+	})
+
+	t.Run("when success skip sending within the resend interval", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		latest := MockOTPCode(t, mockUser, repository.OTPPurposePasswordReset, "123456")
+		latest.CreatedAt = time.Now().UTC().Add(-10 * time.Second)
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber).Return(MockLoginUserOutput(mockUser), nil)
+		mockRepo.EXPECT().GetLatestOTPCode(gomock.Any(), mockUser.ID, repository.OTPPurposePasswordReset).Return(latest, nil)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodPost, url: "/password/forgot", body: requestBody})
+
+		s := &Server{Repository: mockRepo, SMSSender: sms.NewMockSMSSender(ctrl), Config: MockOTPConfig()}
+		err := s.RequestPasswordReset(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+	})
+}
+
+func TestVerifyPasswordResetCode(t *testing.T) {
+	t.Run("when success exchange the code for a reset token", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		otp := MockOTPCode(t, mockUser, repository.OTPPurposePasswordReset, "123456")
+
+		var storedHash string
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber).Return(MockLoginUserOutput(mockUser), nil)
+		mockRepo.EXPECT().GetLatestOTPCode(gomock.Any(), mockUser.ID, repository.OTPPurposePasswordReset).Return(otp, nil)
+		mockRepo.EXPECT().UseOTPCodeAttempt(gomock.Any(), otp.ID, MockOTPConfig().OTPMaxAttempts).Return(true, nil)
+		mockRepo.EXPECT().MarkOTPCodeVerified(gomock.Any(), otp.ID, gomock.Any()).DoAndReturn(
+			func(_ interface{}, _ int, tokenHash string) (bool, error) {
+				storedHash = tokenHash
+				return true, nil
+			},
+		)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/password/forgot/verify",
+			body:       []byte(`{"phone_number": "+62345678901", "code": "123456"}`),
+		})
+
+		s := &Server{Repository: mockRepo, Config: MockOTPConfig()}
+		err := s.VerifyPasswordResetCode(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var resp generated.PasswordResetVerifyResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, storedHash, tools.HashOpaqueToken(resp.ResetToken))
+	})
+}
+
+func TestVerifyPasswordResetCode_Error(t *testing.T) {
+	mockUser := MockUser()
+	requestBody := []byte(`{"phone_number": "+62345678901", "code": "654321"}`)
+
+	t.Run("when error due to code is wrong", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		otp := MockOTPCode(t, mockUser, repository.OTPPurposePasswordReset, "123456")
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber).Return(MockLoginUserOutput(mockUser), nil)
+		mockRepo.EXPECT().GetLatestOTPCode(gomock.Any(), mockUser.ID, repository.OTPPurposePasswordReset).Return(otp, nil)
+		mockRepo.EXPECT().UseOTPCodeAttempt(gomock.Any(), otp.ID, gomock.Any()).Return(true, nil)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodPost, url: "/password/forgot/verify", body: requestBody})
+
+		s := &Server{Repository: mockRepo, Config: MockOTPConfig()}
+		err := s.VerifyPasswordResetCode(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("when error due to attempts are used up", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		otp := MockOTPCode(t, mockUser, repository.OTPPurposePasswordReset, "654321")
+		otp.Attempts = 5
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber).Return(MockLoginUserOutput(mockUser), nil)
+		mockRepo.EXPECT().GetLatestOTPCode(gomock.Any(), mockUser.ID, repository.OTPPurposePasswordReset).Return(otp, nil)
+		mockRepo.EXPECT().UseOTPCodeAttempt(gomock.Any(), otp.ID, MockOTPConfig().OTPMaxAttempts).Return(false, nil)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodPost, url: "/password/forgot/verify", body: requestBody})
+
+		s := &Server{Repository: mockRepo, Config: MockOTPConfig()}
+		err := s.VerifyPasswordResetCode(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("when error due to code has expired", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		otp := MockOTPCode(t, mockUser, repository.OTPPurposePasswordReset, "654321")
+		otp.ExpiresAt = time.Now().UTC().Add(-time.Second)
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber).Return(MockLoginUserOutput(mockUser), nil)
+		mockRepo.EXPECT().GetLatestOTPCode(gomock.Any(), mockUser.ID, repository.OTPPurposePasswordReset).Return(otp, nil)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodPost, url: "/password/forgot/verify", body: requestBody})
+
+		s := &Server{Repository: mockRepo, Config: MockOTPConfig()}
+		err := s.VerifyPasswordResetCode(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestResetPassword(t *testing.T) {
+	t.Run("when success reset the password", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		otp := MockOTPCode(t, mockUser, repository.OTPPurposePasswordReset, "123456")
+		verifiedAt := time.Now().UTC()
+		otp.VerifiedAt = &verifiedAt
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetOTPCodeByVerificationTokenHash(gomock.Any(), tools.HashOpaqueToken("reset-token"), repository.OTPPurposePasswordReset).Return(otp, nil)
+		mockRepo.EXPECT().ConsumeOTPCode(gomock.Any(), otp.ID).Return(true, nil)
+		mockRepo.EXPECT().UpdateUserPassword(gomock.Any(), mockUser.ID, gomock.Any(), 0).Return(nil)
+		mockRepo.EXPECT().RevokeUserRefreshTokens(gomock.Any(), mockUser.GUID).Return(nil)
+		mockRevocations := repository.NewMockTokenRevocationRepositoryInterface(ctrl)
+		mockRevocations.EXPECT().RevokeUserAccessTokens(gomock.Any(), mockUser.GUID, gomock.Any()).Return(nil)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/password/reset",
+			body:       []byte(`{"reset_token": "reset-token", "new_password": "IloveRedPalmOil456$"}`),
+		})
+
+		s := &Server{Repository: mockRepo, TokenRevocations: mockRevocations, Config: MockOTPConfig()}
+		err := s.ResetPassword(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
+
+func TestResetPassword_Error(t *testing.T) {
+	mockUser := MockUser()
+	requestBody := []byte(`{"reset_token": "reset-token", "new_password": "IloveRedPalmOil456$"}`)
+
+	t.Run("when error due to reset token is unknown", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetOTPCodeByVerificationTokenHash(gomock.Any(), gomock.Any(), repository.OTPPurposePasswordReset).Return(nil, sql.ErrNoRows)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodPost, url: "/password/reset", body: requestBody})
+
+		s := &Server{Repository: mockRepo, Config: MockOTPConfig()}
+		err := s.ResetPassword(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("when error due to reset token has expired", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		otp := MockOTPCode(t, mockUser, repository.OTPPurposePasswordReset, "123456")
+		verifiedAt := time.Now().UTC().Add(-time.Hour)
+		otp.VerifiedAt = &verifiedAt
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetOTPCodeByVerificationTokenHash(gomock.Any(), gomock.Any(), repository.OTPPurposePasswordReset).Return(otp, nil)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodPost, url: "/password/reset", body: requestBody})
+
+		s := &Server{Repository: mockRepo, Config: MockOTPConfig()}
+		err := s.ResetPassword(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("when error due to reset token was used concurrently", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		otp := MockOTPCode(t, mockUser, repository.OTPPurposePasswordReset, "123456")
+		verifiedAt := time.Now().UTC()
+		otp.VerifiedAt = &verifiedAt
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetOTPCodeByVerificationTokenHash(gomock.Any(), gomock.Any(), repository.OTPPurposePasswordReset).Return(otp, nil)
+		mockRepo.EXPECT().ConsumeOTPCode(gomock.Any(), otp.ID).Return(false, nil)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodPost, url: "/password/reset", body: requestBody})
+
+		s := &Server{Repository: mockRepo, Config: MockOTPConfig()}
+		err := s.ResetPassword(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/tools"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	phoneVerificationMessageFormat = "Your phone verification code is %s. Do not share it with anyone."
+	phoneChangeMessageFormat       = "Your code to confirm this phone number is %s. Do not share it with anyone."
+)
+
+// VerifyPhoneNumber marks the registered phone number as verified with the
+// code sent to it on registration.
+func (s *Server) VerifyPhoneNumber(ctx echo.Context) error {
+	rCtx := ctx.Request().Context()
+	var input generated.VerifyPhoneNumberJSONRequestBody
+	var errData *tools.Err
+
+	err := ctx.Bind(&input)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	err = tools.ValidateRequestPayload(input)
+	if err != nil && errors.As(err, &errData) {
+		return ctx.JSON(errData.Code, generated.ErrorWithExtraResponse{Message: errData.Message, Extra: &errData.Extra})
+	}
+
+	user, err := s.Repository.GetUserLoginByPhoneNumber(rCtx, input.PhoneNumber)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: errInvalidOTP.Error()})
+		}
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	otp, err := s.checkOTP(rCtx, user.ID, repository.OTPPurposePhoneVerification, input.PhoneNumber, input.Code)
+	if err != nil {
+		if err == errInvalidOTP {
+			return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: err.Error()})
+		}
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	consumed, err := s.Repository.ConsumeOTPCode(rCtx, otp.ID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+	if !consumed {
+		return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: errInvalidOTP.Error()})
+	}
+
+	err = s.Repository.MarkUserPhoneVerified(rCtx, user.ID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, generated.DefaultUpdateResponse{Message: "phone number verified successfully"})
+}
+
+// ResendPhoneVerificationCode texts a new verification code. Like the password
+// reset request it answers the same way for every number, so it cannot be
+// used to find out who has an account.
+func (s *Server) ResendPhoneVerificationCode(ctx echo.Context) error {
+	rCtx := ctx.Request().Context()
+	var input generated.ResendPhoneVerificationCodeJSONRequestBody
+	var errData *tools.Err
+
+	err := ctx.Bind(&input)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	err = tools.ValidateRequestPayload(input)
+	if err != nil && errors.As(err, &errData) {
+		return ctx.JSON(errData.Code, generated.ErrorWithExtraResponse{Message: errData.Message, Extra: &errData.Extra})
+	}
+
+	accepted := generated.DefaultUpdateResponse{Message: "a verification code has been sent if the phone number is registered and not verified yet"}
+
+	user, err := s.Repository.GetUserLoginByPhoneNumber(rCtx, input.PhoneNumber)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ctx.JSON(http.StatusAccepted, accepted)
+		}
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+	if user.PhoneVerifiedAt != nil {
+		return ctx.JSON(http.StatusAccepted, accepted)
+	}
+
+	err = s.sendOTP(rCtx, user.ID, input.PhoneNumber, repository.OTPPurposePhoneVerification, phoneVerificationMessageFormat)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusAccepted, accepted)
+}
+
+// ConfirmPhoneChange swaps in the pending phone number once the caller enters
+// the code that was sent to it.
+func (s *Server) ConfirmPhoneChange(ctx echo.Context) error {
+	rCtx := ctx.Request().Context()
+	guid := uuid.MustParse(ctx.Get("UserGUID").(string))
+	var errData *tools.Err
+
+	var input generated.ConfirmPhoneChangeJSONRequestBody
+	err := ctx.Bind(&input)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	err = tools.ValidateRequestPayload(input)
+	if err != nil && errors.As(err, &errData) {
+		return ctx.JSON(errData.Code, generated.ErrorWithExtraResponse{Message: errData.Message, Extra: &errData.Extra})
+	}
+
+	user, err := s.Repository.GetUserByGUID(rCtx, guid)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ctx.JSON(http.StatusNotFound, generated.ErrorResponse{Message: "user is not found"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+	if user.PendingPhoneNumber == nil {
+		return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: "no phone number change is pending"})
+	}
+
+	otp, err := s.checkOTP(rCtx, user.ID, repository.OTPPurposePhoneChange, *user.PendingPhoneNumber, input.Code)
+	if err != nil {
+		if err == errInvalidOTP {
+			return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: err.Error()})
+		}
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	consumed, err := s.Repository.ConsumeOTPCode(rCtx, otp.ID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+	if !consumed {
+		return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: errInvalidOTP.Error()})
+	}
+
+	confirmed, err := s.Repository.ConfirmUserPendingPhoneNumber(rCtx, user.ID, *user.PendingPhoneNumber)
+	if err != nil {
+		if errors.As(err, &errData) {
+			return ctx.JSON(errData.Code, generated.ErrorResponse{Message: errData.Message})
+		}
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+	if !confirmed {
+		return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: "no phone number change is pending"})
+	}
+
+	return ctx.JSON(http.StatusOK, generated.DefaultUpdateResponse{Message: "phone number changed successfully"})
+}
@@ -0,0 +1,231 @@
+package handler
+
+import (
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/sms"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyPhoneNumber(t *testing.T) {
+	t.Run("when success verify the phone number", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		otp := MockOTPCode(t, mockUser, repository.OTPPurposePhoneVerification, "123456")
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber).Return(MockLoginUserOutput(mockUser), nil)
+		mockRepo.EXPECT().GetLatestOTPCode(gomock.Any(), mockUser.ID, repository.OTPPurposePhoneVerification).Return(otp, nil)
+		mockRepo.EXPECT().UseOTPCodeAttempt(gomock.Any(), otp.ID, MockOTPConfig().OTPMaxAttempts).Return(true, nil)
+		mockRepo.EXPECT().ConsumeOTPCode(gomock.Any(), otp.ID).Return(true, nil)
+		mockRepo.EXPECT().MarkUserPhoneVerified(gomock.Any(), mockUser.ID).Return(nil)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/phone/verify",
+			body:       []byte(`{"phone_number": "+62345678901", "code": "123456"}`),
+		})
+
+		s := &Server{Repository: mockRepo, Config: MockOTPConfig()}
+		err := s.VerifyPhoneNumber(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
+
+func TestVerifyPhoneNumber_Error(t *testing.T) {
+	mockUser := MockUser()
+
+	t.Run("when error due to code is wrong", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		otp := MockOTPCode(t, mockUser, repository.OTPPurposePhoneVerification, "123456")
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber).Return(MockLoginUserOutput(mockUser), nil)
+		mockRepo.EXPECT().GetLatestOTPCode(gomock.Any(), mockUser.ID, repository.OTPPurposePhoneVerification).Return(otp, nil)
+		mockRepo.EXPECT().UseOTPCodeAttempt(gomock.Any(), otp.ID, gomock.Any()).Return(true, nil)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/phone/verify",
+			body:       []byte(`{"phone_number": "+62345678901", "code": "654321"}`),
+		})
+
+		s := &Server{Repository: mockRepo, Config: MockOTPConfig()}
+		_ = s.VerifyPhoneNumber(ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("when error due to phone number is not registered", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber).Return(repository.LoginUserOutput{}, sql.ErrNoRows)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/phone/verify",
+			body:       []byte(`{"phone_number": "+62345678901", "code": "123456"}`),
+		})
+
+		s := &Server{Repository: mockRepo, Config: MockOTPConfig()}
+		_ = s.VerifyPhoneNumber(ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestResendPhoneVerificationCode(t *testing.T) {
+	mockUser := MockUser()
+	requestBody := []byte(`{"phone_number": "+62345678901"}`)
+
+	t.Run("when success send a new verification code", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber).Return(MockLoginUserOutput(mockUser), nil)
+		mockRepo.EXPECT().GetLatestOTPCode(gomock.Any(), mockUser.ID, repository.OTPPurposePhoneVerification).Return(nil, sql.ErrNoRows)
+		mockRepo.EXPECT().CreateOTPCode(gomock.Any(), gomock.Any()).Return(nil)
+		mockSender := sms.NewMockSMSSender(ctrl)
+		mockSender.EXPECT().Send(gomock.Any(), mockUser.PhoneNumber, gomock.Any()).Return(nil)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/phone/verify/resend",
+			body:       requestBody,
+		})
+
+		s := &Server{Repository: mockRepo, SMSSender: mockSender, Config: MockOTPConfig()}
+		err := s.ResendPhoneVerificationCode(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+	})
+
+	t.Run("when success accept a verified phone number without sending", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		verifiedAt := time.Now().UTC()
+		output := MockLoginUserOutput(mockUser)
+		output.PhoneVerifiedAt = &verifiedAt
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserLoginByPhoneNumber(gomock.Any(), mockUser.PhoneNumber).Return(output, nil)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/phone/verify/resend",
+			body:       requestBody,
+		})
+
+		s := &Server{Repository: mockRepo, Config: MockOTPConfig()}
+		err := s.ResendPhoneVerificationCode(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+	})
+}
+
+func TestConfirmPhoneChange(t *testing.T) {
+	t.Run("when success confirm the pending phone number", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		pending := "+62345678999"
+		mockUser.PendingPhoneNumber = &pending
+		otp := MockOTPCode(t, mockUser, repository.OTPPurposePhoneChange, "123456")
+		otp.PhoneNumber = pending
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), mockUser.GUID).Return(mockUser, nil)
+		mockRepo.EXPECT().GetLatestOTPCode(gomock.Any(), mockUser.ID, repository.OTPPurposePhoneChange).Return(otp, nil)
+		mockRepo.EXPECT().UseOTPCodeAttempt(gomock.Any(), otp.ID, MockOTPConfig().OTPMaxAttempts).Return(true, nil)
+		mockRepo.EXPECT().ConsumeOTPCode(gomock.Any(), otp.ID).Return(true, nil)
+		mockRepo.EXPECT().ConfirmUserPendingPhoneNumber(gomock.Any(), mockUser.ID, pending).Return(true, nil)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/users/phone/confirm",
+			body:       []byte(`{"code": "123456"}`),
+		})
+		ctx.Set("UserGUID", mockUser.GUID.String())
+
+		s := &Server{Repository: mockRepo, Config: MockOTPConfig()}
+		err := s.ConfirmPhoneChange(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
+
+func TestConfirmPhoneChange_Error(t *testing.T) {
+	t.Run("when error due to no phone change is pending", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), mockUser.GUID).Return(mockUser, nil)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/users/phone/confirm",
+			body:       []byte(`{"code": "123456"}`),
+		})
+		ctx.Set("UserGUID", mockUser.GUID.String())
+
+		s := &Server{Repository: mockRepo, Config: MockOTPConfig()}
+		_ = s.ConfirmPhoneChange(ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("when error due to code was sent to an earlier pending number", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		pending := "+62345678999"
+		mockUser.PendingPhoneNumber = &pending
+		otp := MockOTPCode(t, mockUser, repository.OTPPurposePhoneChange, "123456")
+		otp.PhoneNumber = "+62345678888"
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), mockUser.GUID).Return(mockUser, nil)
+		mockRepo.EXPECT().GetLatestOTPCode(gomock.Any(), mockUser.ID, repository.OTPPurposePhoneChange).Return(otp, nil)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/users/phone/confirm",
+			body:       []byte(`{"code": "123456"}`),
+		})
+		ctx.Set("UserGUID", mockUser.GUID.String())
+
+		s := &Server{Repository: mockRepo, Config: MockOTPConfig()}
+		_ = s.ConfirmPhoneChange(ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/SawitProRecruitment/UserService/config"
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/ratelimit"
+	"github.com/labstack/echo/v4"
+)
+
+// RouteRateLimits maps "METHOD /path" to the limit configured for it. Routes
+// whose limit is left at zero are not limited.
+func RouteRateLimits(cfg config.Config) map[string]ratelimit.Limit {
+	return map[string]ratelimit.Limit{
+		http.MethodPost + " /login":    {PerMinute: cfg.RateLimitLoginPerMinute, Burst: cfg.RateLimitLoginBurst},
+		http.MethodPost + " /register": {PerMinute: cfg.RateLimitRegisterPerMinute, Burst: cfg.RateLimitRegisterBurst},
+	}
+}
+
+// RateLimitMiddleware gives every client IP, and every phone number named in
+// the request body, its own token bucket per route. A request goes through
+// only when both buckets have a token left. It must run after routing, i.e.
+// through Echo.Use, so the matched route is known.
+//
+// When the store cannot be reached the request is let through and the error
+// logged, so an outage of the store does not take logins down with it.
+func RateLimitMiddleware(store ratelimit.Store, limits map[string]ratelimit.Limit) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			route := c.Request().Method + " " + c.Path()
+			limit, ok := limits[route]
+			if !ok || !limit.Enabled() {
+				return next(c)
+			}
+
+			keys := []string{"ip:" + route + ":" + c.RealIP()}
+			phoneNumber, err := requestPhoneNumber(c)
+			if err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					return c.JSON(http.StatusRequestEntityTooLarge, generated.ErrorResponse{Message: "request body is too large"})
+				}
+				return c.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: err.Error()})
+			}
+			if phoneNumber != "" {
+				keys = append(keys, "phone:"+route+":"+phoneNumber)
+			}
+
+			for _, key := range keys {
+				result, err := store.Take(c.Request().Context(), key, limit)
+				if err != nil {
+					c.Logger().Errorf("rate limiting %s: %v", key, err)
+					continue
+				}
+				if !result.Allowed {
+					c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds()))))
+					return c.JSON(http.StatusTooManyRequests, generated.ErrorResponse{Message: "too many requests, try again later"})
+				}
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// maxRateLimitedBodyBytes caps the body of a rate limited request. Those
+// routes take a few short fields, and the whole body is read into memory
+// before the limit is even checked.
+const maxRateLimitedBodyBytes = 4 << 10
+
+// requestPhoneNumber peeks at the phone_number of a JSON body and puts the
+// body back for the handler. Bodies that are not JSON objects yield nothing
+// and are left for the handler to reject; bodies over
+// maxRateLimitedBodyBytes fail with an *http.MaxBytesError.
+func requestPhoneNumber(c echo.Context) (string, error) {
+	req := c.Request()
+	if req.Body == nil {
+		return "", nil
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Response(), req.Body, maxRateLimitedBodyBytes))
+	if err != nil {
+		return "", err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	var payload struct {
+		PhoneNumber string `json:"phone_number"`
+	}
+	if json.Unmarshal(body, &payload) != nil {
+		return "", nil
+	}
+	return payload.PhoneNumber, nil
+}
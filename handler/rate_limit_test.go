@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/SawitProRecruitment/UserService/config"
+	"github.com/SawitProRecruitment/UserService/ratelimit"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) Take(context.Context, string, ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("connection refused")
+}
+
+func newRateLimitedEcho(store ratelimit.Store) *echo.Echo {
+	cfg := config.Config{RateLimitLoginPerMinute: 1, RateLimitLoginBurst: 2}
+
+	e := echo.New()
+	e.IPExtractor, _ = NewIPExtractor("")
+	e.Use(RateLimitMiddleware(store, RouteRateLimits(cfg)))
+	echoBody := func(c echo.Context) error {
+		body, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, string(body))
+	}
+	e.POST("/login", echoBody)
+	e.POST("/register", echoBody)
+	return e
+}
+
+func serveRateLimited(e *echo.Echo, url string, remoteAddr string, body string, forwardedFor ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.RemoteAddr = remoteAddr
+	if len(forwardedFor) > 0 {
+		req.Header.Set(echo.HeaderXForwardedFor, forwardedFor[0])
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	t.Run("when success pass the body through to the handler", func(t *testing.T) {
+		e := newRateLimitedEcho(ratelimit.NewMemoryStore())
+
+		body := `{"phone_number": "+62345678901"}`
+		rec := serveRateLimited(e, "/login", "192.0.2.1:1234", body)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, body, rec.Body.String())
+	})
+
+	t.Run("when error the body is too large to read", func(t *testing.T) {
+		e := newRateLimitedEcho(ratelimit.NewMemoryStore())
+
+		body := `{"phone_number": "+62345678901", "password": "` + strings.Repeat("x", maxRateLimitedBodyBytes) + `"}`
+		rec := serveRateLimited(e, "/login", "192.0.2.1:1234", body)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	})
+
+	t.Run("when error the client ip spent its burst", func(t *testing.T) {
+		e := newRateLimitedEcho(ratelimit.NewMemoryStore())
+
+		for _, phoneNumber := range []string{"+62345678901", "+62345678902"} {
+			rec := serveRateLimited(e, "/login", "192.0.2.1:1234", `{"phone_number": "`+phoneNumber+`"}`)
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+
+		rec := serveRateLimited(e, "/login", "192.0.2.1:1234", `{"phone_number": "+62345678903"}`)
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+	})
+
+	t.Run("when error rotating X-Forwarded-For does not get a fresh bucket", func(t *testing.T) {
+		e := newRateLimitedEcho(ratelimit.NewMemoryStore())
+
+		for i, phoneNumber := range []string{"+62345678901", "+62345678902", "+62345678903"} {
+			rec := serveRateLimited(e, "/login", "192.0.2.1:1234", `{"phone_number": "`+phoneNumber+`"}`, fmt.Sprintf("203.0.113.%d", i))
+			if i < 2 {
+				assert.Equal(t, http.StatusOK, rec.Code)
+			} else {
+				assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+			}
+		}
+	})
+
+	t.Run("when error the phone number spent its burst from several ips", func(t *testing.T) {
+		e := newRateLimitedEcho(ratelimit.NewMemoryStore())
+
+		for _, remoteAddr := range []string{"192.0.2.1:1234", "192.0.2.2:1234"} {
+			rec := serveRateLimited(e, "/login", remoteAddr, `{"phone_number": "+62345678901"}`)
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+
+		rec := serveRateLimited(e, "/login", "192.0.2.3:1234", `{"phone_number": "+62345678901"}`)
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	})
+
+	t.Run("when success routes without a limit are not counted", func(t *testing.T) {
+		e := newRateLimitedEcho(ratelimit.NewMemoryStore())
+
+		for i := 0; i < 5; i++ {
+			rec := serveRateLimited(e, "/register", "192.0.2.1:1234", `{"phone_number": "+62345678901"}`)
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+	})
+
+	t.Run("when success the store fails and the request is let through", func(t *testing.T) {
+		e := newRateLimitedEcho(failingRateLimitStore{})
+
+		rec := serveRateLimited(e, "/login", "192.0.2.1:1234", `{"phone_number": "+62345678901"}`)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// routeGuard is middleware for the routes under Prefix, limited to Methods
+// when any are given.
+type routeGuard struct {
+	Prefix     string
+	Methods    []string
+	Middleware []echo.MiddlewareFunc
+}
+
+func (g routeGuard) matches(method string, path string) bool {
+	if path != g.Prefix && !strings.HasPrefix(path, g.Prefix+"/") {
+		return false
+	}
+	return len(g.Methods) == 0 || slices.Contains(g.Methods, method)
+}
+
+// guardedRouter registers the generated routes with the middleware of every
+// guard that matches them, so each route exists once and only behind its
+// guards. Trailing slashes are dropped to match RemoveTrailingSlash.
+type guardedRouter struct {
+	*echo.Echo
+	guards []routeGuard
+}
+
+func (r *guardedRouter) add(method string, path string, h echo.HandlerFunc, m []echo.MiddlewareFunc) *echo.Route {
+	if path != "/" {
+		path = strings.TrimSuffix(path, "/")
+	}
+
+	var guarded []echo.MiddlewareFunc
+	for _, guard := range r.guards {
+		if guard.matches(method, path) {
+			guarded = append(guarded, guard.Middleware...)
+		}
+	}
+	return r.Echo.Add(method, path, h, append(guarded, m...)...)
+}
+
+func (r *guardedRouter) DELETE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(echo.DELETE, path, h, m)
+}
+
+func (r *guardedRouter) GET(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(echo.GET, path, h, m)
+}
+
+func (r *guardedRouter) PATCH(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(echo.PATCH, path, h, m)
+}
+
+func (r *guardedRouter) POST(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(echo.POST, path, h, m)
+}
+
+func (r *guardedRouter) PUT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(echo.PUT, path, h, m)
+}
+
+// RegisterRoutes serves the API on e. Routes under the prefixes below need a
+// token; everything else is public.
+func (s *Server) RegisterRoutes(e *echo.Echo) {
+	jwtMiddleware := JWTMiddleware(s.Config, s.KeyRing, s.TokenRevocations)
+	signedInUser := []echo.MiddlewareFunc{jwtMiddleware, RequireUserPrincipal}
+
+	guards := []routeGuard{
+		{Prefix: "/users", Middleware: signedInUser},
+		{Prefix: "/logout", Middleware: signedInUser},
+		{Prefix: "/userinfo", Middleware: signedInUser},
+		// Staff manage other accounts with the permissions their roles grant,
+		// backend jobs with the scopes their client was granted.
+		{Prefix: "/admin/users", Middleware: []echo.MiddlewareFunc{jwtMiddleware}},
+		{Prefix: "/admin/users", Methods: []string{echo.GET}, Middleware: []echo.MiddlewareFunc{RequirePermissionOrScope("users:read")}},
+		{Prefix: "/admin/users", Methods: []string{echo.PUT, echo.POST, echo.DELETE}, Middleware: []echo.MiddlewareFunc{RequirePermissionOrScope("users:write")}},
+		// The first-party login UI calls the authorize endpoints on behalf of
+		// the signed in user; /oauth/token stays public for the clients.
+		{Prefix: "/oauth/authorize", Middleware: signedInUser},
+	}
+
+	e.Pre(middleware.RemoveTrailingSlash())
+	generated.RegisterHandlers(&guardedRouter{Echo: e, guards: guards}, s)
+}
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SawitProRecruitment/UserService/config"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/tools"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterRoutes(t *testing.T) {
+	keyRing, err := tools.NewKeyRing(tools.KeyRingOptions{PrivateKey: tools.MockRSAPrivateKey()})
+	assert.NoError(t, err)
+	mockUser := MockUser()
+
+	newRoutedEcho := func(ctrl *gomock.Controller) (*echo.Echo, *repository.MockRepositoryInterface, *repository.MockTokenRevocationRepositoryInterface) {
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRevocations := repository.NewMockTokenRevocationRepositoryInterface(ctrl)
+		s := &Server{
+			Repository:       mockRepo,
+			TokenRevocations: mockRevocations,
+			KeyRing:          keyRing,
+			Config:           config.Config{RSAPrivateKey: tools.MockRSAPrivateKey()},
+		}
+
+		e := echo.New()
+		s.RegisterRoutes(e)
+		return e, mockRepo, mockRevocations
+	}
+
+	serve := func(e *echo.Echo, method string, url string, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, nil)
+		if token != "" {
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("when error the account routes are called without a token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		e, _, _ := newRoutedEcho(ctrl)
+		for _, route := range []struct{ method, url string }{
+			{http.MethodGet, "/users"},
+			{http.MethodGet, "/users/"},
+			{http.MethodPut, "/users/"},
+			{http.MethodDelete, "/users/"},
+			{http.MethodDelete, "/users"},
+			{http.MethodPut, "/users/password"},
+			{http.MethodGet, "/admin/users/"},
+			{http.MethodPost, "/logout"},
+		} {
+			rec := serve(e, route.method, route.url, "")
+			assert.Equal(t, http.StatusUnauthorized, rec.Code, route.method+" "+route.url)
+		}
+	})
+
+	t.Run("when success a trailing slash reaches the same handler", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		token, _, err := tools.GenerateJWTToken(tools.GenerateJWTTokenParams{GUID: mockUser.GUID, FullName: mockUser.FullName}, 1, tools.MockRSAPrivateKey())
+		assert.NoError(t, err)
+
+		e, mockRepo, mockRevocations := newRoutedEcho(ctrl)
+		mockRevocations.EXPECT().IsAccessTokenRevoked(gomock.Any(), gomock.Any()).Return(false, nil)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), mockUser.GUID).Return(mockUser, nil)
+
+		rec := serve(e, http.MethodGet, "/users/", token)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("when error staff routes need the permission", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		token, _, err := tools.GenerateJWTToken(tools.GenerateJWTTokenParams{GUID: mockUser.GUID, Permissions: []string{"users:read"}}, 1, tools.MockRSAPrivateKey())
+		assert.NoError(t, err)
+
+		e, _, mockRevocations := newRoutedEcho(ctrl)
+		mockRevocations.EXPECT().IsAccessTokenRevoked(gomock.Any(), gomock.Any()).Return(false, nil)
+
+		rec := serve(e, http.MethodDelete, "/admin/users/"+mockUser.GUID.String(), token)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("when success a machine client reads users with its scope", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		token, _, err := tools.GenerateJWTToken(tools.GenerateJWTTokenParams{ClientID: "billing-job", Scope: "users:read"}, 1, tools.MockRSAPrivateKey())
+		assert.NoError(t, err)
+
+		e, mockRepo, mockRevocations := newRoutedEcho(ctrl)
+		mockRevocations.EXPECT().IsAccessTokenRevoked(gomock.Any(), gomock.Any()).Return(false, nil)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), mockUser.GUID).Return(mockUser, nil)
+		mockRepo.EXPECT().GetUserAccess(gomock.Any(), mockUser.ID).Return(repository.UserAccess{}, nil)
+		mockRepo.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Return(nil)
+
+		rec := serve(e, http.MethodGet, "/admin/users/"+mockUser.GUID.String(), token)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("when error a machine client lacks the scope", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		token, _, err := tools.GenerateJWTToken(tools.GenerateJWTTokenParams{ClientID: "billing-job", Scope: "users:read"}, 1, tools.MockRSAPrivateKey())
+		assert.NoError(t, err)
+
+		e, _, mockRevocations := newRoutedEcho(ctrl)
+		mockRevocations.EXPECT().IsAccessTokenRevoked(gomock.Any(), gomock.Any()).Return(false, nil).Times(2)
+
+		rec := serve(e, http.MethodDelete, "/admin/users/"+mockUser.GUID.String(), token)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+
+		rec = serve(e, http.MethodGet, "/users", token)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("when success public routes need no token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		e, _, _ := newRoutedEcho(ctrl)
+		rec := serve(e, http.MethodGet, "/password-policy", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
@@ -3,21 +3,35 @@ package handler
 import (
 	"github.com/SawitProRecruitment/UserService/config"
 	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/sms"
+	"github.com/SawitProRecruitment/UserService/tools"
 )
 
 type Server struct {
-	Repository repository.RepositoryInterface
-	Config     config.Config
+	Repository       repository.RepositoryInterface
+	TokenRevocations repository.TokenRevocationRepositoryInterface
+	KeyRing          *tools.KeyRing
+	PasswordHasher   *tools.PasswordHasher
+	SMSSender        sms.SMSSender
+	Config           config.Config
 }
 
 type NewServerOptions struct {
-	Repository repository.RepositoryInterface
-	Config     config.Config
+	Repository       repository.RepositoryInterface
+	TokenRevocations repository.TokenRevocationRepositoryInterface
+	KeyRing          *tools.KeyRing
+	PasswordHasher   *tools.PasswordHasher
+	SMSSender        sms.SMSSender
+	Config           config.Config
 }
 
 func NewServer(opts NewServerOptions) *Server {
 	return &Server{
-		Repository: opts.Repository,
-		Config:     opts.Config,
+		Repository:       opts.Repository,
+		TokenRevocations: opts.TokenRevocations,
+		KeyRing:          opts.KeyRing,
+		PasswordHasher:   opts.PasswordHasher,
+		SMSSender:        opts.SMSSender,
+		Config:           opts.Config,
 	}
 }
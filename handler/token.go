@@ -0,0 +1,279 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/tools"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	errRefreshTokenRevoked = errors.New("refresh token has been revoked")
+	errRefreshTokenExpired = errors.New("refresh token has expired")
+	errRefreshTokenReused  = errors.New("refresh token reuse detected, please login again")
+)
+
+type tokenOwner struct {
+	ID          int
+	GUID        uuid.UUID
+	FullName    string
+	PhoneNumber string
+
+	// OAuthClient and Scope are set when the pair is issued to a third-party
+	// client, which only sees the claims its scope allows.
+	OAuthClient *repository.OAuthClient
+	Scope       string
+	// Nonce is carried from the authorization request into the ID token.
+	Nonce string
+
+	// Access is embedded in first-party tokens so route groups can check
+	// permissions without a lookup.
+	Access repository.UserAccess
+}
+
+// accessTokenParams fills the claims every access token carries for owner.
+func (s *Server) accessTokenParams(owner tokenOwner) tools.GenerateJWTTokenParams {
+	params := tools.GenerateJWTTokenParams{
+		FullName:    owner.FullName,
+		GUID:        owner.GUID,
+		PhoneNumber: owner.PhoneNumber,
+		Roles:       owner.Access.Roles,
+		Permissions: owner.Access.Permissions,
+		Issuer:      tokenIssuer(s.Config),
+	}
+	if s.Config.JWTAudience != "" {
+		params.Audience = []string{s.Config.JWTAudience}
+	}
+	if owner.OAuthClient != nil {
+		params.ClientID = owner.OAuthClient.ClientID
+		params.Scope = owner.Scope
+		params.Roles = nil
+		params.Permissions = nil
+		if !hasScope(owner.Scope, oauthScopePhone) {
+			params.PhoneNumber = ""
+		}
+	}
+	return params
+}
+
+// issueTokenPair signs a new access token for the owner and stores a fresh
+// refresh token in the given family. Pass uuid.Nil to start a new family.
+// Roles are looked up for every first-party pair, so a change takes effect on
+// the next refresh at the latest.
+func (s *Server) issueTokenPair(ctx context.Context, owner tokenOwner, familyID uuid.UUID) (resp generated.SuccessLoginUserResponse, err error) {
+	if owner.OAuthClient == nil {
+		owner.Access, err = s.Repository.GetUserAccess(ctx, owner.ID)
+		if err != nil {
+			return resp, err
+		}
+	}
+
+	token, expiredAt, err := tools.GenerateJWTToken(s.accessTokenParams(owner), s.Config.JWTTokenLifetimeInHours, s.Config.RSAPrivateKey)
+	if err != nil {
+		return resp, &tools.Err{Code: http.StatusBadRequest, Message: err.Error()}
+	}
+
+	refreshToken, refreshTokenHash, err := tools.GenerateOpaqueToken()
+	if err != nil {
+		return resp, &tools.Err{Code: http.StatusInternalServerError, Message: err.Error()}
+	}
+
+	if familyID == uuid.Nil {
+		familyID = uuid.New()
+	}
+
+	stored := &repository.RefreshToken{
+		UserID:    owner.ID,
+		FamilyID:  familyID,
+		TokenHash: refreshTokenHash,
+		ExpiresAt: time.Now().UTC().Add(time.Duration(s.Config.RefreshTokenLifetimeInHours) * time.Hour),
+	}
+	if owner.OAuthClient != nil {
+		stored.OAuthClientID = &owner.OAuthClient.ID
+		stored.Scope = owner.Scope
+	}
+	err = s.Repository.CreateRefreshToken(ctx, stored)
+	if err != nil {
+		return resp, err
+	}
+
+	return generated.SuccessLoginUserResponse{
+		Token:                 token,
+		ExpiredAt:             expiredAt.String(),
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiredAt: stored.ExpiresAt.String(),
+	}, nil
+}
+
+func (s *Server) RefreshToken(ctx echo.Context) error {
+	rCtx := ctx.Request().Context()
+	var input generated.RefreshTokenJSONRequestBody
+	var errData *tools.Err
+
+	err := ctx.Bind(&input)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	err = tools.ValidateRequestPayload(input)
+	if err != nil && errors.As(err, &errData) {
+		return ctx.JSON(errData.Code, generated.ErrorWithExtraResponse{Message: errData.Message, Extra: &errData.Extra})
+	}
+
+	stored, err := s.Repository.GetRefreshTokenByHash(rCtx, tools.HashOpaqueToken(input.RefreshToken))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ctx.JSON(http.StatusUnauthorized, generated.ErrorResponse{Message: "invalid refresh token"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	// Tokens issued to OAuth2 clients can only be rotated by the same client
+	// through the token endpoint.
+	if stored.OAuthClientID != nil {
+		return ctx.JSON(http.StatusUnauthorized, generated.ErrorResponse{Message: "invalid refresh token"})
+	}
+
+	err = s.consumeRefreshToken(rCtx, stored)
+	if err != nil {
+		if err == errRefreshTokenRevoked || err == errRefreshTokenExpired || err == errRefreshTokenReused {
+			return ctx.JSON(http.StatusUnauthorized, generated.ErrorResponse{Message: err.Error()})
+		}
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	owner := tokenOwner{
+		ID:          stored.UserID,
+		GUID:        stored.UserGUID,
+		FullName:    stored.FullName,
+		PhoneNumber: stored.PhoneNumber,
+	}
+	resp, err := s.issueTokenPair(rCtx, owner, stored.FamilyID)
+	if err != nil {
+		if errors.As(err, &errData) {
+			return ctx.JSON(errData.Code, generated.ErrorResponse{Message: errData.Message})
+		}
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+	return ctx.JSON(http.StatusOK, resp)
+}
+
+// consumeRefreshToken marks the token as used so it can be rotated once. A
+// token presented again after being rotated gets its whole family revoked,
+// since either the legitimate client or an attacker holds a stolen copy and
+// there is no way to tell which.
+func (s *Server) consumeRefreshToken(ctx context.Context, stored *repository.RefreshToken) error {
+	if stored.RevokedAt != nil {
+		return errRefreshTokenRevoked
+	}
+
+	if stored.UsedAt != nil {
+		return s.revokeReusedRefreshToken(ctx, stored)
+	}
+
+	if time.Now().UTC().After(stored.ExpiresAt) {
+		return errRefreshTokenExpired
+	}
+
+	marked, err := s.Repository.MarkRefreshTokenUsed(ctx, stored.ID)
+	if err != nil {
+		return err
+	}
+	if !marked {
+		// Another request consumed the token between the lookup and the update.
+		return s.revokeReusedRefreshToken(ctx, stored)
+	}
+	return nil
+}
+
+func (s *Server) revokeReusedRefreshToken(ctx context.Context, stored *repository.RefreshToken) error {
+	err := s.Repository.RevokeRefreshTokenFamily(ctx, stored.FamilyID)
+	if err != nil {
+		return err
+	}
+	return errRefreshTokenReused
+}
+
+func (s *Server) Logout(ctx echo.Context) error {
+	rCtx := ctx.Request().Context()
+	guid := uuid.MustParse(ctx.Get("UserGUID").(string))
+
+	var input generated.LogoutJSONRequestBody
+	err := ctx.Bind(&input)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	jti := ctx.Get("TokenID").(string)
+	if jti == "" {
+		// Tokens issued before jti was introduced cannot be revoked one by one.
+		// This one may carry the current second as its iat, so the cut-off is
+		// the start of the next second.
+		err = s.TokenRevocations.RevokeUserAccessTokens(rCtx, guid, userRevocationCutoff().Add(time.Second))
+	} else {
+		err = s.TokenRevocations.RevokeAccessToken(rCtx, repository.RevokedAccessToken{
+			JTI:       jti,
+			UserGUID:  guid,
+			ExpiresAt: ctx.Get("TokenExpiresAt").(time.Time),
+		})
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	if input.RefreshToken != nil && *input.RefreshToken != "" {
+		stored, err := s.Repository.GetRefreshTokenByHash(rCtx, tools.HashOpaqueToken(*input.RefreshToken))
+		if err != nil && err != sql.ErrNoRows {
+			return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+		}
+
+		// A refresh token that belongs to someone else is ignored rather than
+		// reported, so logout cannot be used to probe for valid tokens.
+		if err == nil && stored.UserGUID == guid {
+			err = s.Repository.RevokeRefreshTokenFamily(rCtx, stored.FamilyID)
+			if err != nil {
+				return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+			}
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, generated.DefaultUpdateResponse{Message: "logged out successfully"})
+}
+
+func (s *Server) LogoutAllDevices(ctx echo.Context) error {
+	rCtx := ctx.Request().Context()
+	guid := uuid.MustParse(ctx.Get("UserGUID").(string))
+
+	err := s.revokeAllUserTokens(rCtx, guid)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, generated.DefaultUpdateResponse{Message: "logged out from all devices successfully"})
+}
+
+// userRevocationCutoff is the start of the current second. Access tokens only
+// carry their issue time to the second, so user-wide revocations are cut off
+// in whole seconds as well.
+func userRevocationCutoff() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
+
+// revokeAllUserTokens invalidates every access token issued to the user so far
+// and every refresh token they still hold. Tokens from the current second are
+// spared, otherwise the token from logging straight back in would be caught
+// too.
+func (s *Server) revokeAllUserTokens(ctx context.Context, guid uuid.UUID) error {
+	err := s.TokenRevocations.RevokeUserAccessTokens(ctx, guid, userRevocationCutoff())
+	if err != nil {
+		return err
+	}
+	return s.Repository.RevokeUserRefreshTokens(ctx, guid)
+}
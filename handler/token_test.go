@@ -0,0 +1,587 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/config"
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/tools"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func MockRefreshToken() *repository.RefreshToken {
+	return &repository.RefreshToken{
+		ID:        1,
+		UserID:    1,
+		FamilyID:  uuid.New(),
+		TokenHash: tools.HashOpaqueToken("refresh-token"),
+		ExpiresAt: time.Now().UTC().Add(time.Hour),
+		CreatedAt: time.Now().UTC(),
+		UserGUID:  uuid.New(),
+		FullName:  "SawitPro Mania",
+	}
+}
+
+func TestRefreshToken(t *testing.T) {
+	mockConfig := &config.Config{
+		RSAPrivateKey:               tools.MockRSAPrivateKey(),
+		JWTTokenLifetimeInHours:     8,
+		RefreshTokenLifetimeInHours: 720,
+	}
+
+	t.Run("when success rotate refresh token", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		stored := MockRefreshToken()
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetRefreshTokenByHash(gomock.Any(), tools.HashOpaqueToken("refresh-token")).Return(stored, nil)
+		mockRepo.EXPECT().MarkRefreshTokenUsed(gomock.Any(), stored.ID).Return(true, nil)
+		mockRepo.EXPECT().GetUserAccess(gomock.Any(), gomock.Any()).Return(repository.UserAccess{}, nil)
+		mockRepo.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ interface{}, token *repository.RefreshToken) error {
+				assert.Equal(t, stored.FamilyID, token.FamilyID)
+				assert.Equal(t, stored.UserID, token.UserID)
+				assert.NotEqual(t, stored.TokenHash, token.TokenHash)
+				return nil
+			},
+		)
+
+		reqParam := testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/token/refresh",
+			body:       []byte(`{"refresh_token": "refresh-token"}`),
+		}
+		ctx, rec := TestRequestEndpoint(reqParam)
+
+		s := &Server{
+			Repository: mockRepo,
+			Config:     *mockConfig,
+		}
+
+		err := s.RefreshToken(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var resp generated.SuccessLoginUserResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.NotEmpty(t, resp.Token)
+		assert.NotEmpty(t, resp.RefreshToken)
+		assert.NotEqual(t, "refresh-token", resp.RefreshToken)
+	})
+
+	t.Run("when success embed the user's roles and permissions", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		stored := MockRefreshToken()
+		access := repository.UserAccess{Roles: []string{"admin"}, Permissions: []string{"users:read", "users:write"}}
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetRefreshTokenByHash(gomock.Any(), tools.HashOpaqueToken("refresh-token")).Return(stored, nil)
+		mockRepo.EXPECT().MarkRefreshTokenUsed(gomock.Any(), stored.ID).Return(true, nil)
+		mockRepo.EXPECT().GetUserAccess(gomock.Any(), stored.UserID).Return(access, nil)
+		mockRepo.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Return(nil)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/token/refresh",
+			body:       []byte(`{"refresh_token": "refresh-token"}`),
+		})
+
+		s := &Server{
+			Repository: mockRepo,
+			Config:     *mockConfig,
+		}
+
+		err := s.RefreshToken(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var resp generated.SuccessLoginUserResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		claims := new(tools.JWTCustomClaims)
+		_, _, err = jwt.NewParser().ParseUnverified(resp.Token, claims)
+		assert.NoError(t, err)
+		assert.Equal(t, access.Roles, claims.Roles)
+		assert.Equal(t, access.Permissions, claims.Permissions)
+	})
+}
+
+func TestRefreshToken_Error(t *testing.T) {
+	mockConfig := &config.Config{
+		RSAPrivateKey:               tools.MockRSAPrivateKey(),
+		JWTTokenLifetimeInHours:     8,
+		RefreshTokenLifetimeInHours: 720,
+	}
+	successReqBody := []byte(`{"refresh_token": "refresh-token"}`)
+
+	t.Run("when error binding request body", func(t *testing.T) {
+		e := echo.New()
+		reqParam := testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/token/refresh",
+			body:       []byte("invalid json"),
+		}
+		ctx, rec := TestRequestEndpoint(reqParam)
+
+		s := &Server{}
+
+		_ = s.RefreshToken(ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("when error validate request body", func(t *testing.T) {
+		e := echo.New()
+		reqParam := testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/token/refresh",
+			body:       []byte(`{}`),
+		}
+		ctx, rec := TestRequestEndpoint(reqParam)
+
+		s := &Server{}
+
+		_ = s.RefreshToken(ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("when error due to refresh token is not found", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetRefreshTokenByHash(gomock.Any(), gomock.Any()).Return(nil, sql.ErrNoRows)
+
+		reqParam := testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/token/refresh",
+			body:       successReqBody,
+		}
+		ctx, rec := TestRequestEndpoint(reqParam)
+
+		s := &Server{Repository: mockRepo, Config: *mockConfig}
+
+		_ = s.RefreshToken(ctx)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("when error to get refresh token", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetRefreshTokenByHash(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("error db"))
+
+		reqParam := testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/token/refresh",
+			body:       successReqBody,
+		}
+		ctx, rec := TestRequestEndpoint(reqParam)
+
+		s := &Server{Repository: mockRepo, Config: *mockConfig}
+
+		_ = s.RefreshToken(ctx)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+
+	t.Run("when error due to refresh token is expired", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		stored := MockRefreshToken()
+		stored.ExpiresAt = time.Now().UTC().Add(-time.Minute)
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetRefreshTokenByHash(gomock.Any(), gomock.Any()).Return(stored, nil)
+
+		reqParam := testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/token/refresh",
+			body:       successReqBody,
+		}
+		ctx, rec := TestRequestEndpoint(reqParam)
+
+		s := &Server{Repository: mockRepo, Config: *mockConfig}
+
+		_ = s.RefreshToken(ctx)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("when error due to refresh token was issued to an oauth client", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		stored := MockRefreshToken()
+		oauthClientID := 1
+		stored.OAuthClientID = &oauthClientID
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetRefreshTokenByHash(gomock.Any(), gomock.Any()).Return(stored, nil)
+
+		reqParam := testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/token/refresh",
+			body:       successReqBody,
+		}
+		ctx, rec := TestRequestEndpoint(reqParam)
+
+		s := &Server{Repository: mockRepo, Config: *mockConfig}
+
+		_ = s.RefreshToken(ctx)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("when error due to refresh token is revoked", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		stored := MockRefreshToken()
+		revokedAt := time.Now().UTC()
+		stored.RevokedAt = &revokedAt
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetRefreshTokenByHash(gomock.Any(), gomock.Any()).Return(stored, nil)
+
+		reqParam := testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/token/refresh",
+			body:       successReqBody,
+		}
+		ctx, rec := TestRequestEndpoint(reqParam)
+
+		s := &Server{Repository: mockRepo, Config: *mockConfig}
+
+		_ = s.RefreshToken(ctx)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("when refresh token is reused then the family is revoked", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		stored := MockRefreshToken()
+		usedAt := time.Now().UTC()
+		stored.UsedAt = &usedAt
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetRefreshTokenByHash(gomock.Any(), gomock.Any()).Return(stored, nil)
+		mockRepo.EXPECT().RevokeRefreshTokenFamily(gomock.Any(), stored.FamilyID).Return(nil)
+
+		reqParam := testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/token/refresh",
+			body:       successReqBody,
+		}
+		ctx, rec := TestRequestEndpoint(reqParam)
+
+		s := &Server{Repository: mockRepo, Config: *mockConfig}
+
+		_ = s.RefreshToken(ctx)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("when refresh token is consumed concurrently then the family is revoked", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		stored := MockRefreshToken()
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetRefreshTokenByHash(gomock.Any(), gomock.Any()).Return(stored, nil)
+		mockRepo.EXPECT().MarkRefreshTokenUsed(gomock.Any(), stored.ID).Return(false, nil)
+		mockRepo.EXPECT().RevokeRefreshTokenFamily(gomock.Any(), stored.FamilyID).Return(nil)
+
+		reqParam := testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/token/refresh",
+			body:       successReqBody,
+		}
+		ctx, rec := TestRequestEndpoint(reqParam)
+
+		s := &Server{Repository: mockRepo, Config: *mockConfig}
+
+		_ = s.RefreshToken(ctx)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("when error to store rotated refresh token", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		stored := MockRefreshToken()
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetRefreshTokenByHash(gomock.Any(), gomock.Any()).Return(stored, nil)
+		mockRepo.EXPECT().MarkRefreshTokenUsed(gomock.Any(), stored.ID).Return(true, nil)
+		mockRepo.EXPECT().GetUserAccess(gomock.Any(), gomock.Any()).Return(repository.UserAccess{}, nil)
+		mockRepo.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Return(fmt.Errorf("error db"))
+
+		reqParam := testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/token/refresh",
+			body:       successReqBody,
+		}
+		ctx, rec := TestRequestEndpoint(reqParam)
+
+		s := &Server{Repository: mockRepo, Config: *mockConfig}
+
+		_ = s.RefreshToken(ctx)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestLogout(t *testing.T) {
+	mockUser := MockUser()
+
+	t.Run("when success logout with refresh token", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		stored := MockRefreshToken()
+		stored.UserGUID = mockUser.GUID
+		expiresAt := time.Now().UTC().Add(time.Hour)
+
+		mockRevocations := repository.NewMockTokenRevocationRepositoryInterface(ctrl)
+		mockRevocations.EXPECT().RevokeAccessToken(gomock.Any(), repository.RevokedAccessToken{
+			JTI:       "token-id",
+			UserGUID:  mockUser.GUID,
+			ExpiresAt: expiresAt,
+		}).Return(nil)
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetRefreshTokenByHash(gomock.Any(), tools.HashOpaqueToken("refresh-token")).Return(stored, nil)
+		mockRepo.EXPECT().RevokeRefreshTokenFamily(gomock.Any(), stored.FamilyID).Return(nil)
+
+		reqParam := testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/logout",
+			body:       []byte(`{"refresh_token": "refresh-token"}`),
+		}
+		ctx, rec := TestRequestEndpoint(reqParam)
+		ctx.Set("UserGUID", mockUser.GUID.String())
+		ctx.Set("TokenID", "token-id")
+		ctx.Set("TokenExpiresAt", expiresAt)
+
+		s := &Server{Repository: mockRepo, TokenRevocations: mockRevocations}
+
+		err := s.Logout(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("when refresh token belongs to another user then it is left untouched", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		stored := MockRefreshToken()
+		mockRevocations := repository.NewMockTokenRevocationRepositoryInterface(ctrl)
+		mockRevocations.EXPECT().RevokeAccessToken(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetRefreshTokenByHash(gomock.Any(), gomock.Any()).Return(stored, nil)
+
+		reqParam := testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/logout",
+			body:       []byte(`{"refresh_token": "refresh-token"}`),
+		}
+		ctx, rec := TestRequestEndpoint(reqParam)
+		ctx.Set("UserGUID", mockUser.GUID.String())
+		ctx.Set("TokenID", "token-id")
+		ctx.Set("TokenExpiresAt", time.Now().UTC())
+
+		s := &Server{Repository: mockRepo, TokenRevocations: mockRevocations}
+
+		_ = s.Logout(ctx)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("when token has no jti then every token of the user is revoked", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		// The token being logged out may have been issued this very second.
+		issuedAt := time.Now().UTC().Truncate(time.Second)
+		mockRevocations := repository.NewMockTokenRevocationRepositoryInterface(ctrl)
+		mockRevocations.EXPECT().RevokeUserAccessTokens(gomock.Any(), mockUser.GUID, gomock.Any()).DoAndReturn(
+			func(_ interface{}, _ uuid.UUID, before time.Time) error {
+				// Tokens count as revoked when revoked_before > iat.
+				assert.True(t, before.After(issuedAt), "revoked before %s, issued at %s", before, issuedAt)
+				assert.Equal(t, before, before.Truncate(time.Second))
+				return nil
+			},
+		)
+
+		reqParam := testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/logout",
+		}
+		ctx, rec := TestRequestEndpoint(reqParam)
+		ctx.Set("UserGUID", mockUser.GUID.String())
+		ctx.Set("TokenID", "")
+		ctx.Set("TokenExpiresAt", time.Now().UTC())
+
+		s := &Server{TokenRevocations: mockRevocations}
+
+		_ = s.Logout(ctx)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
+
+func TestLogout_Error(t *testing.T) {
+	mockUser := MockUser()
+
+	t.Run("when error binding request body", func(t *testing.T) {
+		e := echo.New()
+		reqParam := testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/logout",
+			body:       []byte("invalid json"),
+		}
+		ctx, rec := TestRequestEndpoint(reqParam)
+		ctx.Set("UserGUID", mockUser.GUID.String())
+
+		s := &Server{}
+
+		_ = s.Logout(ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("when error to revoke access token", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRevocations := repository.NewMockTokenRevocationRepositoryInterface(ctrl)
+		mockRevocations.EXPECT().RevokeAccessToken(gomock.Any(), gomock.Any()).Return(fmt.Errorf("error db"))
+
+		reqParam := testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/logout",
+		}
+		ctx, rec := TestRequestEndpoint(reqParam)
+		ctx.Set("UserGUID", mockUser.GUID.String())
+		ctx.Set("TokenID", "token-id")
+		ctx.Set("TokenExpiresAt", time.Now().UTC())
+
+		s := &Server{TokenRevocations: mockRevocations}
+
+		_ = s.Logout(ctx)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestLogoutAllDevices(t *testing.T) {
+	mockUser := MockUser()
+
+	t.Run("when success logout from all devices", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRevocations := repository.NewMockTokenRevocationRepositoryInterface(ctrl)
+		mockRevocations.EXPECT().RevokeUserAccessTokens(gomock.Any(), mockUser.GUID, gomock.Any()).Return(nil)
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().RevokeUserRefreshTokens(gomock.Any(), mockUser.GUID).Return(nil)
+
+		reqParam := testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/logout/all",
+		}
+		ctx, rec := TestRequestEndpoint(reqParam)
+		ctx.Set("UserGUID", mockUser.GUID.String())
+
+		s := &Server{Repository: mockRepo, TokenRevocations: mockRevocations}
+
+		err := s.LogoutAllDevices(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("when success a token issued in the same second afterwards stays valid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var revokedBefore time.Time
+		mockRevocations := repository.NewMockTokenRevocationRepositoryInterface(ctrl)
+		mockRevocations.EXPECT().RevokeUserAccessTokens(gomock.Any(), mockUser.GUID, gomock.Any()).DoAndReturn(
+			func(_ interface{}, _ uuid.UUID, before time.Time) error {
+				revokedBefore = before
+				return nil
+			},
+		)
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().RevokeUserRefreshTokens(gomock.Any(), mockUser.GUID).Return(nil)
+
+		s := &Server{Repository: mockRepo, TokenRevocations: mockRevocations}
+		assert.NoError(t, s.revokeAllUserTokens(context.Background(), mockUser.GUID))
+
+		token, _, err := tools.GenerateJWTToken(tools.GenerateJWTTokenParams{GUID: mockUser.GUID}, 8, tools.MockRSAPrivateKey())
+		assert.NoError(t, err)
+		claims := &tools.JWTCustomClaims{}
+		_, _, err = jwt.NewParser().ParseUnverified(token, claims)
+		assert.NoError(t, err)
+
+		// Tokens count as revoked when revoked_before > iat.
+		assert.False(t, revokedBefore.After(claims.IssuedAt.Time), "revoked before %s, issued at %s", revokedBefore, claims.IssuedAt.Time)
+	})
+
+	t.Run("when error to revoke refresh tokens", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRevocations := repository.NewMockTokenRevocationRepositoryInterface(ctrl)
+		mockRevocations.EXPECT().RevokeUserAccessTokens(gomock.Any(), mockUser.GUID, gomock.Any()).Return(nil)
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().RevokeUserRefreshTokens(gomock.Any(), mockUser.GUID).Return(fmt.Errorf("error db"))
+
+		reqParam := testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/logout/all",
+		}
+		ctx, rec := TestRequestEndpoint(reqParam)
+		ctx.Set("UserGUID", mockUser.GUID.String())
+
+		s := &Server{Repository: mockRepo, TokenRevocations: mockRevocations}
+
+		_ = s.LogoutAllDevices(ctx)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
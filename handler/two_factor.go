@@ -0,0 +1,334 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/tools"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	totpRecoveryCodeCount = 10
+	// defaultTOTPIssuer labels the entry in authenticator apps when
+	// TOTP_ISSUER is not set.
+	defaultTOTPIssuer = "UserService"
+)
+
+var (
+	errInvalidTwoFactorCode    = errors.New("invalid two-factor code")
+	errInvalidLoginChallenge   = errors.New("invalid or expired login challenge")
+	errTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	errNoTwoFactorEnrollment   = errors.New("no two-factor enrollment is pending")
+	errTwoFactorNotConfigured  = errors.New("two-factor authentication is not configured")
+)
+
+// encryptTOTPSecret and decryptTOTPSecret keep TOTP secrets sealed at rest
+// with TOTP_ENCRYPTION_KEY.
+func (s *Server) encryptTOTPSecret(secret string) (string, error) {
+	key, err := tools.ParseEncryptionKey(s.Config.TOTPEncryptionKey)
+	if err != nil {
+		return "", errTwoFactorNotConfigured
+	}
+	return tools.EncryptSecret(key, secret)
+}
+
+func (s *Server) decryptTOTPSecret(ciphertext string) (string, error) {
+	key, err := tools.ParseEncryptionKey(s.Config.TOTPEncryptionKey)
+	if err != nil {
+		return "", errTwoFactorNotConfigured
+	}
+	return tools.DecryptSecret(key, ciphertext)
+}
+
+// issueLoginChallenge starts the second step of a two-factor login for a user
+// whose password was just accepted. With restore set, passing it also restores
+// the user's deleted account.
+func (s *Server) issueLoginChallenge(ctx context.Context, userID int, restore bool) (resp generated.TwoFactorChallengeResponse, err error) {
+	token, tokenHash, err := tools.GenerateOpaqueToken()
+	if err != nil {
+		return resp, err
+	}
+
+	challenge := &repository.LoginChallenge{
+		UserID:         userID,
+		TokenHash:      tokenHash,
+		ExpiresAt:      time.Now().UTC().Add(time.Duration(s.Config.LoginChallengeLifetimeInSeconds) * time.Second),
+		RestoreAccount: restore,
+	}
+	err = s.Repository.CreateLoginChallenge(ctx, challenge)
+	if err != nil {
+		return resp, err
+	}
+
+	return generated.TwoFactorChallengeResponse{
+		ChallengeToken: token,
+		ExpiresAt:      challenge.ExpiresAt,
+		Message:        "enter the code from your authenticator app to finish logging in",
+	}, nil
+}
+
+// EnrollTOTP creates a new authenticator secret for the caller. It only takes
+// effect once ConfirmTOTP sees a code generated from it.
+func (s *Server) EnrollTOTP(ctx echo.Context) error {
+	rCtx := ctx.Request().Context()
+	guid := uuid.MustParse(ctx.Get("UserGUID").(string))
+
+	user, err := s.Repository.GetUserByGUID(rCtx, guid)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ctx.JSON(http.StatusNotFound, generated.ErrorResponse{Message: "user is not found"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	existing, err := s.Repository.GetUserTOTP(rCtx, user.ID)
+	if err != nil && err != sql.ErrNoRows {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+	if err == nil && existing.EnabledAt != nil {
+		return ctx.JSON(http.StatusConflict, generated.ErrorResponse{Message: errTwoFactorAlreadyEnabled.Error()})
+	}
+
+	secret, err := tools.GenerateTOTPSecret()
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+	ciphertext, err := s.encryptTOTPSecret(secret)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	err = s.Repository.UpsertPendingUserTOTP(rCtx, user.ID, ciphertext)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	issuer := s.Config.TOTPIssuer
+	if issuer == "" {
+		issuer = defaultTOTPIssuer
+	}
+	return ctx.JSON(http.StatusOK, generated.TOTPEnrollmentResponse{
+		Secret:     secret,
+		OtpauthUri: tools.TOTPURI(issuer, user.PhoneNumber, secret),
+	})
+}
+
+// ConfirmTOTP enables two-factor authentication once the caller proves their
+// authenticator app produces the right codes, and hands out recovery codes.
+func (s *Server) ConfirmTOTP(ctx echo.Context) error {
+	rCtx := ctx.Request().Context()
+	guid := uuid.MustParse(ctx.Get("UserGUID").(string))
+	var errData *tools.Err
+
+	var input generated.ConfirmTOTPJSONRequestBody
+	err := ctx.Bind(&input)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	err = tools.ValidateRequestPayload(input)
+	if err != nil && errors.As(err, &errData) {
+		return ctx.JSON(errData.Code, generated.ErrorWithExtraResponse{Message: errData.Message, Extra: &errData.Extra})
+	}
+
+	user, err := s.Repository.GetUserByGUID(rCtx, guid)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ctx.JSON(http.StatusNotFound, generated.ErrorResponse{Message: "user is not found"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	totp, err := s.Repository.GetUserTOTP(rCtx, user.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: errNoTwoFactorEnrollment.Error()})
+		}
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+	if totp.EnabledAt != nil {
+		return ctx.JSON(http.StatusConflict, generated.ErrorResponse{Message: errTwoFactorAlreadyEnabled.Error()})
+	}
+
+	secret, err := s.decryptTOTPSecret(totp.SecretCiphertext)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+	step, ok, err := tools.ValidateTOTP(secret, input.Code, time.Now(), totp.LastUsedStep)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+	if !ok {
+		return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: errInvalidTwoFactorCode.Error()})
+	}
+
+	recoveryCodes, err := tools.GenerateRecoveryCodes(totpRecoveryCodeCount)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+	recoveryCodeHashes := make([]string, len(recoveryCodes))
+	for i, code := range recoveryCodes {
+		recoveryCodeHashes[i] = tools.HashOpaqueToken(code)
+	}
+
+	enabled, err := s.Repository.EnableUserTOTP(rCtx, user.ID, step, recoveryCodeHashes)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+	if !enabled {
+		return ctx.JSON(http.StatusConflict, generated.ErrorResponse{Message: errTwoFactorAlreadyEnabled.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, generated.TOTPRecoveryCodesResponse{
+		RecoveryCodes: recoveryCodes,
+		Message:       "two-factor authentication enabled, store the recovery codes somewhere safe",
+	})
+}
+
+// LoginTwoFactor finishes a login started by LoginUser for a user with
+// two-factor authentication, accepting either a TOTP code or a recovery code.
+func (s *Server) LoginTwoFactor(ctx echo.Context) error {
+	rCtx := ctx.Request().Context()
+	var input generated.LoginTwoFactorJSONRequestBody
+	var errData *tools.Err
+
+	err := ctx.Bind(&input)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	err = tools.ValidateRequestPayload(input)
+	if err != nil && errors.As(err, &errData) {
+		return ctx.JSON(errData.Code, generated.ErrorWithExtraResponse{Message: errData.Message, Extra: &errData.Extra})
+	}
+
+	challenge, err := s.Repository.GetLoginChallengeByTokenHash(rCtx, tools.HashOpaqueToken(input.ChallengeToken))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ctx.JSON(http.StatusUnauthorized, generated.ErrorResponse{Message: errInvalidLoginChallenge.Error()})
+		}
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+	if challenge.ConsumedAt != nil || time.Now().UTC().After(challenge.ExpiresAt) {
+		return ctx.JSON(http.StatusUnauthorized, generated.ErrorResponse{Message: errInvalidLoginChallenge.Error()})
+	}
+
+	used, err := s.Repository.UseLoginChallengeAttempt(rCtx, challenge.ID, s.Config.OTPMaxAttempts)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+	if !used {
+		return ctx.JSON(http.StatusUnauthorized, generated.ErrorResponse{Message: errInvalidLoginChallenge.Error()})
+	}
+
+	if input.Code != nil {
+		err = s.checkTOTPCode(rCtx, challenge.UserID, *input.Code)
+	} else {
+		err = s.useRecoveryCode(rCtx, challenge.UserID, *input.RecoveryCode)
+	}
+	if err != nil {
+		if err != errInvalidTwoFactorCode {
+			return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+		}
+		// A wrong second factor counts like a wrong password, so whoever
+		// holds the password cannot keep asking for fresh challenges.
+		err = s.recordUserLoginFailure(rCtx, challenge.UserID)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+		}
+		return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: errInvalidTwoFactorCode.Error()})
+	}
+
+	consumed, err := s.Repository.ConsumeLoginChallenge(rCtx, challenge.ID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+	if !consumed {
+		return ctx.JSON(http.StatusUnauthorized, generated.ErrorResponse{Message: errInvalidLoginChallenge.Error()})
+	}
+
+	if challenge.RestoreAccount {
+		err = s.restoreAccount(rCtx, challenge.UserID)
+		if err != nil {
+			if errors.As(err, &errData) {
+				return ctx.JSON(errData.Code, generated.ErrorResponse{Message: errData.Message})
+			}
+			return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+		}
+	}
+
+	err = s.unlockUserLogin(rCtx, challenge.UserID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	owner := tokenOwner{
+		ID:          challenge.UserID,
+		GUID:        challenge.UserGUID,
+		FullName:    challenge.FullName,
+		PhoneNumber: challenge.PhoneNumber,
+	}
+
+	resp, err := s.issueTokenPair(rCtx, owner, uuid.Nil)
+	if err != nil {
+		if errors.As(err, &errData) {
+			return ctx.JSON(errData.Code, generated.ErrorResponse{Message: errData.Message})
+		}
+		return ctx.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return ctx.JSON(http.StatusOK, resp)
+}
+
+// checkTOTPCode accepts a code from the user's authenticator app. A code is
+// only good once, even within the window it is valid for.
+func (s *Server) checkTOTPCode(ctx context.Context, userID int, code string) error {
+	totp, err := s.Repository.GetUserTOTP(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errInvalidTwoFactorCode
+		}
+		return err
+	}
+	if totp.EnabledAt == nil {
+		return errInvalidTwoFactorCode
+	}
+
+	secret, err := s.decryptTOTPSecret(totp.SecretCiphertext)
+	if err != nil {
+		return err
+	}
+	step, ok, err := tools.ValidateTOTP(secret, code, time.Now(), totp.LastUsedStep)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errInvalidTwoFactorCode
+	}
+
+	marked, err := s.Repository.MarkUserTOTPStepUsed(ctx, userID, step)
+	if err != nil {
+		return err
+	}
+	if !marked {
+		return errInvalidTwoFactorCode
+	}
+	return nil
+}
+
+func (s *Server) useRecoveryCode(ctx context.Context, userID int, code string) error {
+	used, err := s.Repository.UseTOTPRecoveryCode(ctx, userID, tools.HashOpaqueToken(tools.NormalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return errInvalidTwoFactorCode
+	}
+	return nil
+}
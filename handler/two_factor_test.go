@@ -0,0 +1,453 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/config"
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/tools"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+const mockTOTPEncryptionKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+
+func MockTwoFactorConfig() config.Config {
+	cfg := MockOTPConfig()
+	cfg.RSAPrivateKey = tools.MockRSAPrivateKey()
+	cfg.JWTTokenLifetimeInHours = 8
+	cfg.RefreshTokenLifetimeInHours = 720
+	cfg.TOTPEncryptionKey = mockTOTPEncryptionKey
+	cfg.LoginChallengeLifetimeInSeconds = 300
+	return cfg
+}
+
+// MockUserTOTP returns an enrollment for user together with its plain secret.
+func MockUserTOTP(t *testing.T, user *repository.User, enabled bool) (*repository.UserTOTP, string) {
+	secret, err := tools.GenerateTOTPSecret()
+	assert.NoError(t, err)
+	key, err := tools.ParseEncryptionKey(mockTOTPEncryptionKey)
+	assert.NoError(t, err)
+	ciphertext, err := tools.EncryptSecret(key, secret)
+	assert.NoError(t, err)
+
+	totp := &repository.UserTOTP{UserID: user.ID, SecretCiphertext: ciphertext}
+	if enabled {
+		enabledAt := time.Now().UTC().Add(-24 * time.Hour)
+		totp.EnabledAt = &enabledAt
+	}
+	return totp, secret
+}
+
+func MockLoginChallenge(user *repository.User) *repository.LoginChallenge {
+	return &repository.LoginChallenge{
+		ID:          3,
+		UserID:      user.ID,
+		ExpiresAt:   time.Now().UTC().Add(5 * time.Minute),
+		CreatedAt:   time.Now().UTC(),
+		UserGUID:    user.GUID,
+		FullName:    user.FullName,
+		PhoneNumber: user.PhoneNumber,
+	}
+}
+
+func currentTOTPCode(t *testing.T, secret string) string {
+	code, err := tools.TOTPCode(secret, tools.TOTPStep(time.Now()))
+	assert.NoError(t, err)
+	return code
+}
+
+func TestEnrollTOTP(t *testing.T) {
+	t.Run("when success start an enrollment", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		var storedCiphertext string
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), mockUser.GUID).Return(mockUser, nil)
+		mockRepo.EXPECT().GetUserTOTP(gomock.Any(), mockUser.ID).Return(nil, sql.ErrNoRows)
+		mockRepo.EXPECT().UpsertPendingUserTOTP(gomock.Any(), mockUser.ID, gomock.Any()).DoAndReturn(
+			func(_ interface{}, _ int, ciphertext string) error {
+				storedCiphertext = ciphertext
+				return nil
+			},
+		)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/users/2fa/totp",
+		})
+		ctx.Set("UserGUID", mockUser.GUID.String())
+
+		s := &Server{Repository: mockRepo, Config: MockTwoFactorConfig()}
+		err := s.EnrollTOTP(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var resp generated.TOTPEnrollmentResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.NotContains(t, storedCiphertext, resp.Secret)
+		assert.Contains(t, resp.OtpauthUri, "otpauth://totp/")
+
+		key, _ := tools.ParseEncryptionKey(mockTOTPEncryptionKey)
+		secret, err := tools.DecryptSecret(key, storedCiphertext)
+		assert.NoError(t, err)
+		assert.Equal(t, resp.Secret, secret)
+	})
+}
+
+func TestEnrollTOTP_Error(t *testing.T) {
+	t.Run("when error due to two-factor authentication is already enabled", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		totp, _ := MockUserTOTP(t, mockUser, true)
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), mockUser.GUID).Return(mockUser, nil)
+		mockRepo.EXPECT().GetUserTOTP(gomock.Any(), mockUser.ID).Return(totp, nil)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/users/2fa/totp",
+		})
+		ctx.Set("UserGUID", mockUser.GUID.String())
+
+		s := &Server{Repository: mockRepo, Config: MockTwoFactorConfig()}
+		_ = s.EnrollTOTP(ctx)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("when error due to encryption key is not configured", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), mockUser.GUID).Return(mockUser, nil)
+		mockRepo.EXPECT().GetUserTOTP(gomock.Any(), mockUser.ID).Return(nil, sql.ErrNoRows)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/users/2fa/totp",
+		})
+		ctx.Set("UserGUID", mockUser.GUID.String())
+
+		s := &Server{Repository: mockRepo, Config: MockOTPConfig()}
+		_ = s.EnrollTOTP(ctx)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestConfirmTOTP(t *testing.T) {
+	t.Run("when success enable two-factor authentication", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		totp, secret := MockUserTOTP(t, mockUser, false)
+		var storedHashes []string
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), mockUser.GUID).Return(mockUser, nil)
+		mockRepo.EXPECT().GetUserTOTP(gomock.Any(), mockUser.ID).Return(totp, nil)
+		mockRepo.EXPECT().EnableUserTOTP(gomock.Any(), mockUser.ID, gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ interface{}, _ int, _ int64, hashes []string) (bool, error) {
+				storedHashes = hashes
+				return true, nil
+			},
+		)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/users/2fa/totp/confirm",
+			body:       []byte(`{"code": "` + currentTOTPCode(t, secret) + `"}`),
+		})
+		ctx.Set("UserGUID", mockUser.GUID.String())
+
+		s := &Server{Repository: mockRepo, Config: MockTwoFactorConfig()}
+		err := s.ConfirmTOTP(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var resp generated.TOTPRecoveryCodesResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Len(t, resp.RecoveryCodes, totpRecoveryCodeCount)
+		for i, code := range resp.RecoveryCodes {
+			assert.Equal(t, tools.HashOpaqueToken(code), storedHashes[i])
+		}
+	})
+}
+
+func TestConfirmTOTP_Error(t *testing.T) {
+	t.Run("when error due to code is wrong", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		totp, secret := MockUserTOTP(t, mockUser, false)
+		code, _ := tools.TOTPCode(secret, tools.TOTPStep(time.Now())-10)
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), mockUser.GUID).Return(mockUser, nil)
+		mockRepo.EXPECT().GetUserTOTP(gomock.Any(), mockUser.ID).Return(totp, nil)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/users/2fa/totp/confirm",
+			body:       []byte(`{"code": "` + code + `"}`),
+		})
+		ctx.Set("UserGUID", mockUser.GUID.String())
+
+		s := &Server{Repository: mockRepo, Config: MockTwoFactorConfig()}
+		_ = s.ConfirmTOTP(ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("when error due to no enrollment is pending", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), mockUser.GUID).Return(mockUser, nil)
+		mockRepo.EXPECT().GetUserTOTP(gomock.Any(), mockUser.ID).Return(nil, sql.ErrNoRows)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/users/2fa/totp/confirm",
+			body:       []byte(`{"code": "123456"}`),
+		})
+		ctx.Set("UserGUID", mockUser.GUID.String())
+
+		s := &Server{Repository: mockRepo, Config: MockTwoFactorConfig()}
+		_ = s.ConfirmTOTP(ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestLoginTwoFactor(t *testing.T) {
+	t.Run("when success finish the login with a TOTP code", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		totp, secret := MockUserTOTP(t, mockUser, true)
+		challenge := MockLoginChallenge(mockUser)
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetLoginChallengeByTokenHash(gomock.Any(), tools.HashOpaqueToken("challenge-token")).Return(challenge, nil)
+		mockRepo.EXPECT().UseLoginChallengeAttempt(gomock.Any(), challenge.ID, MockTwoFactorConfig().OTPMaxAttempts).Return(true, nil)
+		mockRepo.EXPECT().GetUserTOTP(gomock.Any(), mockUser.ID).Return(totp, nil)
+		mockRepo.EXPECT().MarkUserTOTPStepUsed(gomock.Any(), mockUser.ID, gomock.Any()).Return(true, nil)
+		mockRepo.EXPECT().ConsumeLoginChallenge(gomock.Any(), challenge.ID).Return(true, nil)
+		mockRepo.EXPECT().GetUserAccess(gomock.Any(), gomock.Any()).Return(repository.UserAccess{}, nil)
+		mockRepo.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Return(nil)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/login/2fa",
+			body:       []byte(`{"challenge_token": "challenge-token", "code": "` + currentTOTPCode(t, secret) + `"}`),
+		})
+
+		s := &Server{Repository: mockRepo, Config: MockTwoFactorConfig()}
+		err := s.LoginTwoFactor(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var resp generated.SuccessLoginUserResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.NotEmpty(t, resp.Token)
+	})
+
+	t.Run("when success finish the login with a recovery code", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		challenge := MockLoginChallenge(mockUser)
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetLoginChallengeByTokenHash(gomock.Any(), gomock.Any()).Return(challenge, nil)
+		mockRepo.EXPECT().UseLoginChallengeAttempt(gomock.Any(), challenge.ID, MockTwoFactorConfig().OTPMaxAttempts).Return(true, nil)
+		mockRepo.EXPECT().UseTOTPRecoveryCode(gomock.Any(), mockUser.ID, tools.HashOpaqueToken("k3j9d-x2m7q")).Return(true, nil)
+		mockRepo.EXPECT().ConsumeLoginChallenge(gomock.Any(), challenge.ID).Return(true, nil)
+		mockRepo.EXPECT().GetUserAccess(gomock.Any(), gomock.Any()).Return(repository.UserAccess{}, nil)
+		mockRepo.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Return(nil)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/login/2fa",
+			body:       []byte(`{"challenge_token": "challenge-token", "recovery_code": "K3J9DX2M7Q"}`),
+		})
+
+		s := &Server{Repository: mockRepo, Config: MockTwoFactorConfig()}
+		err := s.LoginTwoFactor(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("when success restore the deleted account the login asked for", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		challenge := MockLoginChallenge(mockUser)
+		challenge.RestoreAccount = true
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		gomock.InOrder(
+			mockRepo.EXPECT().GetLoginChallengeByTokenHash(gomock.Any(), gomock.Any()).Return(challenge, nil),
+			mockRepo.EXPECT().UseLoginChallengeAttempt(gomock.Any(), challenge.ID, MockTwoFactorConfig().OTPMaxAttempts).Return(true, nil),
+			mockRepo.EXPECT().UseTOTPRecoveryCode(gomock.Any(), mockUser.ID, gomock.Any()).Return(true, nil),
+			mockRepo.EXPECT().ConsumeLoginChallenge(gomock.Any(), challenge.ID).Return(true, nil),
+			mockRepo.EXPECT().RestoreUser(gomock.Any(), mockUser.ID).Return(true, nil),
+		)
+		mockRepo.EXPECT().GetUserAccess(gomock.Any(), gomock.Any()).Return(repository.UserAccess{}, nil)
+		mockRepo.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Return(nil)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/login/2fa",
+			body:       []byte(`{"challenge_token": "challenge-token", "recovery_code": "K3J9DX2M7Q"}`),
+		})
+
+		s := &Server{Repository: mockRepo, Config: MockTwoFactorConfig()}
+		err := s.LoginTwoFactor(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
+
+func TestLoginTwoFactor_Error(t *testing.T) {
+	mockUser := MockUser()
+
+	t.Run("when error validate request body", func(t *testing.T) {
+		e := echo.New()
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/login/2fa",
+			body:       []byte(`{"challenge_token": "challenge-token"}`),
+		})
+
+		s := &Server{}
+		_ = s.LoginTwoFactor(ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("when error due to code is wrong", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		totp, secret := MockUserTOTP(t, mockUser, true)
+		code, _ := tools.TOTPCode(secret, tools.TOTPStep(time.Now())-10)
+		challenge := MockLoginChallenge(mockUser)
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetLoginChallengeByTokenHash(gomock.Any(), gomock.Any()).Return(challenge, nil)
+		mockRepo.EXPECT().GetUserTOTP(gomock.Any(), mockUser.ID).Return(totp, nil)
+		mockRepo.EXPECT().UseLoginChallengeAttempt(gomock.Any(), challenge.ID, MockTwoFactorConfig().OTPMaxAttempts).Return(true, nil)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/login/2fa",
+			body:       []byte(`{"challenge_token": "challenge-token", "code": "` + code + `"}`),
+		})
+
+		s := &Server{Repository: mockRepo, Config: MockTwoFactorConfig()}
+		_ = s.LoginTwoFactor(ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("when error due to code was already used", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		totp, secret := MockUserTOTP(t, mockUser, true)
+		challenge := MockLoginChallenge(mockUser)
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetLoginChallengeByTokenHash(gomock.Any(), gomock.Any()).Return(challenge, nil)
+		mockRepo.EXPECT().GetUserTOTP(gomock.Any(), mockUser.ID).Return(totp, nil)
+		mockRepo.EXPECT().MarkUserTOTPStepUsed(gomock.Any(), mockUser.ID, gomock.Any()).Return(false, nil)
+		mockRepo.EXPECT().UseLoginChallengeAttempt(gomock.Any(), challenge.ID, MockTwoFactorConfig().OTPMaxAttempts).Return(true, nil)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/login/2fa",
+			body:       []byte(`{"challenge_token": "challenge-token", "code": "` + currentTOTPCode(t, secret) + `"}`),
+		})
+
+		s := &Server{Repository: mockRepo, Config: MockTwoFactorConfig()}
+		_ = s.LoginTwoFactor(ctx)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("when error due to challenge has expired", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		challenge := MockLoginChallenge(mockUser)
+		challenge.ExpiresAt = time.Now().UTC().Add(-time.Second)
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetLoginChallengeByTokenHash(gomock.Any(), gomock.Any()).Return(challenge, nil)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/login/2fa",
+			body:       []byte(`{"challenge_token": "challenge-token", "code": "123456"}`),
+		})
+
+		s := &Server{Repository: mockRepo, Config: MockTwoFactorConfig()}
+		_ = s.LoginTwoFactor(ctx)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("when error due to attempts are used up", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		challenge := MockLoginChallenge(mockUser)
+		challenge.Attempts = MockTwoFactorConfig().OTPMaxAttempts
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetLoginChallengeByTokenHash(gomock.Any(), gomock.Any()).Return(challenge, nil)
+		mockRepo.EXPECT().UseLoginChallengeAttempt(gomock.Any(), challenge.ID, MockTwoFactorConfig().OTPMaxAttempts).Return(false, nil)
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/login/2fa",
+			body:       []byte(`{"challenge_token": "challenge-token", "code": "123456"}`),
+		})
+
+		s := &Server{Repository: mockRepo, Config: MockTwoFactorConfig()}
+		_ = s.LoginTwoFactor(ctx)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
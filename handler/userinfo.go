@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+func (s *Server) GetUserInfo(ctx echo.Context) error {
+	return s.userInfo(ctx)
+}
+
+func (s *Server) PostUserInfo(ctx echo.Context) error {
+	return s.userInfo(ctx)
+}
+
+func (s *Server) userInfo(ctx echo.Context) error {
+	guid := uuid.MustParse(ctx.Get("UserGUID").(string))
+
+	user, err := s.Repository.GetUserByGUID(ctx.Request().Context(), guid)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// The token outlived its owner, which OpenID Connect reports as an invalid token.
+			return invalidTokenError(ctx, "user is not found")
+		}
+		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: err.Error()})
+	}
+
+	resp := generated.UserInfoResponse{Sub: user.GUID.String()}
+
+	// First-party tokens carry no client and see every claim, OAuth2 clients
+	// only see the claims their scope grants.
+	clientID, _ := ctx.Get("ClientID").(string)
+	scope, _ := ctx.Get("Scope").(string)
+	if clientID == "" || hasScope(scope, oauthScopeProfile) {
+		updatedAt := user.LastModifiedAt.Unix()
+		resp.Name = &user.FullName
+		resp.UpdatedAt = &updatedAt
+	}
+	if clientID == "" || hasScope(scope, oauthScopePhone) {
+		resp.PhoneNumber = &user.PhoneNumber
+	}
+	return ctx.JSON(http.StatusOK, resp)
+}
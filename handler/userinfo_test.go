@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetUserInfo(t *testing.T) {
+	t.Run("when success get user info", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), mockUser.GUID).Return(mockUser, nil)
+
+		reqParam := testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodGet,
+			url:        "/userinfo",
+		}
+		ctx, rec := TestRequestEndpoint(reqParam)
+		ctx.Set("UserGUID", mockUser.GUID.String())
+
+		s := &Server{Repository: mockRepo}
+
+		err := s.GetUserInfo(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var resp generated.UserInfoResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, mockUser.GUID.String(), resp.Sub)
+		assert.Equal(t, mockUser.FullName, *resp.Name)
+		assert.Equal(t, mockUser.PhoneNumber, *resp.PhoneNumber)
+	})
+
+	t.Run("when success get user info limited by the client scope", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUser := MockUser()
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), mockUser.GUID).Return(mockUser, nil)
+
+		reqParam := testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodGet,
+			url:        "/userinfo",
+		}
+		ctx, rec := TestRequestEndpoint(reqParam)
+		ctx.Set("UserGUID", mockUser.GUID.String())
+		ctx.Set("ClientID", "third-party-app")
+		ctx.Set("Scope", "openid profile")
+
+		s := &Server{Repository: mockRepo}
+
+		err := s.GetUserInfo(ctx)
+		assert.NoError(t, err)
+
+		var resp generated.UserInfoResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, mockUser.FullName, *resp.Name)
+		assert.Nil(t, resp.PhoneNumber)
+	})
+}
+
+func TestGetUserInfo_Error(t *testing.T) {
+	mockUser := MockUser()
+
+	t.Run("when error due to user is not found", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), mockUser.GUID).Return(nil, sql.ErrNoRows)
+
+		reqParam := testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodPost,
+			url:        "/userinfo",
+		}
+		ctx, rec := TestRequestEndpoint(reqParam)
+		ctx.Set("UserGUID", mockUser.GUID.String())
+
+		s := &Server{Repository: mockRepo}
+
+		err := s.PostUserInfo(ctx)
+		assert.Equal(t, http.StatusUnauthorized, err.(*echo.HTTPError).Code)
+		assert.Contains(t, rec.Header().Get(echo.HeaderWWWAuthenticate), "invalid_token")
+	})
+
+	t.Run("when error to get user data", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), mockUser.GUID).Return(nil, fmt.Errorf("error db"))
+
+		reqParam := testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodGet,
+			url:        "/userinfo",
+		}
+		ctx, rec := TestRequestEndpoint(reqParam)
+		ctx.Set("UserGUID", mockUser.GUID.String())
+
+		s := &Server{Repository: mockRepo}
+
+		_ = s.GetUserInfo(ctx)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"github.com/SawitProRecruitment/UserService/config"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/tools"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidToken       = errors.New("invalid token")
+	ErrInvalidTokenClaims = errors.New("invalid token claims")
+	ErrTokenRevoked       = errors.New("token has been revoked")
+)
+
+// TokenVerifier checks an access token's signature, issuer, audience, claims
+// and revocation state. It backs JWTMiddleware as well as the endpoints that
+// verify tokens on behalf of other services.
+type TokenVerifier struct {
+	keyRing       *tools.KeyRing
+	revocations   repository.TokenRevocationRepositoryInterface
+	parserOptions []jwt.ParserOption
+}
+
+func NewTokenVerifier(cfg config.Config, keyRing *tools.KeyRing, revocations repository.TokenRevocationRepositoryInterface) *TokenVerifier {
+	var parserOptions []jwt.ParserOption
+	if issuer := tokenIssuer(cfg); issuer != "" {
+		parserOptions = append(parserOptions, jwt.WithIssuer(issuer))
+	}
+	if cfg.JWTAudience != "" {
+		parserOptions = append(parserOptions, jwt.WithAudience(cfg.JWTAudience))
+	}
+
+	return &TokenVerifier{
+		keyRing:       keyRing,
+		revocations:   revocations,
+		parserOptions: parserOptions,
+	}
+}
+
+// Verify returns the claims of a valid token. Rejections are reported as
+// ErrInvalidToken, ErrInvalidTokenClaims or ErrTokenRevoked; any other error
+// means the revocation store could not be reached.
+func (v *TokenVerifier) Verify(ctx context.Context, tokenString string) (*tools.JWTCustomClaims, error) {
+	claims := new(tools.JWTCustomClaims)
+	token, err := jwt.ParseWithClaims(tokenString, claims, v.keyRing.Keyfunc, v.parserOptions...)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	// A token without user_guid must at least name the client it was issued to.
+	if !token.Valid || claims.IssuedAt == nil || claims.ExpiresAt == nil || (claims.GUID == uuid.Nil && claims.ClientID == "") {
+		return nil, ErrInvalidTokenClaims
+	}
+
+	revoked, err := v.revocations.IsAccessTokenRevoked(ctx, repository.IsAccessTokenRevokedInput{
+		JTI:      claims.ID,
+		UserGUID: claims.GUID,
+		IssuedAt: claims.IssuedAt.Time.UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrTokenRevoked
+	}
+	return claims, nil
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/SawitProRecruitment/UserService/config"
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/tools"
+	"github.com/labstack/echo/v4"
+)
+
+// wellKnownMaxAge lets verifiers cache discovery documents while still picking
+// up a rotated key within minutes.
+const wellKnownMaxAge = "public, max-age=300"
+
+func (s *Server) GetJSONWebKeySet(ctx echo.Context) error {
+	keys := s.KeyRing.JWKS()
+
+	resp := generated.JSONWebKeySet{Keys: make([]generated.JSONWebKey, 0, len(keys))}
+	for _, key := range keys {
+		resp.Keys = append(resp.Keys, generated.JSONWebKey{
+			Kty: key.Kty,
+			Use: key.Use,
+			Alg: key.Alg,
+			Kid: key.Kid,
+			N:   key.N,
+			E:   key.E,
+		})
+	}
+
+	ctx.Response().Header().Set(echo.HeaderCacheControl, wellKnownMaxAge)
+	return ctx.JSON(http.StatusOK, resp)
+}
+
+func (s *Server) GetOpenIDConfiguration(ctx echo.Context) error {
+	issuer := tokenIssuer(s.Config)
+	authorizationEndpoint := issuer + "/oauth/authorize"
+	tokenEndpoint := issuer + "/oauth/token"
+	introspectionEndpoint := issuer + "/oauth/introspect"
+	revocationEndpoint := issuer + "/oauth/revoke"
+
+	resp := generated.OpenIDConfiguration{
+		Issuer:                            issuer,
+		AuthorizationEndpoint:             &authorizationEndpoint,
+		TokenEndpoint:                     &tokenEndpoint,
+		IntrospectionEndpoint:             &introspectionEndpoint,
+		RevocationEndpoint:                &revocationEndpoint,
+		JwksUri:                           issuer + "/.well-known/jwks.json",
+		UserinfoEndpoint:                  issuer + "/userinfo",
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               &[]string{oauthGrantAuthorizationCode, oauthGrantRefreshToken, oauthGrantClientCredentials},
+		CodeChallengeMethodsSupported:     &[]string{tools.PKCEMethodS256},
+		TokenEndpointAuthMethodsSupported: &[]string{"none", "client_secret_basic", "client_secret_post"},
+		SubjectTypesSupported:             []string{"public"},
+		IdTokenSigningAlgValuesSupported:  []string{"RS256"},
+		ScopesSupported:                   []string{oauthScopeOpenID, oauthScopeProfile, oauthScopePhone},
+		ClaimsSupported: []string{
+			"sub", "iss", "aud", "exp", "iat", "jti",
+			"name", "phone_number",
+			"user_guid", "full_name",
+		},
+	}
+
+	ctx.Response().Header().Set(echo.HeaderCacheControl, wellKnownMaxAge)
+	return ctx.JSON(http.StatusOK, resp)
+}
+
+// tokenIssuer is the iss of every token the service signs, the issuer the
+// verifier expects and the one the discovery document names. OpenID Connect
+// requires it to be the base URL of the provider and compares it as an exact
+// string, so a trailing slash in JWT_ISSUER is dropped everywhere.
+func tokenIssuer(cfg config.Config) string {
+	return strings.TrimSuffix(cfg.JWTIssuer, "/")
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/SawitProRecruitment/UserService/config"
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/tools"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetJSONWebKeySet(t *testing.T) {
+	t.Run("when success get json web key set", func(t *testing.T) {
+		e := echo.New()
+		keyRing, err := tools.NewKeyRing(tools.KeyRingOptions{PrivateKey: tools.MockRSAPrivateKey()})
+		assert.NoError(t, err)
+
+		reqParam := testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodGet,
+			url:        "/.well-known/jwks.json",
+		}
+		ctx, rec := TestRequestEndpoint(reqParam)
+
+		s := &Server{KeyRing: keyRing}
+
+		err = s.GetJSONWebKeySet(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var resp generated.JSONWebKeySet
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Len(t, resp.Keys, 1)
+		assert.Equal(t, keyRing.ActiveKeyID(), resp.Keys[0].Kid)
+	})
+}
+
+func TestGetOpenIDConfiguration(t *testing.T) {
+	t.Run("when issuer is configured", func(t *testing.T) {
+		e := echo.New()
+		reqParam := testRequestEndpointParam{
+			e:          e,
+			httpMethod: http.MethodGet,
+			url:        "/.well-known/openid-configuration",
+		}
+		ctx, rec := TestRequestEndpoint(reqParam)
+
+		s := &Server{Config: config.Config{JWTIssuer: "https://auth.sawitpro.com/"}}
+
+		err := s.GetOpenIDConfiguration(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var resp generated.OpenIDConfiguration
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, "https://auth.sawitpro.com", resp.Issuer)
+		assert.Equal(t, "https://auth.sawitpro.com/.well-known/jwks.json", resp.JwksUri)
+		assert.Equal(t, "https://auth.sawitpro.com/userinfo", resp.UserinfoEndpoint)
+	})
+
+	t.Run("when issuer has a trailing slash then tokens carry the advertised issuer", func(t *testing.T) {
+		e := echo.New()
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		cfg := config.Config{JWTIssuer: "https://auth.sawitpro.com/", RSAPrivateKey: tools.MockRSAPrivateKey()}
+		s := &Server{Config: cfg}
+
+		ctx, rec := TestRequestEndpoint(testRequestEndpointParam{e: e, httpMethod: http.MethodGet, url: "/.well-known/openid-configuration"})
+		_ = s.GetOpenIDConfiguration(ctx)
+		var resp generated.OpenIDConfiguration
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+
+		token, _, err := tools.GenerateJWTToken(s.accessTokenParams(tokenOwner{GUID: uuid.New()}), 1, cfg.RSAPrivateKey)
+		assert.NoError(t, err)
+		claims := &tools.JWTCustomClaims{}
+		_, _, err = jwt.NewParser().ParseUnverified(token, claims)
+		assert.NoError(t, err)
+		assert.Equal(t, resp.Issuer, claims.RegisteredClaims.Issuer)
+
+		keyRing, err := tools.NewKeyRing(tools.KeyRingOptions{PrivateKey: cfg.RSAPrivateKey})
+		assert.NoError(t, err)
+		revocations := repository.NewMockTokenRevocationRepositoryInterface(ctrl)
+		revocations.EXPECT().IsAccessTokenRevoked(gomock.Any(), gomock.Any()).Return(false, nil)
+		_, err = NewTokenVerifier(cfg, keyRing, revocations).Verify(context.Background(), token)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,83 @@
+// Package jobs holds the work the service does in the background rather than
+// in answer to a request.
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/repository"
+)
+
+// defaultPurgeBatchSize bounds how many accounts one statement purges, so a
+// backlog does not hold locks on many users at once.
+const defaultPurgeBatchSize = 100
+
+type AccountPurgerOptions struct {
+	// GracePeriod is how long deleted accounts can still be restored.
+	GracePeriod time.Duration
+	// Interval is how often Run looks for accounts to purge.
+	Interval  time.Duration
+	BatchSize int
+}
+
+// AccountPurger erases the personal data of deleted accounts once their grace
+// period is over. Several instances can run at once; each account is purged
+// by only one of them.
+type AccountPurger struct {
+	repository  repository.RepositoryInterface
+	gracePeriod time.Duration
+	interval    time.Duration
+	batchSize   int
+	now         func() time.Time
+}
+
+func NewAccountPurger(repo repository.RepositoryInterface, opts AccountPurgerOptions) *AccountPurger {
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultPurgeBatchSize
+	}
+	return &AccountPurger{
+		repository:  repo,
+		gracePeriod: opts.GracePeriod,
+		interval:    opts.Interval,
+		batchSize:   batchSize,
+		now:         time.Now,
+	}
+}
+
+// Run purges the accounts that are due straight away and then every interval
+// until ctx is done.
+func (p *AccountPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := p.PurgeDue(ctx)
+		if err != nil {
+			log.Printf("purging deleted accounts: %v", err)
+		} else if purged > 0 {
+			log.Printf("purged %d deleted accounts", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeDue purges every account deleted longer than the grace period ago, one
+// batch at a time, and returns how many it purged.
+func (p *AccountPurger) PurgeDue(ctx context.Context) (total int, err error) {
+	deletedBefore := p.now().UTC().Add(-p.gracePeriod)
+	for {
+		purged, err := p.repository.PurgeDeletedUsers(ctx, deletedBefore, p.batchSize)
+		total += purged
+		if err != nil || purged < p.batchSize {
+			return total, err
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountPurger_PurgeDue(t *testing.T) {
+	now := time.Date(2024, 5, 31, 12, 0, 0, 0, time.UTC)
+	deletedBefore := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	newPurger := func(repo repository.RepositoryInterface) *AccountPurger {
+		purger := NewAccountPurger(repo, AccountPurgerOptions{GracePeriod: 30 * 24 * time.Hour, Interval: time.Hour, BatchSize: 2})
+		purger.now = func() time.Time { return now }
+		return purger
+	}
+
+	t.Run("when accounts are due then batches are purged until one comes back short", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		gomock.InOrder(
+			mockRepo.EXPECT().PurgeDeletedUsers(gomock.Any(), deletedBefore, 2).Return(2, nil),
+			mockRepo.EXPECT().PurgeDeletedUsers(gomock.Any(), deletedBefore, 2).Return(1, nil),
+		)
+
+		purged, err := newPurger(mockRepo).PurgeDue(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 3, purged)
+	})
+
+	t.Run("when a batch fails then purging stops", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		gomock.InOrder(
+			mockRepo.EXPECT().PurgeDeletedUsers(gomock.Any(), deletedBefore, 2).Return(2, nil),
+			mockRepo.EXPECT().PurgeDeletedUsers(gomock.Any(), deletedBefore, 2).Return(0, fmt.Errorf("error db")),
+		)
+
+		purged, err := newPurger(mockRepo).PurgeDue(context.Background())
+		assert.Error(t, err)
+		assert.Equal(t, 2, purged)
+	})
+}
+
+func TestAccountPurger_Run(t *testing.T) {
+	t.Run("when the context is done then it stops after the first pass", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().PurgeDeletedUsers(gomock.Any(), gomock.Any(), defaultPurgeBatchSize).DoAndReturn(
+			func(_ context.Context, _ time.Time, _ int) (int, error) {
+				cancel()
+				return 0, nil
+			},
+		)
+
+		NewAccountPurger(mockRepo, AccountPurgerOptions{Interval: time.Hour}).Run(ctx)
+	})
+}
@@ -0,0 +1,293 @@
+package jobs
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/google/uuid"
+)
+
+// defaultDataExportStaleAfter is how long an export may stay processing before
+// the worker building it is taken to have died.
+const defaultDataExportStaleAfter = 15 * time.Minute
+
+type DataExporterOptions struct {
+	// Retention is how long a ready archive is kept for download.
+	Retention time.Duration
+	// Interval is how often Run looks for queued exports.
+	Interval   time.Duration
+	StaleAfter time.Duration
+}
+
+// DataExporter builds the archives users ask for with a data export, and
+// deletes them once they expire. Several instances can run at once; each
+// export is built by only one of them.
+type DataExporter struct {
+	repository repository.RepositoryInterface
+	retention  time.Duration
+	interval   time.Duration
+	staleAfter time.Duration
+	now        func() time.Time
+}
+
+func NewDataExporter(repo repository.RepositoryInterface, opts DataExporterOptions) *DataExporter {
+	staleAfter := opts.StaleAfter
+	if staleAfter <= 0 {
+		staleAfter = defaultDataExportStaleAfter
+	}
+	return &DataExporter{
+		repository: repo,
+		retention:  opts.Retention,
+		interval:   opts.Interval,
+		staleAfter: staleAfter,
+		now:        time.Now,
+	}
+}
+
+// Run builds the queued exports straight away and then every interval until
+// ctx is done.
+func (e *DataExporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		expired, err := e.repository.ExpireDataExports(ctx, e.now().UTC())
+		if err != nil {
+			log.Printf("expiring data exports: %v", err)
+		} else if expired > 0 {
+			log.Printf("expired %d data exports", expired)
+		}
+
+		built, err := e.ProcessPending(ctx)
+		if err != nil {
+			log.Printf("building data exports: %v", err)
+		} else if built > 0 {
+			log.Printf("built %d data exports", built)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessPending builds queued exports one at a time until none are left, and
+// returns how many it took on. An export that cannot be built is marked
+// failed rather than stopping the others.
+func (e *DataExporter) ProcessPending(ctx context.Context) (total int, err error) {
+	for {
+		export, err := e.repository.ClaimDataExport(ctx, e.now().UTC().Add(-e.staleAfter))
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return total, nil
+			}
+			return total, err
+		}
+		total++
+
+		archive, err := e.buildArchive(ctx, export)
+		if err != nil {
+			log.Printf("building data export %s: %v", export.GUID, err)
+			err = e.repository.FailDataExport(ctx, export.ID, err.Error())
+		} else {
+			err = e.repository.CompleteDataExport(ctx, export.ID, archive, e.now().UTC().Add(e.retention))
+		}
+		if err != nil {
+			return total, err
+		}
+	}
+}
+
+// dataExportDocument is everything stored about a user, as handed to them.
+type dataExportDocument struct {
+	GeneratedAt  time.Time              `json:"generated_at"`
+	Profile      exportedProfile        `json:"profile"`
+	LoginHistory []exportedLogin        `json:"login_history"`
+	Sessions     []exportedSession      `json:"sessions"`
+	Consents     []exportedConsent      `json:"consents"`
+	AuditLogs    []exportedAuditLogItem `json:"audit_logs"`
+}
+
+type exportedProfile struct {
+	ID                 uuid.UUID  `json:"id"`
+	FullName           string     `json:"full_name"`
+	PhoneNumber        string     `json:"phone_number"`
+	PhoneVerifiedAt    *time.Time `json:"phone_verified_at"`
+	PendingPhoneNumber *string    `json:"pending_phone_number"`
+	Roles              []string   `json:"roles"`
+	TwoFactorEnabledAt *time.Time `json:"two_factor_enabled_at"`
+	DisabledAt         *time.Time `json:"disabled_at"`
+	CreatedAt          time.Time  `json:"created_at"`
+	LastModifiedAt     time.Time  `json:"last_modified_at"`
+}
+
+type exportedLogin struct {
+	LoggedInAt time.Time `json:"logged_in_at"`
+	Client     *string   `json:"client"`
+}
+
+type exportedSession struct {
+	ID              uuid.UUID  `json:"id"`
+	Client          *string    `json:"client"`
+	Scope           string     `json:"scope"`
+	StartedAt       time.Time  `json:"started_at"`
+	LastRefreshedAt time.Time  `json:"last_refreshed_at"`
+	ExpiresAt       time.Time  `json:"expires_at"`
+	RevokedAt       *time.Time `json:"revoked_at"`
+}
+
+type exportedConsent struct {
+	Client    string     `json:"client"`
+	Scopes    []string   `json:"scopes"`
+	GivenAt   time.Time  `json:"given_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+}
+
+// exportedAuditLogItem leaves out who acted and from where, which is about
+// the staff member rather than the user.
+type exportedAuditLogItem struct {
+	Action    string            `json:"action"`
+	Details   map[string]string `json:"details"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// buildArchive encodes everything stored about the owner of export in the
+// format they asked for.
+func (e *DataExporter) buildArchive(ctx context.Context, export *repository.DataExport) ([]byte, error) {
+	doc, err := e.collect(ctx, export.UserGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	if export.Format == repository.DataExportFormatZIP {
+		return encodeDataExportZIP(doc)
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func (e *DataExporter) collect(ctx context.Context, userGUID uuid.UUID) (doc dataExportDocument, err error) {
+	doc.GeneratedAt = e.now().UTC()
+
+	user, err := e.repository.GetUserByGUID(ctx, userGUID)
+	if err != nil {
+		return doc, err
+	}
+	access, err := e.repository.GetUserAccess(ctx, user.ID)
+	if err != nil {
+		return doc, err
+	}
+	doc.Profile = exportedProfile{
+		ID:                 user.GUID,
+		FullName:           user.FullName,
+		PhoneNumber:        user.PhoneNumber,
+		PhoneVerifiedAt:    user.PhoneVerifiedAt,
+		PendingPhoneNumber: user.PendingPhoneNumber,
+		Roles:              access.Roles,
+		DisabledAt:         user.DisabledAt,
+		CreatedAt:          user.CreatedAt,
+		LastModifiedAt:     user.LastModifiedAt,
+	}
+
+	totp, err := e.repository.GetUserTOTP(ctx, user.ID)
+	if err != nil && err != sql.ErrNoRows {
+		return doc, err
+	}
+	if err == nil {
+		doc.Profile.TwoFactorEnabledAt = totp.EnabledAt
+	}
+
+	sessions, err := e.repository.GetUserSessions(ctx, user.ID)
+	if err != nil {
+		return doc, err
+	}
+	doc.LoginHistory = []exportedLogin{}
+	doc.Sessions = []exportedSession{}
+	for _, session := range sessions {
+		doc.LoginHistory = append(doc.LoginHistory, exportedLogin{LoggedInAt: session.StartedAt, Client: session.ClientName})
+		if session.RevokedAt == nil && session.ExpiresAt.After(doc.GeneratedAt) {
+			doc.Sessions = append(doc.Sessions, exportedSession{
+				ID:              session.FamilyID,
+				Client:          session.ClientName,
+				Scope:           session.Scope,
+				StartedAt:       session.StartedAt,
+				LastRefreshedAt: session.LastRefreshedAt,
+				ExpiresAt:       session.ExpiresAt,
+			})
+		}
+	}
+
+	consents, err := e.repository.ListUserOAuthConsents(ctx, user.ID)
+	if err != nil {
+		return doc, err
+	}
+	doc.Consents = []exportedConsent{}
+	for _, consent := range consents {
+		doc.Consents = append(doc.Consents, exportedConsent{
+			Client:    consent.ClientName,
+			Scopes:    consent.Scopes,
+			GivenAt:   consent.CreatedAt,
+			UpdatedAt: consent.LastModifiedAt,
+			RevokedAt: consent.RevokedAt,
+		})
+	}
+
+	entries, err := e.repository.ListUserAuditLogs(ctx, user.ID)
+	if err != nil {
+		return doc, err
+	}
+	doc.AuditLogs = []exportedAuditLogItem{}
+	for _, entry := range entries {
+		doc.AuditLogs = append(doc.AuditLogs, exportedAuditLogItem{
+			Action:    entry.Action,
+			Details:   entry.Details,
+			CreatedAt: entry.CreatedAt,
+		})
+	}
+	return doc, nil
+}
+
+// encodeDataExportZIP writes one JSON file per kind of data.
+func encodeDataExportZIP(doc dataExportDocument) ([]byte, error) {
+	files := []struct {
+		name    string
+		content any
+	}{
+		{"profile.json", doc.Profile},
+		{"login_history.json", doc.LoginHistory},
+		{"sessions.json", doc.Sessions},
+		{"consents.json", doc.Consents},
+		{"audit_logs.json", doc.AuditLogs},
+	}
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, file := range files {
+		f, err := w.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Deflate, Modified: doc.GeneratedAt})
+		if err != nil {
+			return nil, err
+		}
+		content, err := json.MarshalIndent(file.content, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		_, err = f.Write(content)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := w.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
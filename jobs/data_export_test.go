@@ -0,0 +1,137 @@
+package jobs
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataExporter_ProcessPending(t *testing.T) {
+	now := time.Date(2024, 5, 31, 12, 0, 0, 0, time.UTC)
+	user := &repository.User{ID: 7, GUID: uuid.New(), FullName: "Jane Doe", PhoneNumber: "+62345678901"}
+	clientName := "Acme"
+
+	newExporter := func(repo repository.RepositoryInterface) *DataExporter {
+		exporter := NewDataExporter(repo, DataExporterOptions{Retention: 72 * time.Hour, Interval: time.Minute})
+		exporter.now = func() time.Time { return now }
+		return exporter
+	}
+
+	expectUserData := func(mockRepo *repository.MockRepositoryInterface) {
+		mockRepo.EXPECT().GetUserByGUID(gomock.Any(), user.GUID).Return(user, nil)
+		mockRepo.EXPECT().GetUserAccess(gomock.Any(), user.ID).Return(repository.UserAccess{Roles: []string{"support"}}, nil)
+		mockRepo.EXPECT().GetUserTOTP(gomock.Any(), user.ID).Return(nil, sql.ErrNoRows)
+		mockRepo.EXPECT().GetUserSessions(gomock.Any(), user.ID).Return([]repository.UserSession{
+			{FamilyID: uuid.New(), StartedAt: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour)},
+			{FamilyID: uuid.New(), ClientName: &clientName, StartedAt: now.Add(-48 * time.Hour), ExpiresAt: now.Add(-time.Hour)},
+		}, nil)
+		mockRepo.EXPECT().ListUserOAuthConsents(gomock.Any(), user.ID).Return([]*repository.UserOAuthConsent{
+			{OAuthConsent: repository.OAuthConsent{Scopes: []string{"profile"}}, ClientName: clientName},
+		}, nil)
+		mockRepo.EXPECT().ListUserAuditLogs(gomock.Any(), user.ID).Return([]*repository.AuditLog{
+			{Action: repository.AuditActionUserViewed},
+		}, nil)
+	}
+
+	t.Run("when an export is queued then it is built as JSON and kept for the retention", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		export := &repository.DataExport{ID: 1, UserGUID: user.GUID, Format: repository.DataExportFormatJSON}
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		var archive []byte
+		gomock.InOrder(
+			mockRepo.EXPECT().ClaimDataExport(gomock.Any(), now.Add(-defaultDataExportStaleAfter)).Return(export, nil),
+			mockRepo.EXPECT().CompleteDataExport(gomock.Any(), 1, gomock.Any(), now.Add(72*time.Hour)).
+				DoAndReturn(func(_ context.Context, _ int, a []byte, _ time.Time) error {
+					archive = a
+					return nil
+				}),
+			mockRepo.EXPECT().ClaimDataExport(gomock.Any(), gomock.Any()).Return(nil, sql.ErrNoRows),
+		)
+		expectUserData(mockRepo)
+
+		built, err := newExporter(mockRepo).ProcessPending(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, built)
+
+		var doc dataExportDocument
+		assert.NoError(t, json.Unmarshal(archive, &doc))
+		assert.Equal(t, user.FullName, doc.Profile.FullName)
+		assert.Equal(t, []string{"support"}, doc.Profile.Roles)
+		assert.Len(t, doc.LoginHistory, 2)
+		assert.Len(t, doc.Sessions, 1)
+		assert.Equal(t, clientName, doc.Consents[0].Client)
+		assert.Equal(t, repository.AuditActionUserViewed, doc.AuditLogs[0].Action)
+	})
+
+	t.Run("when a ZIP is asked for then each kind of data gets its own file", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		export := &repository.DataExport{ID: 2, UserGUID: user.GUID, Format: repository.DataExportFormatZIP}
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		var archive []byte
+		gomock.InOrder(
+			mockRepo.EXPECT().ClaimDataExport(gomock.Any(), gomock.Any()).Return(export, nil),
+			mockRepo.EXPECT().CompleteDataExport(gomock.Any(), 2, gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ int, a []byte, _ time.Time) error {
+					archive = a
+					return nil
+				}),
+			mockRepo.EXPECT().ClaimDataExport(gomock.Any(), gomock.Any()).Return(nil, sql.ErrNoRows),
+		)
+		expectUserData(mockRepo)
+
+		_, err := newExporter(mockRepo).ProcessPending(context.Background())
+		assert.NoError(t, err)
+
+		r, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+		assert.NoError(t, err)
+		var names []string
+		for _, f := range r.File {
+			names = append(names, f.Name)
+		}
+		assert.Equal(t, []string{"profile.json", "login_history.json", "sessions.json", "consents.json", "audit_logs.json"}, names)
+	})
+
+	t.Run("when an export cannot be built then it is failed and the next one is built", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		export := &repository.DataExport{ID: 3, UserGUID: user.GUID, Format: repository.DataExportFormatJSON}
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		gomock.InOrder(
+			mockRepo.EXPECT().ClaimDataExport(gomock.Any(), gomock.Any()).Return(export, nil),
+			mockRepo.EXPECT().GetUserByGUID(gomock.Any(), user.GUID).Return(nil, sql.ErrNoRows),
+			mockRepo.EXPECT().FailDataExport(gomock.Any(), 3, sql.ErrNoRows.Error()).Return(nil),
+			mockRepo.EXPECT().ClaimDataExport(gomock.Any(), gomock.Any()).Return(nil, sql.ErrNoRows),
+		)
+
+		built, err := newExporter(mockRepo).ProcessPending(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, built)
+	})
+
+	t.Run("when claiming fails then processing stops", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := repository.NewMockRepositoryInterface(ctrl)
+		mockRepo.EXPECT().ClaimDataExport(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("error db"))
+
+		built, err := newExporter(mockRepo).ProcessPending(context.Background())
+		assert.Error(t, err)
+		assert.Equal(t, 0, built)
+	})
+}
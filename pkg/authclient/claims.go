@@ -0,0 +1,48 @@
+// Package authclient verifies access tokens issued by the user service, for
+// services that accept them without calling back on every request.
+package authclient
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Claims are the claims of a verified access token. User tokens carry the
+// user's GUID; tokens issued to an OAuth2 client acting on its own behalf
+// only carry ClientID.
+type Claims struct {
+	UserGUID    uuid.UUID `json:"user_guid"`
+	FullName    string    `json:"full_name"`
+	PhoneNumber string    `json:"phone_number,omitempty"`
+	Scope       string    `json:"scope,omitempty"`
+	ClientID    string    `json:"client_id,omitempty"`
+	Roles       []string  `json:"roles,omitempty"`
+	Permissions []string  `json:"permissions,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// IsClient reports whether the token was issued to a client rather than a
+// user.
+func (c *Claims) IsClient() bool {
+	return c.UserGUID == uuid.Nil
+}
+
+// HasScope reports whether scope was granted. Tokens from the user service's
+// own login carry no scope at all, so they never match.
+func (c *Claims) HasScope(scope string) bool {
+	for _, granted := range strings.Fields(c.Scope) {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPermission reports whether the user was granted permission through one
+// of their roles. Client tokens never carry permissions.
+func (c *Claims) HasPermission(permission string) bool {
+	return slices.Contains(c.Permissions, permission)
+}
@@ -0,0 +1,261 @@
+package authclient
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// KeySource resolves the public key a token was signed with. An empty keyID
+// asks for the key tokens without a kid header are verified with.
+type KeySource interface {
+	Key(ctx context.Context, keyID string) (*rsa.PublicKey, error)
+}
+
+type staticKeySource struct {
+	keys       map[string]*rsa.PublicKey
+	defaultKey *rsa.PublicKey
+}
+
+// NewStaticKeySource accepts one or more concatenated PEM encoded public keys.
+// The first one verifies tokens without a kid header.
+func NewStaticKeySource(publicKeysPEM string) (KeySource, error) {
+	source := &staticKeySource{keys: make(map[string]*rsa.PublicKey)}
+
+	rest := []byte(strings.TrimSpace(publicKeysPEM))
+	for len(rest) > 0 {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return nil, errors.New("parse public keys: invalid PEM data")
+		}
+
+		publicKey, err := jwt.ParseRSAPublicKeyFromPEM(pem.EncodeToMemory(block))
+		if err != nil {
+			return nil, fmt.Errorf("parse public key: %w", err)
+		}
+		source.keys[keyID(publicKey)] = publicKey
+		if source.defaultKey == nil {
+			source.defaultKey = publicKey
+		}
+	}
+
+	if source.defaultKey == nil {
+		return nil, errors.New("static key source needs a public key")
+	}
+	return source, nil
+}
+
+func (s *staticKeySource) Key(_ context.Context, keyID string) (*rsa.PublicKey, error) {
+	if keyID == "" {
+		return s.defaultKey, nil
+	}
+
+	publicKey, ok := s.keys[keyID]
+	if !ok {
+		return nil, ErrUnknownKeyID
+	}
+	return publicKey, nil
+}
+
+type JWKSKeySourceOptions struct {
+	// URL is the jwks_uri from the user service's discovery document.
+	URL string
+	// HTTPClient defaults to a client with a ten second timeout.
+	HTTPClient *http.Client
+	// CacheTTL is how long fetched keys are used before they are fetched
+	// again. It defaults to DefaultJWKSCacheTTL.
+	CacheTTL time.Duration
+	// MinRefreshInterval limits how often an unknown kid may trigger a fetch,
+	// so forged tokens cannot hammer the user service. It defaults to
+	// DefaultJWKSMinRefreshInterval.
+	MinRefreshInterval time.Duration
+}
+
+const (
+	DefaultJWKSCacheTTL           = time.Hour
+	DefaultJWKSMinRefreshInterval = 30 * time.Second
+)
+
+type jwksKeySource struct {
+	opts JWKSKeySourceOptions
+	now  func() time.Time
+
+	// mu guards the cached keys and is never held across a fetch, so lookups
+	// of cached keys do not wait for the user service.
+	mu         sync.RWMutex
+	keys       map[string]*rsa.PublicKey
+	defaultKey *rsa.PublicKey
+	fetchedAt  time.Time
+
+	// refreshMu lets one caller fetch at a time; the others wait for it and
+	// then find its keys in the cache.
+	refreshMu   sync.Mutex
+	attemptedAt time.Time
+}
+
+// NewJWKSKeySource fetches the signing keys lazily and caches them. A kid that
+// is not cached yet triggers a refetch, which picks up rotated keys without a
+// restart.
+func NewJWKSKeySource(opts JWKSKeySourceOptions) KeySource {
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	if opts.CacheTTL <= 0 {
+		opts.CacheTTL = DefaultJWKSCacheTTL
+	}
+	if opts.MinRefreshInterval <= 0 {
+		opts.MinRefreshInterval = DefaultJWKSMinRefreshInterval
+	}
+
+	return &jwksKeySource{opts: opts, now: time.Now}
+}
+
+func (s *jwksKeySource) Key(ctx context.Context, keyID string) (*rsa.PublicKey, error) {
+	publicKey, fetchedAt := s.cachedKey(keyID)
+	if publicKey != nil && s.now().Sub(fetchedAt) < s.opts.CacheTTL {
+		return publicKey, nil
+	}
+
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	// Another caller may have refreshed the keys while this one waited.
+	now := s.now()
+	publicKey, fetchedAt = s.cachedKey(keyID)
+	if publicKey != nil && now.Sub(fetchedAt) < s.opts.CacheTTL {
+		return publicKey, nil
+	}
+
+	if now.Sub(s.attemptedAt) < s.opts.MinRefreshInterval {
+		switch {
+		case publicKey != nil:
+			return publicKey, nil
+		case fetchedAt.IsZero():
+			return nil, ErrKeyUnavailable
+		default:
+			return nil, ErrUnknownKeyID
+		}
+	}
+
+	s.attemptedAt = now
+	keys, defaultKey, err := s.fetch(ctx)
+	if err != nil {
+		// Keep serving a key we already know rather than failing every
+		// request while the user service is unreachable.
+		if publicKey != nil {
+			return publicKey, nil
+		}
+		return nil, fmt.Errorf("%w: %v", ErrKeyUnavailable, err)
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.defaultKey = defaultKey
+	s.fetchedAt = now
+	s.mu.Unlock()
+
+	publicKey, _ = s.cachedKey(keyID)
+	if publicKey == nil {
+		return nil, ErrUnknownKeyID
+	}
+	return publicKey, nil
+}
+
+// cachedKey looks keyID up in the cache and reports when the cache was
+// filled.
+func (s *jwksKeySource) cachedKey(keyID string) (*rsa.PublicKey, time.Time) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if keyID == "" {
+		return s.defaultKey, s.fetchedAt
+	}
+	return s.keys[keyID], s.fetchedAt
+}
+
+func (s *jwksKeySource) fetch(ctx context.Context) (map[string]*rsa.PublicKey, *rsa.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.opts.URL, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := s.opts.HTTPClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("fetch jwks: unexpected status %d", resp.StatusCode)
+	}
+
+	var jwks struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+		return nil, nil, fmt.Errorf("decode jwks: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(jwks.Keys))
+	var defaultKey *rsa.PublicKey
+	for _, jwk := range jwks.Keys {
+		if jwk.Kty != "RSA" {
+			continue
+		}
+
+		publicKey, err := rsaPublicKey(jwk.N, jwk.E)
+		if err != nil {
+			return nil, nil, fmt.Errorf("decode jwk %q: %w", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = publicKey
+		// The user service lists its active key first.
+		if defaultKey == nil {
+			defaultKey = publicKey
+		}
+	}
+
+	return keys, defaultKey, nil
+}
+
+func rsaPublicKey(n string, e string) (*rsa.PublicKey, error) {
+	modulus, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil {
+		return nil, err
+	}
+	exponent, err := base64.RawURLEncoding.DecodeString(e)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(modulus),
+		E: int(new(big.Int).SetBytes(exponent).Int64()),
+	}, nil
+}
+
+// keyID matches the kid the user service derives from the RFC 7638 thumbprint
+// of its keys, so a static PEM resolves the same kid the tokens carry.
+func keyID(publicKey *rsa.PublicKey) string {
+	n := base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+	e := base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+	thumbprint := sha256.Sum256([]byte(`{"e":"` + e + `","kty":"RSA","n":"` + n + `"}`))
+	return base64.RawURLEncoding.EncodeToString(thumbprint[:])
+}
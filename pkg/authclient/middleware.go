@@ -0,0 +1,101 @@
+package authclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+type claimsContextKey struct{}
+
+// NewContext returns a copy of ctx carrying claims.
+func NewContext(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsContextKey{}, claims)
+}
+
+// ClaimsFromContext returns the claims the middleware stored on the request
+// context, or nil when the request was not authenticated.
+func ClaimsFromContext(ctx context.Context) *Claims {
+	claims, _ := ctx.Value(claimsContextKey{}).(*Claims)
+	return claims
+}
+
+// BearerToken reads the token from an Authorization header value.
+func BearerToken(authHeader string) (string, error) {
+	if authHeader == "" {
+		return "", ErrMissingToken
+	}
+
+	scheme, token, ok := strings.Cut(authHeader, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", ErrInvalidToken
+	}
+	return token, nil
+}
+
+// EchoMiddleware rejects requests without a valid bearer token and stores the
+// claims on the request context for ClaimsFromContext.
+func EchoMiddleware(v *Verifier) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claims, err := v.verifyRequest(c.Request())
+			if err != nil {
+				code, message, challenge := authenticationFailure(err)
+				if challenge != "" {
+					c.Response().Header().Set(echo.HeaderWWWAuthenticate, challenge)
+				}
+				return echo.NewHTTPError(code, message)
+			}
+
+			c.SetRequest(c.Request().WithContext(NewContext(c.Request().Context(), claims)))
+			return next(c)
+		}
+	}
+}
+
+// HTTPMiddleware is EchoMiddleware for net/http handlers. Rejections are
+// answered with the same {"message": ...} body echo would send.
+func HTTPMiddleware(v *Verifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, err := v.verifyRequest(r)
+			if err != nil {
+				code, message, challenge := authenticationFailure(err)
+				if challenge != "" {
+					w.Header().Set(echo.HeaderWWWAuthenticate, challenge)
+				}
+				w.Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+				w.WriteHeader(code)
+				_ = json.NewEncoder(w).Encode(map[string]string{"message": message})
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), claims)))
+		})
+	}
+}
+
+func (v *Verifier) verifyRequest(r *http.Request) (*Claims, error) {
+	tokenString, err := BearerToken(r.Header.Get(echo.HeaderAuthorization))
+	if err != nil {
+		return nil, err
+	}
+	return v.Verify(r.Context(), tokenString)
+}
+
+// authenticationFailure follows RFC 6750: a missing token only gets the
+// Bearer challenge, a rejected one also says it is invalid.
+func authenticationFailure(err error) (code int, message string, challenge string) {
+	switch {
+	case errors.Is(err, ErrMissingToken):
+		return http.StatusUnauthorized, "Missing JWT token", "Bearer"
+	case errors.Is(err, ErrInvalidToken):
+		return http.StatusUnauthorized, "Invalid JWT token", `Bearer error="invalid_token"`
+	default:
+		return http.StatusServiceUnavailable, "Unable to verify JWT token", ""
+	}
+}
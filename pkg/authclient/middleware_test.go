@@ -0,0 +1,86 @@
+package authclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SawitProRecruitment/UserService/tools"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEchoMiddleware(t *testing.T) {
+	verifier, err := NewVerifier(Options{PublicKeyPEM: tools.MockRSAPublicKey()})
+	assert.NoError(t, err)
+	guid := uuid.New()
+
+	handler := EchoMiddleware(verifier)(func(c echo.Context) error {
+		return c.String(http.StatusOK, ClaimsFromContext(c.Request().Context()).UserGUID.String())
+	})
+
+	t.Run("when success authenticate a user token", func(t *testing.T) {
+		tokenString, _, err := tools.GenerateJWTToken(tools.GenerateJWTTokenParams{GUID: guid}, 1, tools.MockRSAPrivateKey())
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+tokenString)
+		rec := httptest.NewRecorder()
+
+		err = handler(echo.New().NewContext(req, rec))
+		assert.NoError(t, err)
+		assert.Equal(t, guid.String(), rec.Body.String())
+	})
+
+	t.Run("when error due to token is missing", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+
+		err := handler(echo.New().NewContext(req, rec))
+		assert.Equal(t, http.StatusUnauthorized, err.(*echo.HTTPError).Code)
+		assert.Equal(t, "Bearer", rec.Header().Get(echo.HeaderWWWAuthenticate))
+	})
+
+	t.Run("when error due to token is invalid", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer invalid")
+		rec := httptest.NewRecorder()
+
+		err := handler(echo.New().NewContext(req, rec))
+		assert.Equal(t, http.StatusUnauthorized, err.(*echo.HTTPError).Code)
+		assert.Equal(t, `Bearer error="invalid_token"`, rec.Header().Get(echo.HeaderWWWAuthenticate))
+	})
+}
+
+func TestHTTPMiddleware(t *testing.T) {
+	verifier, err := NewVerifier(Options{PublicKeyPEM: tools.MockRSAPublicKey()})
+	assert.NoError(t, err)
+
+	handler := HTTPMiddleware(verifier)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(ClaimsFromContext(r.Context()).ClientID))
+	}))
+
+	t.Run("when success authenticate a machine token", func(t *testing.T) {
+		tokenString, _, err := tools.GenerateClientJWTToken(tools.GenerateClientJWTTokenParams{ClientID: "billing-job"}, 1, tools.MockRSAPrivateKey())
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+tokenString)
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "billing-job", rec.Body.String())
+	})
+
+	t.Run("when error due to scheme is not bearer", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetBasicAuth("billing-job", "client-secret")
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.JSONEq(t, `{"message":"Invalid JWT token"}`, rec.Body.String())
+	})
+}
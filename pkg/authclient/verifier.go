@@ -0,0 +1,118 @@
+package authclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrMissingToken   = errors.New("missing token")
+	ErrInvalidToken   = errors.New("invalid token")
+	ErrUnknownKeyID   = errors.New("unknown signing key id")
+	ErrKeyUnavailable = errors.New("signing keys are unavailable")
+)
+
+// DefaultLeeway is the clock skew tolerated on exp, iat and nbf when Options
+// does not set one.
+const DefaultLeeway = 30 * time.Second
+
+type Options struct {
+	// JWKSURL and PublicKeyPEM are the two ways to get the verification keys;
+	// exactly one of them must be set. PublicKeyPEM may hold several keys to
+	// keep accepting tokens signed with a retired one.
+	JWKSURL      string
+	PublicKeyPEM string
+	// JWKS tunes the JWKS cache. Its URL is taken from JWKSURL.
+	JWKS JWKSKeySourceOptions
+
+	// Issuer and Audience, when set, must match the iss and aud claims.
+	Issuer   string
+	Audience string
+	// Leeway defaults to DefaultLeeway.
+	Leeway time.Duration
+}
+
+// Verifier checks the signature, issuer, audience and lifetime of access
+// tokens. It cannot see revocations; ask the user service's introspection
+// endpoint when a revoked token must be rejected before it expires.
+type Verifier struct {
+	keys          KeySource
+	parserOptions []jwt.ParserOption
+}
+
+func NewVerifier(opts Options) (*Verifier, error) {
+	var keys KeySource
+	switch {
+	case opts.JWKSURL != "" && opts.PublicKeyPEM != "":
+		return nil, errors.New("set either JWKSURL or PublicKeyPEM, not both")
+	case opts.JWKSURL != "":
+		jwksOpts := opts.JWKS
+		jwksOpts.URL = opts.JWKSURL
+		keys = NewJWKSKeySource(jwksOpts)
+	case opts.PublicKeyPEM != "":
+		var err error
+		keys, err = NewStaticKeySource(opts.PublicKeyPEM)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("set JWKSURL or PublicKeyPEM")
+	}
+
+	return NewVerifierWithKeySource(keys, opts), nil
+}
+
+// NewVerifierWithKeySource uses keys instead of the sources described by
+// opts.JWKSURL and opts.PublicKeyPEM.
+func NewVerifierWithKeySource(keys KeySource, opts Options) *Verifier {
+	leeway := opts.Leeway
+	if leeway == 0 {
+		leeway = DefaultLeeway
+	}
+
+	parserOptions := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(leeway),
+	}
+	if opts.Issuer != "" {
+		parserOptions = append(parserOptions, jwt.WithIssuer(opts.Issuer))
+	}
+	if opts.Audience != "" {
+		parserOptions = append(parserOptions, jwt.WithAudience(opts.Audience))
+	}
+
+	return &Verifier{keys: keys, parserOptions: parserOptions}
+}
+
+// Verify returns the claims of a valid token. Rejected tokens are reported as
+// ErrInvalidToken; ErrKeyUnavailable means the keys could not be fetched and
+// the token may well be valid.
+func (v *Verifier) Verify(ctx context.Context, tokenString string) (*Claims, error) {
+	if tokenString == "" {
+		return nil, ErrMissingToken
+	}
+
+	claims := new(Claims)
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		keyID, _ := token.Header["kid"].(string)
+		return v.keys.Key(ctx, keyID)
+	}, v.parserOptions...)
+	if errors.Is(err, ErrKeyUnavailable) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	// A token without user_guid must at least name the client it was issued to.
+	if claims.IsClient() && claims.ClientID == "" {
+		return nil, fmt.Errorf("%w: token has neither user_guid nor client_id", ErrInvalidToken)
+	}
+	return claims, nil
+}
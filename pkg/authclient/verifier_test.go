@@ -0,0 +1,256 @@
+package authclient
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/tools"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func generateTestKeyPEM(t *testing.T) (privatePEM string, publicPEM string) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	assert.NoError(t, err)
+
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	assert.NoError(t, err)
+
+	privatePEM = string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+	publicPEM = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}))
+	return
+}
+
+// jwksServer publishes the keys of whichever key ring is current, counting
+// how often it was asked.
+func jwksServer(t *testing.T, ring *atomic.Pointer[tools.KeyRing], fetches *int32) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(fetches, 1)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": ring.Load().JWKS()})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestVerifier(t *testing.T) {
+	params := tools.GenerateJWTTokenParams{
+		FullName: "SawitPro Mania",
+		GUID:     uuid.New(),
+		Issuer:   "http://localhost:8080",
+		Audience: []string{"user-service"},
+	}
+	opts := Options{PublicKeyPEM: tools.MockRSAPublicKey(), Issuer: "http://localhost:8080", Audience: "user-service"}
+
+	t.Run("when success verify a user token with a static key", func(t *testing.T) {
+		tokenString, _, err := tools.GenerateJWTToken(params, 1, tools.MockRSAPrivateKey())
+		assert.NoError(t, err)
+
+		verifier, err := NewVerifier(opts)
+		assert.NoError(t, err)
+
+		claims, err := verifier.Verify(context.Background(), tokenString)
+		assert.NoError(t, err)
+		assert.Equal(t, params.GUID, claims.UserGUID)
+		assert.Equal(t, params.FullName, claims.FullName)
+		assert.False(t, claims.IsClient())
+	})
+
+	t.Run("when success verify a machine token", func(t *testing.T) {
+		tokenString, _, err := tools.GenerateClientJWTToken(tools.GenerateClientJWTTokenParams{
+			ClientID: "billing-job",
+			Scope:    "users:read",
+			Issuer:   params.Issuer,
+			Audience: params.Audience,
+		}, 1, tools.MockRSAPrivateKey())
+		assert.NoError(t, err)
+
+		verifier, err := NewVerifier(opts)
+		assert.NoError(t, err)
+
+		claims, err := verifier.Verify(context.Background(), tokenString)
+		assert.NoError(t, err)
+		assert.True(t, claims.IsClient())
+		assert.Equal(t, "billing-job", claims.ClientID)
+		assert.True(t, claims.HasScope("users:read"))
+		assert.False(t, claims.HasScope("users:write"))
+	})
+
+	t.Run("when success verify a token that just expired within the leeway", func(t *testing.T) {
+		tokenString, _, err := tools.GenerateJWTToken(params, 0, tools.MockRSAPrivateKey())
+		assert.NoError(t, err)
+
+		verifier, err := NewVerifier(opts)
+		assert.NoError(t, err)
+
+		_, err = verifier.Verify(context.Background(), tokenString)
+		assert.NoError(t, err)
+	})
+
+	t.Run("when success pick up a rotated key from the jwks", func(t *testing.T) {
+		newPrivateKey, _ := generateTestKeyPEM(t)
+		oldRing, err := tools.NewKeyRing(tools.KeyRingOptions{PrivateKey: tools.MockRSAPrivateKey()})
+		assert.NoError(t, err)
+		newRing, err := tools.NewKeyRing(tools.KeyRingOptions{PrivateKey: newPrivateKey, RetiredPublicKeys: tools.MockRSAPublicKey()})
+		assert.NoError(t, err)
+
+		var ring atomic.Pointer[tools.KeyRing]
+		ring.Store(oldRing)
+		var fetches int32
+		server := jwksServer(t, &ring, &fetches)
+
+		verifier, err := NewVerifier(Options{JWKSURL: server.URL, Issuer: opts.Issuer, Audience: opts.Audience})
+		assert.NoError(t, err)
+		keys := verifier.keys.(*jwksKeySource)
+		now := time.Now()
+		keys.now = func() time.Time { return now }
+
+		oldToken, _, err := tools.GenerateJWTToken(params, 1, tools.MockRSAPrivateKey())
+		assert.NoError(t, err)
+		_, err = verifier.Verify(context.Background(), oldToken)
+		assert.NoError(t, err)
+		_, err = verifier.Verify(context.Background(), oldToken)
+		assert.NoError(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+
+		ring.Store(newRing)
+		newToken, _, err := tools.GenerateJWTToken(params, 1, newPrivateKey)
+		assert.NoError(t, err)
+
+		// The unknown kid may only trigger a refetch once the minimum refresh
+		// interval has passed.
+		_, err = verifier.Verify(context.Background(), newToken)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+
+		now = now.Add(DefaultJWKSMinRefreshInterval)
+		claims, err := verifier.Verify(context.Background(), newToken)
+		assert.NoError(t, err)
+		assert.Equal(t, params.GUID, claims.UserGUID)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&fetches))
+	})
+
+	t.Run("when success serve cached keys while a refetch is in flight", func(t *testing.T) {
+		ring, err := tools.NewKeyRing(tools.KeyRingOptions{PrivateKey: tools.MockRSAPrivateKey()})
+		assert.NoError(t, err)
+
+		var fetches int32
+		fetching := make(chan struct{})
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&fetches, 1) > 1 {
+				close(fetching)
+				<-release
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": ring.JWKS()})
+		}))
+		defer server.Close()
+
+		verifier, err := NewVerifier(Options{JWKSURL: server.URL, Issuer: opts.Issuer, Audience: opts.Audience})
+		assert.NoError(t, err)
+		keys := verifier.keys.(*jwksKeySource)
+		now := time.Now()
+		keys.now = func() time.Time { return now }
+
+		knownToken, _, err := tools.GenerateJWTToken(params, 1, tools.MockRSAPrivateKey())
+		assert.NoError(t, err)
+		_, err = verifier.Verify(context.Background(), knownToken)
+		assert.NoError(t, err)
+
+		now = now.Add(DefaultJWKSMinRefreshInterval)
+		otherPrivateKey, _ := generateTestKeyPEM(t)
+		unknownToken, _, err := tools.GenerateJWTToken(params, 1, otherPrivateKey)
+		assert.NoError(t, err)
+		done := make(chan error)
+		go func() {
+			_, err := verifier.Verify(context.Background(), unknownToken)
+			done <- err
+		}()
+		<-fetching
+
+		_, err = verifier.Verify(context.Background(), knownToken)
+		assert.NoError(t, err)
+
+		close(release)
+		assert.ErrorIs(t, <-done, ErrInvalidToken)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&fetches))
+	})
+}
+
+func TestVerifier_Error(t *testing.T) {
+	params := tools.GenerateJWTTokenParams{FullName: "SawitPro Mania", GUID: uuid.New(), Issuer: "http://localhost:8080"}
+
+	t.Run("when error due to options name no key", func(t *testing.T) {
+		_, err := NewVerifier(Options{})
+		assert.Error(t, err)
+	})
+
+	t.Run("when error due to token has expired", func(t *testing.T) {
+		tokenString, _, err := tools.GenerateJWTToken(params, -1, tools.MockRSAPrivateKey())
+		assert.NoError(t, err)
+
+		verifier, err := NewVerifier(Options{PublicKeyPEM: tools.MockRSAPublicKey()})
+		assert.NoError(t, err)
+
+		_, err = verifier.Verify(context.Background(), tokenString)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("when error due to issuer does not match", func(t *testing.T) {
+		tokenString, _, err := tools.GenerateJWTToken(params, 1, tools.MockRSAPrivateKey())
+		assert.NoError(t, err)
+
+		verifier, err := NewVerifier(Options{PublicKeyPEM: tools.MockRSAPublicKey(), Issuer: "https://accounts.example.com"})
+		assert.NoError(t, err)
+
+		_, err = verifier.Verify(context.Background(), tokenString)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("when error due to audience does not match", func(t *testing.T) {
+		tokenString, _, err := tools.GenerateJWTToken(params, 1, tools.MockRSAPrivateKey())
+		assert.NoError(t, err)
+
+		verifier, err := NewVerifier(Options{PublicKeyPEM: tools.MockRSAPublicKey(), Audience: "billing-service"})
+		assert.NoError(t, err)
+
+		_, err = verifier.Verify(context.Background(), tokenString)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("when error due to token is signed with another key", func(t *testing.T) {
+		otherPrivateKey, _ := generateTestKeyPEM(t)
+		tokenString, _, err := tools.GenerateJWTToken(params, 1, otherPrivateKey)
+		assert.NoError(t, err)
+
+		verifier, err := NewVerifier(Options{PublicKeyPEM: tools.MockRSAPublicKey()})
+		assert.NoError(t, err)
+
+		_, err = verifier.Verify(context.Background(), tokenString)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("when error due to jwks is unreachable", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+
+		tokenString, _, err := tools.GenerateJWTToken(params, 1, tools.MockRSAPrivateKey())
+		assert.NoError(t, err)
+
+		verifier, err := NewVerifier(Options{JWKSURL: server.URL})
+		assert.NoError(t, err)
+
+		_, err = verifier.Verify(context.Background(), tokenString)
+		assert.ErrorIs(t, err, ErrKeyUnavailable)
+	})
+}
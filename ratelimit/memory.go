@@ -0,0 +1,81 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps the buckets in the process, so every replica enforces the
+// limits on its own.
+type MemoryStore struct {
+	now func() time.Time
+
+	mu            sync.Mutex
+	buckets       map[string]*bucket
+	lastEvictedAt time.Time
+}
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+	// fullAt is when the bucket will have refilled completely, after which
+	// forgetting it changes nothing.
+	fullAt time.Time
+}
+
+// evictInterval bounds how often idle buckets are swept.
+const evictInterval = time.Minute
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	now := s.now()
+	interval := limit.refillInterval()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.evictIdle(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), updatedAt: now}
+		s.buckets[key] = b
+	}
+
+	refilled := float64(now.Sub(b.updatedAt)) / float64(interval)
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+refilled)
+	b.updatedAt = now
+
+	result := Result{}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration((1 - b.tokens) * float64(interval))
+	}
+	result.Remaining = int(b.tokens)
+	b.fullAt = now.Add(time.Duration((float64(limit.Burst) - b.tokens) * float64(interval)))
+	return result, nil
+}
+
+// evictIdle drops buckets that have refilled completely, at most once per
+// evictInterval. Callers must hold s.mu.
+func (s *MemoryStore) evictIdle(now time.Time) {
+	if now.Sub(s.lastEvictedAt) < evictInterval {
+		return
+	}
+	s.lastEvictedAt = now
+
+	for key, b := range s.buckets {
+		if !now.Before(b.fullAt) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	limit := Limit{PerMinute: 6, Burst: 3}
+
+	t.Run("when the burst is spent then the next take waits for a refill", func(t *testing.T) {
+		now := time.Now()
+		store := NewMemoryStore()
+		store.now = func() time.Time { return now }
+
+		for i := 2; i >= 0; i-- {
+			result, err := store.Take(ctx, "ip:192.0.2.1", limit)
+			assert.NoError(t, err)
+			assert.True(t, result.Allowed)
+			assert.Equal(t, i, result.Remaining)
+		}
+
+		result, err := store.Take(ctx, "ip:192.0.2.1", limit)
+		assert.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, 10*time.Second, result.RetryAfter)
+
+		now = now.Add(10 * time.Second)
+		result, err = store.Take(ctx, "ip:192.0.2.1", limit)
+		assert.NoError(t, err)
+		assert.True(t, result.Allowed)
+	})
+
+	t.Run("when keys differ then their buckets are separate", func(t *testing.T) {
+		store := NewMemoryStore()
+
+		for i := 0; i < 3; i++ {
+			_, _ = store.Take(ctx, "ip:192.0.2.1", limit)
+		}
+		result, err := store.Take(ctx, "ip:192.0.2.2", limit)
+		assert.NoError(t, err)
+		assert.True(t, result.Allowed)
+	})
+
+	t.Run("when a bucket refilled then it is evicted", func(t *testing.T) {
+		now := time.Now()
+		store := NewMemoryStore()
+		store.now = func() time.Time { return now }
+
+		_, _ = store.Take(ctx, "ip:192.0.2.1", limit)
+		now = now.Add(2 * time.Minute)
+		_, _ = store.Take(ctx, "ip:192.0.2.2", limit)
+
+		assert.Len(t, store.buckets, 1)
+	})
+}
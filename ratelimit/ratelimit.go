@@ -0,0 +1,66 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Store drivers accepted by NewStore.
+const (
+	DriverMemory = "memory"
+	DriverRedis  = "redis"
+)
+
+// Limit describes a token bucket: it holds at most Burst tokens and refills
+// PerMinute of them every minute.
+type Limit struct {
+	PerMinute int
+	Burst     int
+}
+
+// Enabled reports whether the limit should be enforced at all.
+func (l Limit) Enabled() bool {
+	return l.PerMinute > 0 && l.Burst > 0
+}
+
+// refillInterval is how long the bucket takes to earn one token back.
+func (l Limit) refillInterval() time.Duration {
+	return time.Minute / time.Duration(l.PerMinute)
+}
+
+// Result is the outcome of taking a token from a bucket.
+type Result struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+// Store keeps the buckets. Take removes one token from the bucket named key,
+// creating a full one the first time the key is seen.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// NewStore builds the store named by driver. The redis driver shares the
+// buckets between replicas through the server at redisURL; the memory driver
+// keeps them in the process.
+func NewStore(driver string, redisURL string) (Store, error) {
+	switch driver {
+	case "", DriverMemory:
+		return NewMemoryStore(), nil
+	case DriverRedis:
+		if redisURL == "" {
+			return nil, fmt.Errorf("rate limit store %q needs a redis url", driver)
+		}
+		opts, err := redis.ParseURL(redisURL)
+		if err != nil {
+			return nil, err
+		}
+		return NewRedisStore(redis.NewClient(opts)), nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit store %q", driver)
+	}
+}
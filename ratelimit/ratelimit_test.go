@@ -0,0 +1,23 @@
+package ratelimit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewStore(t *testing.T) {
+	store, err := NewStore(DriverMemory, "")
+	assert.NoError(t, err)
+	assert.IsType(t, &MemoryStore{}, store)
+
+	_, err = NewStore(DriverRedis, "")
+	assert.Error(t, err)
+
+	store, err = NewStore(DriverRedis, "redis://localhost:6379/0")
+	assert.NoError(t, err)
+	assert.IsType(t, &RedisStore{}, store)
+
+	_, err = NewStore("carrier-pigeon", "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// takeScript refills and takes from the bucket in one round trip. It reads
+// the clock from the Redis server so replicas with skewed clocks still agree
+// on how much a bucket has refilled. Times are in microseconds.
+var takeScript = redis.NewScript(`
+local burst = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+
+local clock = redis.call('TIME')
+local now = tonumber(clock[1]) * 1000000 + tonumber(clock[2])
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'updated_at')
+local tokens = tonumber(state[1])
+local updated_at = tonumber(state[2])
+if tokens == nil or updated_at == nil then
+	tokens = burst
+	updated_at = now
+end
+
+tokens = math.min(burst, tokens + math.max(0, now - updated_at) / interval)
+
+local allowed = 0
+local retry_after = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry_after = math.ceil((1 - tokens) * interval)
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated_at', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil((burst - tokens) * interval / 1000) + 1)
+
+return {allowed, math.floor(tokens), retry_after}
+`)
+
+// keyPrefix keeps the buckets apart from anything else in the same database.
+const keyPrefix = "ratelimit:"
+
+// RedisStore keeps the buckets in Redis, or anything speaking its protocol, so
+// every replica draws from the same buckets. A bucket expires once it would
+// have refilled completely.
+type RedisStore struct {
+	client redis.Scripter
+}
+
+func NewRedisStore(client redis.Scripter) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+func (s *RedisStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	interval := limit.refillInterval().Microseconds()
+
+	values, err := takeScript.Run(ctx, s.client, []string{keyPrefix + key}, limit.Burst, interval).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+
+	return Result{
+		Allowed:    values[0] == 1,
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Microsecond,
+	}, nil
+}
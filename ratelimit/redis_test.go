@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisStore(t *testing.T) {
+	ctx := context.Background()
+	limit := Limit{PerMinute: 6, Burst: 3}
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	t.Run("when the burst is spent then the next take waits for a refill", func(t *testing.T) {
+		server.FlushAll()
+		now := time.Now()
+		server.SetTime(now)
+		store := NewRedisStore(client)
+
+		for i := 2; i >= 0; i-- {
+			result, err := store.Take(ctx, "ip:192.0.2.1", limit)
+			assert.NoError(t, err)
+			assert.True(t, result.Allowed)
+			assert.Equal(t, i, result.Remaining)
+		}
+
+		result, err := store.Take(ctx, "ip:192.0.2.1", limit)
+		assert.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, 10*time.Second, result.RetryAfter)
+
+		server.SetTime(now.Add(10 * time.Second))
+		result, err = store.Take(ctx, "ip:192.0.2.1", limit)
+		assert.NoError(t, err)
+		assert.True(t, result.Allowed)
+	})
+
+	t.Run("when two stores share a server then they share the buckets", func(t *testing.T) {
+		server.FlushAll()
+		server.SetTime(time.Now())
+		first := NewRedisStore(client)
+		second := NewRedisStore(redis.NewClient(&redis.Options{Addr: server.Addr()}))
+
+		for i := 0; i < 3; i++ {
+			_, _ = first.Take(ctx, "phone:+62345678901", limit)
+		}
+		result, err := second.Take(ctx, "phone:+62345678901", limit)
+		assert.NoError(t, err)
+		assert.False(t, result.Allowed)
+	})
+
+	t.Run("when a bucket is taken from then it expires once refilled", func(t *testing.T) {
+		server.FlushAll()
+		server.SetTime(time.Now())
+		store := NewRedisStore(client)
+
+		_, err := store.Take(ctx, "ip:192.0.2.1", limit)
+		assert.NoError(t, err)
+		assert.True(t, server.Exists("ratelimit:ip:192.0.2.1"))
+
+		server.FastForward(11 * time.Second)
+		assert.False(t, server.Exists("ratelimit:ip:192.0.2.1"))
+	})
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// GetDeletedUserLoginByPhoneNumber finds the account most recently deleted
+// by its owner with phoneNumber after deletedAfter whose data has not been
+// purged yet, so logging in can restore it. Accounts staff deleted are left
+// out.
+func (r *Repository) GetDeletedUserLoginByPhoneNumber(ctx context.Context, phoneNumber string, deletedAfter time.Time) (
+	output LoginUserOutput,
+	err error,
+) {
+	err = r.Db.QueryRowContext(
+		ctx,
+		`SELECT u.id, u.guid, u.full_name, u.password, u.password_changed_at, u.phone_verified_at, u.disabled_at,
+			u.deleted_at, t.enabled_at IS NOT NULL
+		FROM users u
+		LEFT JOIN user_totp t ON t.user_id = u.id
+		WHERE u.phone_number = $1 AND u.deleted_at > $2 AND u.deleted_by = u.guid AND u.purged_at IS NULL
+		ORDER BY u.deleted_at DESC
+		LIMIT 1`,
+		phoneNumber, deletedAfter,
+	).Scan(&output.ID, &output.GUID, &output.FullName, &output.Password, &output.PasswordChangedAt, &output.PhoneVerifiedAt,
+		&output.DisabledAt, &output.DeletedAt, &output.TOTPEnabled)
+	return
+}
+
+// RestoreUser undoes the deletion of an account its owner deleted that has
+// not been purged. It fails with a conflict when the phone number was taken
+// by a new account in the meantime, and reports false when there was nothing
+// to restore.
+func (r *Repository) RestoreUser(ctx context.Context, userID int) (restored bool, err error) {
+	result, err := r.Db.ExecContext(
+		ctx,
+		`UPDATE users SET deleted_at = NULL, deleted_by = NULL
+		WHERE id = $1 AND deleted_at IS NOT NULL AND deleted_by = guid AND purged_at IS NULL`,
+		userID,
+	)
+	if err != nil {
+		return false, ConvertPGError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, ConvertPGError(err)
+	}
+	return affected == 1, nil
+}
+
+// PurgeDeletedUsers erases the personal data of up to limit accounts deleted
+// before deletedBefore. Rows only about the user are removed; the users row
+// itself is anonymized and kept, because the audit trail still points at it.
+func (r *Repository) PurgeDeletedUsers(ctx context.Context, deletedBefore time.Time, limit int) (purged int, err error) {
+	err = r.Db.QueryRowContext(
+		ctx,
+		`WITH doomed AS (
+			SELECT id FROM users
+			WHERE deleted_at < $1 AND purged_at IS NULL
+			ORDER BY deleted_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		), refresh_tokens_removed AS (
+			DELETE FROM refresh_tokens WHERE user_id IN (SELECT id FROM doomed)
+		), authorization_codes_removed AS (
+			DELETE FROM oauth_authorization_codes WHERE user_id IN (SELECT id FROM doomed)
+		), consents_removed AS (
+			DELETE FROM oauth_consents WHERE user_id IN (SELECT id FROM doomed)
+		), otp_codes_removed AS (
+			DELETE FROM otp_codes WHERE user_id IN (SELECT id FROM doomed)
+		), recovery_codes_removed AS (
+			DELETE FROM totp_recovery_codes WHERE user_id IN (SELECT id FROM doomed)
+		), totp_removed AS (
+			DELETE FROM user_totp WHERE user_id IN (SELECT id FROM doomed)
+		), challenges_removed AS (
+			DELETE FROM login_challenges WHERE user_id IN (SELECT id FROM doomed)
+		), history_removed AS (
+			DELETE FROM password_history WHERE user_id IN (SELECT id FROM doomed)
+		), roles_removed AS (
+			DELETE FROM user_roles WHERE user_id IN (SELECT id FROM doomed)
+		), exports_removed AS (
+			DELETE FROM data_exports WHERE user_id IN (SELECT id FROM doomed)
+		), throttles_removed AS (
+			DELETE FROM login_throttles WHERE subject IN (SELECT 'user:' || id FROM doomed)
+		), anonymized AS (
+			UPDATE users SET full_name = 'Deleted user', phone_number = 'deleted:' || id, password = '',
+				phone_verified_at = NULL, pending_phone_number = NULL, purged_at = NOW()
+			WHERE id IN (SELECT id FROM doomed)
+			RETURNING id
+		)
+		SELECT COUNT(*) FROM anonymized`,
+		deletedBefore, limit,
+	).Scan(&purged)
+	return purged, ConvertPGError(err)
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// CreateAuditLog appends an entry to the audit trail.
+func (r *Repository) CreateAuditLog(ctx context.Context, entry *AuditLog) (err error) {
+	details := []byte("{}")
+	if entry.Details != nil {
+		details, err = json.Marshal(entry.Details)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = r.Db.QueryRowContext(
+		ctx,
+		`INSERT INTO audit_logs (actor_guid, action, target_user_id, ip_address, details)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`,
+		entry.ActorGUID, entry.Action, entry.TargetUserID, entry.IPAddress, details,
+	).Scan(&entry.ID, &entry.CreatedAt)
+	return ConvertPGError(err)
+}
+
+// ListUserAuditLogs lists the audit entries about the user, newest first.
+func (r *Repository) ListUserAuditLogs(ctx context.Context, userID int) (entries []*AuditLog, err error) {
+	rows, err := r.Db.QueryContext(
+		ctx,
+		`SELECT id, actor_guid, action, target_user_id, ip_address, details, created_at
+		FROM audit_logs
+		WHERE target_user_id = $1
+		ORDER BY id DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, ConvertPGError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry := new(AuditLog)
+		var details []byte
+		err = rows.Scan(&entry.ID, &entry.ActorGUID, &entry.Action, &entry.TargetUserID, &entry.IPAddress, &details,
+			&entry.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal(details, &entry.Details)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
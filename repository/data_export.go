@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const dataExportColumns = `e.id, e.guid, e.user_id, e.format, e.status, e.error, e.created_at, e.started_at,
+	e.completed_at, e.expires_at, u.guid`
+
+func scanDataExport(row rowScanner, export *DataExport, dest ...any) error {
+	return row.Scan(append([]any{
+		&export.ID, &export.GUID, &export.UserID, &export.Format, &export.Status, &export.Error, &export.CreatedAt,
+		&export.StartedAt, &export.CompletedAt, &export.ExpiresAt, &export.UserGUID,
+	}, dest...)...)
+}
+
+// CreateDataExport queues an export for the user export.UserGUID points at.
+// It returns sql.ErrNoRows when that user does not exist.
+func (r *Repository) CreateDataExport(ctx context.Context, export *DataExport) (err error) {
+	err = r.Db.QueryRowContext(
+		ctx,
+		`INSERT INTO data_exports (user_id, format)
+		SELECT id, $2 FROM users WHERE guid = $1 AND deleted_at IS NULL
+		RETURNING id, guid, user_id, status, created_at`,
+		export.UserGUID, export.Format,
+	).Scan(&export.ID, &export.GUID, &export.UserID, &export.Status, &export.CreatedAt)
+	return ConvertPGError(err)
+}
+
+// GetUserDataExport finds the export guid, as long as it belongs to the user
+// userGUID.
+func (r *Repository) GetUserDataExport(ctx context.Context, userGUID uuid.UUID, guid uuid.UUID) (export *DataExport, err error) {
+	export = new(DataExport)
+	err = scanDataExport(r.Db.QueryRowContext(
+		ctx,
+		`SELECT `+dataExportColumns+`
+		FROM data_exports e
+		JOIN users u ON u.id = e.user_id
+		WHERE e.guid = $1 AND u.guid = $2`,
+		guid, userGUID,
+	), export)
+	return export, err
+}
+
+// GetDataExportArchive loads the archive of the export guid while it is ready,
+// has not expired and its owner has not deleted their account.
+func (r *Repository) GetDataExportArchive(ctx context.Context, guid uuid.UUID) (export *DataExport, archive []byte, err error) {
+	export = new(DataExport)
+	err = scanDataExport(r.Db.QueryRowContext(
+		ctx,
+		`SELECT `+dataExportColumns+`, e.archive
+		FROM data_exports e
+		JOIN users u ON u.id = e.user_id
+		WHERE e.guid = $1 AND e.status = 'ready' AND e.expires_at > NOW() AND u.deleted_at IS NULL`,
+		guid,
+	), export, &archive)
+	return export, archive, err
+}
+
+// ClaimDataExport marks the oldest queued export as processing and returns
+// it, so that only one worker builds it. Exports left processing since before
+// staleBefore are taken to belong to a worker that died and are claimed
+// again. It returns sql.ErrNoRows when there is nothing to do.
+func (r *Repository) ClaimDataExport(ctx context.Context, staleBefore time.Time) (export *DataExport, err error) {
+	export = new(DataExport)
+	err = scanDataExport(r.Db.QueryRowContext(
+		ctx,
+		`UPDATE data_exports e SET status = 'processing', started_at = NOW()
+		FROM users u
+		WHERE e.id = (
+			SELECT id FROM data_exports
+			WHERE status = 'pending' OR (status = 'processing' AND started_at < $1)
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		) AND u.id = e.user_id
+		RETURNING `+dataExportColumns,
+		staleBefore,
+	), export)
+	return export, err
+}
+
+// CompleteDataExport stores the archive of an export and makes it ready until
+// expiresAt.
+func (r *Repository) CompleteDataExport(ctx context.Context, id int, archive []byte, expiresAt time.Time) error {
+	_, err := r.Db.ExecContext(
+		ctx,
+		`UPDATE data_exports SET status = 'ready', archive = $2, completed_at = NOW(), expires_at = $3
+		WHERE id = $1 AND status = 'processing'`,
+		id, archive, expiresAt,
+	)
+	return ConvertPGError(err)
+}
+
+// FailDataExport records why an export could not be built.
+func (r *Repository) FailDataExport(ctx context.Context, id int, message string) error {
+	_, err := r.Db.ExecContext(
+		ctx,
+		`UPDATE data_exports SET status = 'failed', error = LEFT($2, 255), completed_at = NOW()
+		WHERE id = $1 AND status = 'processing'`,
+		id, message,
+	)
+	return ConvertPGError(err)
+}
+
+// ExpireDataExports deletes the archives of ready exports that expired
+// before now.
+func (r *Repository) ExpireDataExports(ctx context.Context, now time.Time) (expired int, err error) {
+	result, err := r.Db.ExecContext(
+		ctx,
+		"UPDATE data_exports SET status = 'expired', archive = NULL WHERE status = 'ready' AND expires_at <= $1",
+		now,
+	)
+	if err != nil {
+		return 0, ConvertPGError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, ConvertPGError(err)
+	}
+	return int(affected), nil
+}
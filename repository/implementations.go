@@ -2,27 +2,45 @@ package repository
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
 
-func (r *Repository) CreateUser(ctx context.Context, user *User) (err error) {
-	err = r.Db.QueryRowContext(
-		ctx,
-		"INSERT INTO users (full_name, phone_number, password) VALUES ($1, $2, $3) RETURNING *",
-		user.FullName,
-		user.PhoneNumber,
-		user.Password,
-	).Scan(
+// userColumns lists the users columns in the order scanUser reads them.
+const userColumns = `id, guid, full_name, phone_number, password, phone_verified_at, pending_phone_number,
+	disabled_at, created_at, last_modified_at, deleted_at`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanUser(row rowScanner, user *User) error {
+	return row.Scan(
 		&user.ID,
 		&user.GUID,
 		&user.FullName,
 		&user.PhoneNumber,
 		&user.Password,
+		&user.PhoneVerifiedAt,
+		&user.PendingPhoneNumber,
+		&user.DisabledAt,
 		&user.CreatedAt,
 		&user.LastModifiedAt,
 		&user.DeletedAt,
 	)
+}
+
+func (r *Repository) CreateUser(ctx context.Context, user *User) (err error) {
+	err = scanUser(r.Db.QueryRowContext(
+		ctx,
+		"INSERT INTO users (full_name, phone_number, password) VALUES ($1, $2, $3) RETURNING "+userColumns,
+		user.FullName,
+		user.PhoneNumber,
+		user.Password,
+	), user)
 
 	return ConvertPGError(err)
 }
@@ -33,9 +51,14 @@ func (r *Repository) GetUserLoginByPhoneNumber(ctx context.Context, phoneNumber
 ) {
 	err = r.Db.QueryRowContext(
 		ctx,
-		"SELECT guid, full_name, password FROM users WHERE phone_number = $1 AND deleted_at IS NULL",
+		`SELECT u.id, u.guid, u.full_name, u.password, u.password_changed_at, u.phone_verified_at, u.disabled_at,
+			t.enabled_at IS NOT NULL
+		FROM users u
+		LEFT JOIN user_totp t ON t.user_id = u.id
+		WHERE u.phone_number = $1 AND u.deleted_at IS NULL`,
 		phoneNumber,
-	).Scan(&output.GUID, &output.FullName, &output.Password)
+	).Scan(&output.ID, &output.GUID, &output.FullName, &output.Password, &output.PasswordChangedAt, &output.PhoneVerifiedAt,
+		&output.DisabledAt, &output.TOTPEnabled)
 	if err != nil {
 		return
 	}
@@ -45,43 +68,219 @@ func (r *Repository) GetUserLoginByPhoneNumber(ctx context.Context, phoneNumber
 func (r *Repository) GetUserByGUID(ctx context.Context, guid uuid.UUID) (user *User, err error) {
 	user = new(User)
 
-	err = r.Db.QueryRowContext(
+	err = scanUser(r.Db.QueryRowContext(
 		ctx,
-		"SELECT * FROM users WHERE guid = $1 AND deleted_at IS NULL",
+		"SELECT "+userColumns+" FROM users WHERE guid = $1 AND deleted_at IS NULL",
 		guid,
-	).Scan(
-		&user.ID,
-		&user.GUID,
-		&user.FullName,
-		&user.PhoneNumber,
-		&user.Password,
-		&user.CreatedAt,
-		&user.LastModifiedAt,
-		&user.DeletedAt,
-	)
+	), user)
 	if err != nil {
 		return
 	}
 	return
 }
 
-func (r *Repository) UpdateUser(ctx context.Context, user *User) error {
-	err := r.Db.QueryRowContext(
+// ListUsers returns up to input.Limit users, newest first, with ties on
+// created_at broken by id so every user has a stable position to continue
+// after.
+func (r *Repository) ListUsers(ctx context.Context, input ListUsersInput) (users []*User, err error) {
+	var afterCreatedAt *time.Time
+	var afterID int
+	if input.After != nil {
+		afterCreatedAt = &input.After.CreatedAt
+		afterID = input.After.ID
+	}
+
+	rows, err := r.Db.QueryContext(
 		ctx,
-		"UPDATE users SET full_name = $1, phone_number = $2 , last_modified_at = NOW() WHERE id = $3 RETURNING *",
-		user.FullName, user.PhoneNumber, user.ID,
-	).Scan(
-		&user.ID,
-		&user.GUID,
-		&user.FullName,
-		&user.PhoneNumber,
-		&user.Password,
-		&user.CreatedAt,
-		&user.LastModifiedAt,
-		&user.DeletedAt,
+		`SELECT `+userColumns+` FROM users
+		WHERE deleted_at IS NULL
+			AND ($1::timestamp IS NULL OR created_at >= $1)
+			AND ($2::timestamp IS NULL OR created_at < $2)
+			AND ($3::boolean IS NULL OR (phone_verified_at IS NOT NULL) = $3)
+			AND ($4::boolean IS NULL OR (disabled_at IS NOT NULL) = $4)
+			AND ($5 = '' OR phone_number LIKE $5 || '%')
+			AND ($6 = '' OR full_name ILIKE '%' || $6 || '%')
+			AND ($7::timestamp IS NULL OR (created_at, id) < ($7, $8))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $9`,
+		input.CreatedFrom, input.CreatedTo, input.PhoneVerified, input.Disabled,
+		escapeLike(input.PhonePrefix), escapeLike(input.NameContains), afterCreatedAt, afterID, input.Limit,
 	)
+	if err != nil {
+		return nil, ConvertPGError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		user := new(User)
+		err = scanUser(rows, user)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+// escapeLike quotes the LIKE wildcards in s so it only matches literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// UpdateUser saves the profile fields. Setting a different PhoneNumber
+// directly, as staff can, leaves the new number unverified.
+func (r *Repository) UpdateUser(ctx context.Context, user *User) error {
+	err := scanUser(r.Db.QueryRowContext(
+		ctx,
+		`UPDATE users SET full_name = $1, phone_number = $2, pending_phone_number = $3,
+			phone_verified_at = CASE WHEN phone_number = $2 THEN phone_verified_at END, last_modified_at = NOW()
+		WHERE id = $4 RETURNING `+userColumns,
+		user.FullName, user.PhoneNumber, user.PendingPhoneNumber, user.ID,
+	), user)
 	if err != nil {
 		return ConvertPGError(err)
 	}
 	return nil
 }
+
+// UpdateUserPassword sets a new password. The replaced hash goes to the
+// password history, which is trimmed so that together with the current
+// password it holds no more than historySize passwords.
+func (r *Repository) UpdateUserPassword(ctx context.Context, userID int, hashedPassword string, historySize int) error {
+	_, err := r.Db.ExecContext(
+		ctx,
+		`WITH previous AS (
+			SELECT password FROM users WHERE id = $2 AND deleted_at IS NULL
+		), updated AS (
+			UPDATE users SET password = $1, password_changed_at = NOW(), last_modified_at = NOW()
+			WHERE id = $2 AND deleted_at IS NULL
+			RETURNING id
+		), recorded AS (
+			INSERT INTO password_history (user_id, password_hash)
+			SELECT $2, password FROM previous
+			WHERE $3 > 1 AND EXISTS (SELECT 1 FROM updated)
+		)
+		DELETE FROM password_history
+		WHERE user_id = $2 AND id NOT IN (
+			SELECT id FROM password_history WHERE user_id = $2 ORDER BY id DESC LIMIT GREATEST($3 - 2, 0)
+		)`,
+		hashedPassword, userID, historySize,
+	)
+	return ConvertPGError(err)
+}
+
+// GetPasswordHistory returns the hashes of the user's latest replaced
+// passwords, newest first.
+func (r *Repository) GetPasswordHistory(ctx context.Context, userID int, limit int) (hashes []string, err error) {
+	rows, err := r.Db.QueryContext(
+		ctx,
+		"SELECT password_hash FROM password_history WHERE user_id = $1 ORDER BY id DESC LIMIT $2",
+		userID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var hash string
+		err = rows.Scan(&hash)
+		if err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+	return hashes, rows.Err()
+}
+
+// RehashUserPassword swaps in a new hash of the same password. It only
+// replaces currentHash, so a password changed in the meantime is left alone,
+// and reports false in that case.
+func (r *Repository) RehashUserPassword(ctx context.Context, userID int, currentHash string, newHash string) (updated bool, err error) {
+	result, err := r.Db.ExecContext(
+		ctx,
+		"UPDATE users SET password = $1 WHERE id = $2 AND password = $3 AND deleted_at IS NULL",
+		newHash, userID, currentHash,
+	)
+	if err != nil {
+		return false, ConvertPGError(err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (r *Repository) MarkUserPhoneVerified(ctx context.Context, userID int) error {
+	_, err := r.Db.ExecContext(
+		ctx,
+		"UPDATE users SET phone_verified_at = NOW() WHERE id = $1 AND phone_verified_at IS NULL AND deleted_at IS NULL",
+		userID,
+	)
+	return ConvertPGError(err)
+}
+
+// ConfirmUserPendingPhoneNumber swaps the pending phone number in as verified.
+// It reports false when phoneNumber is no longer the pending number, which
+// happens when the user asked for another change in the meantime.
+func (r *Repository) ConfirmUserPendingPhoneNumber(ctx context.Context, userID int, phoneNumber string) (confirmed bool, err error) {
+	result, err := r.Db.ExecContext(
+		ctx,
+		`UPDATE users SET phone_number = pending_phone_number, pending_phone_number = NULL, phone_verified_at = NOW(),
+			last_modified_at = NOW()
+		WHERE id = $1 AND pending_phone_number = $2 AND deleted_at IS NULL`,
+		userID,
+		phoneNumber,
+	)
+	if err != nil {
+		return false, ConvertPGError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, ConvertPGError(err)
+	}
+	return affected == 1, nil
+}
+
+// SetUserDisabled disables the user, or enables them again. It reports false
+// when the account was already in that state.
+func (r *Repository) SetUserDisabled(ctx context.Context, userID int, disabled bool) (changed bool, err error) {
+	query := "UPDATE users SET disabled_at = NOW() WHERE id = $1 AND disabled_at IS NULL AND deleted_at IS NULL"
+	if !disabled {
+		query = "UPDATE users SET disabled_at = NULL WHERE id = $1 AND disabled_at IS NOT NULL AND deleted_at IS NULL"
+	}
+
+	result, err := r.Db.ExecContext(ctx, query, userID)
+	if err != nil {
+		return false, ConvertPGError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, ConvertPGError(err)
+	}
+	return affected == 1, nil
+}
+
+// DeleteUser soft-deletes the user, which frees their phone number for a new
+// account. It reports false when the user was already deleted.
+func (r *Repository) DeleteUser(ctx context.Context, userID int, deletedBy uuid.UUID) (deleted bool, err error) {
+	result, err := r.Db.ExecContext(
+		ctx,
+		"UPDATE users SET deleted_at = NOW(), deleted_by = $2 WHERE id = $1 AND deleted_at IS NULL",
+		userID,
+		deletedBy,
+	)
+	if err != nil {
+		return false, ConvertPGError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, ConvertPGError(err)
+	}
+	return affected == 1, nil
+}
@@ -6,6 +6,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -14,5 +15,69 @@ type RepositoryInterface interface {
 	CreateUser(ctx context.Context, user *User) (err error)
 	GetUserLoginByPhoneNumber(ctx context.Context, phoneNumber string) (output LoginUserOutput, err error)
 	GetUserByGUID(ctx context.Context, guid uuid.UUID) (user *User, err error)
+	ListUsers(ctx context.Context, input ListUsersInput) (users []*User, err error)
 	UpdateUser(ctx context.Context, user *User) error
+	UpdateUserPassword(ctx context.Context, userID int, hashedPassword string, historySize int) error
+	GetPasswordHistory(ctx context.Context, userID int, limit int) (hashes []string, err error)
+	RehashUserPassword(ctx context.Context, userID int, currentHash string, newHash string) (updated bool, err error)
+	MarkUserPhoneVerified(ctx context.Context, userID int) error
+	ConfirmUserPendingPhoneNumber(ctx context.Context, userID int, phoneNumber string) (confirmed bool, err error)
+	SetUserDisabled(ctx context.Context, userID int, disabled bool) (changed bool, err error)
+	DeleteUser(ctx context.Context, userID int, deletedBy uuid.UUID) (deleted bool, err error)
+	GetDeletedUserLoginByPhoneNumber(ctx context.Context, phoneNumber string, deletedAfter time.Time) (output LoginUserOutput, err error)
+	RestoreUser(ctx context.Context, userID int) (restored bool, err error)
+	PurgeDeletedUsers(ctx context.Context, deletedBefore time.Time, limit int) (purged int, err error)
+	CreateRefreshToken(ctx context.Context, token *RefreshToken) (err error)
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (token *RefreshToken, err error)
+	MarkRefreshTokenUsed(ctx context.Context, id int) (marked bool, err error)
+	RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID) error
+	RevokeUserRefreshTokens(ctx context.Context, userGUID uuid.UUID) error
+	GetOAuthClientByClientID(ctx context.Context, clientID string) (client *OAuthClient, err error)
+	CreateOAuthAuthorizationCode(ctx context.Context, code *OAuthAuthorizationCode) (err error)
+	GetOAuthAuthorizationCodeByHash(ctx context.Context, codeHash string) (code *OAuthAuthorizationCode, err error)
+	MarkOAuthAuthorizationCodeUsed(ctx context.Context, id int, refreshTokenFamilyID uuid.UUID) (marked bool, err error)
+	GetOAuthConsent(ctx context.Context, userID int, oauthClientID int) (consent *OAuthConsent, err error)
+	UpsertOAuthConsent(ctx context.Context, consent *OAuthConsent) (err error)
+	CreateOTPCode(ctx context.Context, code *OTPCode) (err error)
+	GetLatestOTPCode(ctx context.Context, userID int, purpose string) (code *OTPCode, err error)
+	GetOTPCodeByVerificationTokenHash(ctx context.Context, tokenHash string, purpose string) (code *OTPCode, err error)
+	UseOTPCodeAttempt(ctx context.Context, id int, maxAttempts int) (used bool, err error)
+	MarkOTPCodeVerified(ctx context.Context, id int, verificationTokenHash string) (marked bool, err error)
+	ConsumeOTPCode(ctx context.Context, id int) (consumed bool, err error)
+	CountOTPCodesSentSince(ctx context.Context, phoneNumber string, since time.Time) (count int, err error)
+	UpsertPendingUserTOTP(ctx context.Context, userID int, secretCiphertext string) error
+	GetUserTOTP(ctx context.Context, userID int) (totp *UserTOTP, err error)
+	EnableUserTOTP(ctx context.Context, userID int, usedStep int64, recoveryCodeHashes []string) (enabled bool, err error)
+	MarkUserTOTPStepUsed(ctx context.Context, userID int, step int64) (marked bool, err error)
+	UseTOTPRecoveryCode(ctx context.Context, userID int, codeHash string) (used bool, err error)
+	CreateLoginChallenge(ctx context.Context, challenge *LoginChallenge) (err error)
+	GetLoginChallengeByTokenHash(ctx context.Context, tokenHash string) (challenge *LoginChallenge, err error)
+	UseLoginChallengeAttempt(ctx context.Context, id int, maxAttempts int) (used bool, err error)
+	ConsumeLoginChallenge(ctx context.Context, id int) (consumed bool, err error)
+	GetLoginThrottle(ctx context.Context, subject string) (throttle *LoginThrottle, err error)
+	RecordLoginFailure(ctx context.Context, subject string, resetAfter time.Duration) (failedAttempts int, err error)
+	BlockLoginSubject(ctx context.Context, subject string, until time.Time) error
+	ClearLoginThrottle(ctx context.Context, subject string) error
+	GetUserAccess(ctx context.Context, userID int) (access UserAccess, err error)
+	AssignUserRole(ctx context.Context, userID int, roleName string) (found bool, err error)
+	RemoveUserRole(ctx context.Context, userID int, roleName string) error
+	CreateAuditLog(ctx context.Context, entry *AuditLog) (err error)
+	ListUserAuditLogs(ctx context.Context, userID int) (entries []*AuditLog, err error)
+	GetUserSessions(ctx context.Context, userID int) (sessions []UserSession, err error)
+	ListUserOAuthConsents(ctx context.Context, userID int) (consents []*UserOAuthConsent, err error)
+	CreateDataExport(ctx context.Context, export *DataExport) (err error)
+	GetUserDataExport(ctx context.Context, userGUID uuid.UUID, guid uuid.UUID) (export *DataExport, err error)
+	GetDataExportArchive(ctx context.Context, guid uuid.UUID) (export *DataExport, archive []byte, err error)
+	ClaimDataExport(ctx context.Context, staleBefore time.Time) (export *DataExport, err error)
+	CompleteDataExport(ctx context.Context, id int, archive []byte, expiresAt time.Time) error
+	FailDataExport(ctx context.Context, id int, message string) error
+	ExpireDataExports(ctx context.Context, now time.Time) (expired int, err error)
+}
+
+// TokenRevocationRepositoryInterface is the store JWTMiddleware consults to
+// reject access tokens that were revoked before they expired.
+type TokenRevocationRepositoryInterface interface {
+	RevokeAccessToken(ctx context.Context, token RevokedAccessToken) error
+	RevokeUserAccessTokens(ctx context.Context, userGUID uuid.UUID, revokedBefore time.Time) error
+	IsAccessTokenRevoked(ctx context.Context, input IsAccessTokenRevokedInput) (revoked bool, err error)
 }
@@ -7,6 +7,7 @@ package repository
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	uuid "github.com/google/uuid"
@@ -35,6 +36,222 @@ func (m *MockRepositoryInterface) EXPECT() *MockRepositoryInterfaceMockRecorder
 	return m.recorder
 }
 
+// AssignUserRole mocks base method.
+func (m *MockRepositoryInterface) AssignUserRole(ctx context.Context, userID int, roleName string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssignUserRole", ctx, userID, roleName)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AssignUserRole indicates an expected call of AssignUserRole.
+func (mr *MockRepositoryInterfaceMockRecorder) AssignUserRole(ctx, userID, roleName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignUserRole", reflect.TypeOf((*MockRepositoryInterface)(nil).AssignUserRole), ctx, userID, roleName)
+}
+
+// BlockLoginSubject mocks base method.
+func (m *MockRepositoryInterface) BlockLoginSubject(ctx context.Context, subject string, until time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockLoginSubject", ctx, subject, until)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BlockLoginSubject indicates an expected call of BlockLoginSubject.
+func (mr *MockRepositoryInterfaceMockRecorder) BlockLoginSubject(ctx, subject, until interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockLoginSubject", reflect.TypeOf((*MockRepositoryInterface)(nil).BlockLoginSubject), ctx, subject, until)
+}
+
+// ClaimDataExport mocks base method.
+func (m *MockRepositoryInterface) ClaimDataExport(ctx context.Context, staleBefore time.Time) (*DataExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDataExport", ctx, staleBefore)
+	ret0, _ := ret[0].(*DataExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDataExport indicates an expected call of ClaimDataExport.
+func (mr *MockRepositoryInterfaceMockRecorder) ClaimDataExport(ctx, staleBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDataExport", reflect.TypeOf((*MockRepositoryInterface)(nil).ClaimDataExport), ctx, staleBefore)
+}
+
+// ClearLoginThrottle mocks base method.
+func (m *MockRepositoryInterface) ClearLoginThrottle(ctx context.Context, subject string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearLoginThrottle", ctx, subject)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearLoginThrottle indicates an expected call of ClearLoginThrottle.
+func (mr *MockRepositoryInterfaceMockRecorder) ClearLoginThrottle(ctx, subject interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearLoginThrottle", reflect.TypeOf((*MockRepositoryInterface)(nil).ClearLoginThrottle), ctx, subject)
+}
+
+// CompleteDataExport mocks base method.
+func (m *MockRepositoryInterface) CompleteDataExport(ctx context.Context, id int, archive []byte, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteDataExport", ctx, id, archive, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteDataExport indicates an expected call of CompleteDataExport.
+func (mr *MockRepositoryInterfaceMockRecorder) CompleteDataExport(ctx, id, archive, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteDataExport", reflect.TypeOf((*MockRepositoryInterface)(nil).CompleteDataExport), ctx, id, archive, expiresAt)
+}
+
+// ConfirmUserPendingPhoneNumber mocks base method.
+func (m *MockRepositoryInterface) ConfirmUserPendingPhoneNumber(ctx context.Context, userID int, phoneNumber string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmUserPendingPhoneNumber", ctx, userID, phoneNumber)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmUserPendingPhoneNumber indicates an expected call of ConfirmUserPendingPhoneNumber.
+func (mr *MockRepositoryInterfaceMockRecorder) ConfirmUserPendingPhoneNumber(ctx, userID, phoneNumber interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmUserPendingPhoneNumber", reflect.TypeOf((*MockRepositoryInterface)(nil).ConfirmUserPendingPhoneNumber), ctx, userID, phoneNumber)
+}
+
+// ConsumeLoginChallenge mocks base method.
+func (m *MockRepositoryInterface) ConsumeLoginChallenge(ctx context.Context, id int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeLoginChallenge", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeLoginChallenge indicates an expected call of ConsumeLoginChallenge.
+func (mr *MockRepositoryInterfaceMockRecorder) ConsumeLoginChallenge(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeLoginChallenge", reflect.TypeOf((*MockRepositoryInterface)(nil).ConsumeLoginChallenge), ctx, id)
+}
+
+// ConsumeOTPCode mocks base method.
+func (m *MockRepositoryInterface) ConsumeOTPCode(ctx context.Context, id int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeOTPCode", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeOTPCode indicates an expected call of ConsumeOTPCode.
+func (mr *MockRepositoryInterfaceMockRecorder) ConsumeOTPCode(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeOTPCode", reflect.TypeOf((*MockRepositoryInterface)(nil).ConsumeOTPCode), ctx, id)
+}
+
+// CountOTPCodesSentSince mocks base method.
+func (m *MockRepositoryInterface) CountOTPCodesSentSince(ctx context.Context, phoneNumber string, since time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountOTPCodesSentSince", ctx, phoneNumber, since)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountOTPCodesSentSince indicates an expected call of CountOTPCodesSentSince.
+func (mr *MockRepositoryInterfaceMockRecorder) CountOTPCodesSentSince(ctx, phoneNumber, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountOTPCodesSentSince", reflect.TypeOf((*MockRepositoryInterface)(nil).CountOTPCodesSentSince), ctx, phoneNumber, since)
+}
+
+// CreateAuditLog mocks base method.
+func (m *MockRepositoryInterface) CreateAuditLog(ctx context.Context, entry *AuditLog) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuditLog", ctx, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAuditLog indicates an expected call of CreateAuditLog.
+func (mr *MockRepositoryInterfaceMockRecorder) CreateAuditLog(ctx, entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuditLog", reflect.TypeOf((*MockRepositoryInterface)(nil).CreateAuditLog), ctx, entry)
+}
+
+// CreateDataExport mocks base method.
+func (m *MockRepositoryInterface) CreateDataExport(ctx context.Context, export *DataExport) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDataExport", ctx, export)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateDataExport indicates an expected call of CreateDataExport.
+func (mr *MockRepositoryInterfaceMockRecorder) CreateDataExport(ctx, export interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDataExport", reflect.TypeOf((*MockRepositoryInterface)(nil).CreateDataExport), ctx, export)
+}
+
+// CreateLoginChallenge mocks base method.
+func (m *MockRepositoryInterface) CreateLoginChallenge(ctx context.Context, challenge *LoginChallenge) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLoginChallenge", ctx, challenge)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateLoginChallenge indicates an expected call of CreateLoginChallenge.
+func (mr *MockRepositoryInterfaceMockRecorder) CreateLoginChallenge(ctx, challenge interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLoginChallenge", reflect.TypeOf((*MockRepositoryInterface)(nil).CreateLoginChallenge), ctx, challenge)
+}
+
+// CreateOAuthAuthorizationCode mocks base method.
+func (m *MockRepositoryInterface) CreateOAuthAuthorizationCode(ctx context.Context, code *OAuthAuthorizationCode) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOAuthAuthorizationCode", ctx, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOAuthAuthorizationCode indicates an expected call of CreateOAuthAuthorizationCode.
+func (mr *MockRepositoryInterfaceMockRecorder) CreateOAuthAuthorizationCode(ctx, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOAuthAuthorizationCode", reflect.TypeOf((*MockRepositoryInterface)(nil).CreateOAuthAuthorizationCode), ctx, code)
+}
+
+// CreateOTPCode mocks base method.
+func (m *MockRepositoryInterface) CreateOTPCode(ctx context.Context, code *OTPCode) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOTPCode", ctx, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOTPCode indicates an expected call of CreateOTPCode.
+func (mr *MockRepositoryInterfaceMockRecorder) CreateOTPCode(ctx, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOTPCode", reflect.TypeOf((*MockRepositoryInterface)(nil).CreateOTPCode), ctx, code)
+}
+
+// CreateRefreshToken mocks base method.
+func (m *MockRepositoryInterface) CreateRefreshToken(ctx context.Context, token *RefreshToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRefreshToken", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRefreshToken indicates an expected call of CreateRefreshToken.
+func (mr *MockRepositoryInterfaceMockRecorder) CreateRefreshToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefreshToken", reflect.TypeOf((*MockRepositoryInterface)(nil).CreateRefreshToken), ctx, token)
+}
+
 // CreateUser mocks base method.
 func (m *MockRepositoryInterface) CreateUser(ctx context.Context, user *User) error {
 	m.ctrl.T.Helper()
@@ -49,6 +266,246 @@ func (mr *MockRepositoryInterfaceMockRecorder) CreateUser(ctx, user interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockRepositoryInterface)(nil).CreateUser), ctx, user)
 }
 
+// DeleteUser mocks base method.
+func (m *MockRepositoryInterface) DeleteUser(ctx context.Context, userID int, deletedBy uuid.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", ctx, userID, deletedBy)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockRepositoryInterfaceMockRecorder) DeleteUser(ctx, userID, deletedBy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockRepositoryInterface)(nil).DeleteUser), ctx, userID, deletedBy)
+}
+
+// EnableUserTOTP mocks base method.
+func (m *MockRepositoryInterface) EnableUserTOTP(ctx context.Context, userID int, usedStep int64, recoveryCodeHashes []string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableUserTOTP", ctx, userID, usedStep, recoveryCodeHashes)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnableUserTOTP indicates an expected call of EnableUserTOTP.
+func (mr *MockRepositoryInterfaceMockRecorder) EnableUserTOTP(ctx, userID, usedStep, recoveryCodeHashes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableUserTOTP", reflect.TypeOf((*MockRepositoryInterface)(nil).EnableUserTOTP), ctx, userID, usedStep, recoveryCodeHashes)
+}
+
+// ExpireDataExports mocks base method.
+func (m *MockRepositoryInterface) ExpireDataExports(ctx context.Context, now time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireDataExports", ctx, now)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpireDataExports indicates an expected call of ExpireDataExports.
+func (mr *MockRepositoryInterfaceMockRecorder) ExpireDataExports(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireDataExports", reflect.TypeOf((*MockRepositoryInterface)(nil).ExpireDataExports), ctx, now)
+}
+
+// FailDataExport mocks base method.
+func (m *MockRepositoryInterface) FailDataExport(ctx context.Context, id int, message string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailDataExport", ctx, id, message)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FailDataExport indicates an expected call of FailDataExport.
+func (mr *MockRepositoryInterfaceMockRecorder) FailDataExport(ctx, id, message interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailDataExport", reflect.TypeOf((*MockRepositoryInterface)(nil).FailDataExport), ctx, id, message)
+}
+
+// GetDataExportArchive mocks base method.
+func (m *MockRepositoryInterface) GetDataExportArchive(ctx context.Context, guid uuid.UUID) (*DataExport, []byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDataExportArchive", ctx, guid)
+	ret0, _ := ret[0].(*DataExport)
+	ret1, _ := ret[1].([]byte)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetDataExportArchive indicates an expected call of GetDataExportArchive.
+func (mr *MockRepositoryInterfaceMockRecorder) GetDataExportArchive(ctx, guid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDataExportArchive", reflect.TypeOf((*MockRepositoryInterface)(nil).GetDataExportArchive), ctx, guid)
+}
+
+// GetDeletedUserLoginByPhoneNumber mocks base method.
+func (m *MockRepositoryInterface) GetDeletedUserLoginByPhoneNumber(ctx context.Context, phoneNumber string, deletedAfter time.Time) (LoginUserOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeletedUserLoginByPhoneNumber", ctx, phoneNumber, deletedAfter)
+	ret0, _ := ret[0].(LoginUserOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeletedUserLoginByPhoneNumber indicates an expected call of GetDeletedUserLoginByPhoneNumber.
+func (mr *MockRepositoryInterfaceMockRecorder) GetDeletedUserLoginByPhoneNumber(ctx, phoneNumber, deletedAfter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeletedUserLoginByPhoneNumber", reflect.TypeOf((*MockRepositoryInterface)(nil).GetDeletedUserLoginByPhoneNumber), ctx, phoneNumber, deletedAfter)
+}
+
+// GetLatestOTPCode mocks base method.
+func (m *MockRepositoryInterface) GetLatestOTPCode(ctx context.Context, userID int, purpose string) (*OTPCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestOTPCode", ctx, userID, purpose)
+	ret0, _ := ret[0].(*OTPCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestOTPCode indicates an expected call of GetLatestOTPCode.
+func (mr *MockRepositoryInterfaceMockRecorder) GetLatestOTPCode(ctx, userID, purpose interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestOTPCode", reflect.TypeOf((*MockRepositoryInterface)(nil).GetLatestOTPCode), ctx, userID, purpose)
+}
+
+// GetLoginChallengeByTokenHash mocks base method.
+func (m *MockRepositoryInterface) GetLoginChallengeByTokenHash(ctx context.Context, tokenHash string) (*LoginChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginChallengeByTokenHash", ctx, tokenHash)
+	ret0, _ := ret[0].(*LoginChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginChallengeByTokenHash indicates an expected call of GetLoginChallengeByTokenHash.
+func (mr *MockRepositoryInterfaceMockRecorder) GetLoginChallengeByTokenHash(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginChallengeByTokenHash", reflect.TypeOf((*MockRepositoryInterface)(nil).GetLoginChallengeByTokenHash), ctx, tokenHash)
+}
+
+// GetLoginThrottle mocks base method.
+func (m *MockRepositoryInterface) GetLoginThrottle(ctx context.Context, subject string) (*LoginThrottle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginThrottle", ctx, subject)
+	ret0, _ := ret[0].(*LoginThrottle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginThrottle indicates an expected call of GetLoginThrottle.
+func (mr *MockRepositoryInterfaceMockRecorder) GetLoginThrottle(ctx, subject interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginThrottle", reflect.TypeOf((*MockRepositoryInterface)(nil).GetLoginThrottle), ctx, subject)
+}
+
+// GetOAuthAuthorizationCodeByHash mocks base method.
+func (m *MockRepositoryInterface) GetOAuthAuthorizationCodeByHash(ctx context.Context, codeHash string) (*OAuthAuthorizationCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOAuthAuthorizationCodeByHash", ctx, codeHash)
+	ret0, _ := ret[0].(*OAuthAuthorizationCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOAuthAuthorizationCodeByHash indicates an expected call of GetOAuthAuthorizationCodeByHash.
+func (mr *MockRepositoryInterfaceMockRecorder) GetOAuthAuthorizationCodeByHash(ctx, codeHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOAuthAuthorizationCodeByHash", reflect.TypeOf((*MockRepositoryInterface)(nil).GetOAuthAuthorizationCodeByHash), ctx, codeHash)
+}
+
+// GetOAuthClientByClientID mocks base method.
+func (m *MockRepositoryInterface) GetOAuthClientByClientID(ctx context.Context, clientID string) (*OAuthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOAuthClientByClientID", ctx, clientID)
+	ret0, _ := ret[0].(*OAuthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOAuthClientByClientID indicates an expected call of GetOAuthClientByClientID.
+func (mr *MockRepositoryInterfaceMockRecorder) GetOAuthClientByClientID(ctx, clientID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOAuthClientByClientID", reflect.TypeOf((*MockRepositoryInterface)(nil).GetOAuthClientByClientID), ctx, clientID)
+}
+
+// GetOAuthConsent mocks base method.
+func (m *MockRepositoryInterface) GetOAuthConsent(ctx context.Context, userID, oauthClientID int) (*OAuthConsent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOAuthConsent", ctx, userID, oauthClientID)
+	ret0, _ := ret[0].(*OAuthConsent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOAuthConsent indicates an expected call of GetOAuthConsent.
+func (mr *MockRepositoryInterfaceMockRecorder) GetOAuthConsent(ctx, userID, oauthClientID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOAuthConsent", reflect.TypeOf((*MockRepositoryInterface)(nil).GetOAuthConsent), ctx, userID, oauthClientID)
+}
+
+// GetOTPCodeByVerificationTokenHash mocks base method.
+func (m *MockRepositoryInterface) GetOTPCodeByVerificationTokenHash(ctx context.Context, tokenHash, purpose string) (*OTPCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOTPCodeByVerificationTokenHash", ctx, tokenHash, purpose)
+	ret0, _ := ret[0].(*OTPCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOTPCodeByVerificationTokenHash indicates an expected call of GetOTPCodeByVerificationTokenHash.
+func (mr *MockRepositoryInterfaceMockRecorder) GetOTPCodeByVerificationTokenHash(ctx, tokenHash, purpose interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOTPCodeByVerificationTokenHash", reflect.TypeOf((*MockRepositoryInterface)(nil).GetOTPCodeByVerificationTokenHash), ctx, tokenHash, purpose)
+}
+
+// GetPasswordHistory mocks base method.
+func (m *MockRepositoryInterface) GetPasswordHistory(ctx context.Context, userID, limit int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPasswordHistory", ctx, userID, limit)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPasswordHistory indicates an expected call of GetPasswordHistory.
+func (mr *MockRepositoryInterfaceMockRecorder) GetPasswordHistory(ctx, userID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPasswordHistory", reflect.TypeOf((*MockRepositoryInterface)(nil).GetPasswordHistory), ctx, userID, limit)
+}
+
+// GetRefreshTokenByHash mocks base method.
+func (m *MockRepositoryInterface) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefreshTokenByHash", ctx, tokenHash)
+	ret0, _ := ret[0].(*RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefreshTokenByHash indicates an expected call of GetRefreshTokenByHash.
+func (mr *MockRepositoryInterfaceMockRecorder) GetRefreshTokenByHash(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefreshTokenByHash", reflect.TypeOf((*MockRepositoryInterface)(nil).GetRefreshTokenByHash), ctx, tokenHash)
+}
+
+// GetUserAccess mocks base method.
+func (m *MockRepositoryInterface) GetUserAccess(ctx context.Context, userID int) (UserAccess, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserAccess", ctx, userID)
+	ret0, _ := ret[0].(UserAccess)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserAccess indicates an expected call of GetUserAccess.
+func (mr *MockRepositoryInterfaceMockRecorder) GetUserAccess(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserAccess", reflect.TypeOf((*MockRepositoryInterface)(nil).GetUserAccess), ctx, userID)
+}
+
 // GetUserByGUID mocks base method.
 func (m *MockRepositoryInterface) GetUserByGUID(ctx context.Context, guid uuid.UUID) (*User, error) {
 	m.ctrl.T.Helper()
@@ -64,6 +521,21 @@ func (mr *MockRepositoryInterfaceMockRecorder) GetUserByGUID(ctx, guid interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByGUID", reflect.TypeOf((*MockRepositoryInterface)(nil).GetUserByGUID), ctx, guid)
 }
 
+// GetUserDataExport mocks base method.
+func (m *MockRepositoryInterface) GetUserDataExport(ctx context.Context, userGUID, guid uuid.UUID) (*DataExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserDataExport", ctx, userGUID, guid)
+	ret0, _ := ret[0].(*DataExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserDataExport indicates an expected call of GetUserDataExport.
+func (mr *MockRepositoryInterfaceMockRecorder) GetUserDataExport(ctx, userGUID, guid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserDataExport", reflect.TypeOf((*MockRepositoryInterface)(nil).GetUserDataExport), ctx, userGUID, guid)
+}
+
 // GetUserLoginByPhoneNumber mocks base method.
 func (m *MockRepositoryInterface) GetUserLoginByPhoneNumber(ctx context.Context, phoneNumber string) (LoginUserOutput, error) {
 	m.ctrl.T.Helper()
@@ -79,6 +551,272 @@ func (mr *MockRepositoryInterfaceMockRecorder) GetUserLoginByPhoneNumber(ctx, ph
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserLoginByPhoneNumber", reflect.TypeOf((*MockRepositoryInterface)(nil).GetUserLoginByPhoneNumber), ctx, phoneNumber)
 }
 
+// GetUserSessions mocks base method.
+func (m *MockRepositoryInterface) GetUserSessions(ctx context.Context, userID int) ([]UserSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserSessions", ctx, userID)
+	ret0, _ := ret[0].([]UserSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserSessions indicates an expected call of GetUserSessions.
+func (mr *MockRepositoryInterfaceMockRecorder) GetUserSessions(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserSessions", reflect.TypeOf((*MockRepositoryInterface)(nil).GetUserSessions), ctx, userID)
+}
+
+// GetUserTOTP mocks base method.
+func (m *MockRepositoryInterface) GetUserTOTP(ctx context.Context, userID int) (*UserTOTP, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserTOTP", ctx, userID)
+	ret0, _ := ret[0].(*UserTOTP)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserTOTP indicates an expected call of GetUserTOTP.
+func (mr *MockRepositoryInterfaceMockRecorder) GetUserTOTP(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTOTP", reflect.TypeOf((*MockRepositoryInterface)(nil).GetUserTOTP), ctx, userID)
+}
+
+// ListUserAuditLogs mocks base method.
+func (m *MockRepositoryInterface) ListUserAuditLogs(ctx context.Context, userID int) ([]*AuditLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserAuditLogs", ctx, userID)
+	ret0, _ := ret[0].([]*AuditLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserAuditLogs indicates an expected call of ListUserAuditLogs.
+func (mr *MockRepositoryInterfaceMockRecorder) ListUserAuditLogs(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserAuditLogs", reflect.TypeOf((*MockRepositoryInterface)(nil).ListUserAuditLogs), ctx, userID)
+}
+
+// ListUserOAuthConsents mocks base method.
+func (m *MockRepositoryInterface) ListUserOAuthConsents(ctx context.Context, userID int) ([]*UserOAuthConsent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserOAuthConsents", ctx, userID)
+	ret0, _ := ret[0].([]*UserOAuthConsent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserOAuthConsents indicates an expected call of ListUserOAuthConsents.
+func (mr *MockRepositoryInterfaceMockRecorder) ListUserOAuthConsents(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserOAuthConsents", reflect.TypeOf((*MockRepositoryInterface)(nil).ListUserOAuthConsents), ctx, userID)
+}
+
+// ListUsers mocks base method.
+func (m *MockRepositoryInterface) ListUsers(ctx context.Context, input ListUsersInput) ([]*User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsers", ctx, input)
+	ret0, _ := ret[0].([]*User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsers indicates an expected call of ListUsers.
+func (mr *MockRepositoryInterfaceMockRecorder) ListUsers(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockRepositoryInterface)(nil).ListUsers), ctx, input)
+}
+
+// MarkOAuthAuthorizationCodeUsed mocks base method.
+func (m *MockRepositoryInterface) MarkOAuthAuthorizationCodeUsed(ctx context.Context, id int, refreshTokenFamilyID uuid.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOAuthAuthorizationCodeUsed", ctx, id, refreshTokenFamilyID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkOAuthAuthorizationCodeUsed indicates an expected call of MarkOAuthAuthorizationCodeUsed.
+func (mr *MockRepositoryInterfaceMockRecorder) MarkOAuthAuthorizationCodeUsed(ctx, id, refreshTokenFamilyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOAuthAuthorizationCodeUsed", reflect.TypeOf((*MockRepositoryInterface)(nil).MarkOAuthAuthorizationCodeUsed), ctx, id, refreshTokenFamilyID)
+}
+
+// MarkOTPCodeVerified mocks base method.
+func (m *MockRepositoryInterface) MarkOTPCodeVerified(ctx context.Context, id int, verificationTokenHash string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOTPCodeVerified", ctx, id, verificationTokenHash)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkOTPCodeVerified indicates an expected call of MarkOTPCodeVerified.
+func (mr *MockRepositoryInterfaceMockRecorder) MarkOTPCodeVerified(ctx, id, verificationTokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOTPCodeVerified", reflect.TypeOf((*MockRepositoryInterface)(nil).MarkOTPCodeVerified), ctx, id, verificationTokenHash)
+}
+
+// MarkRefreshTokenUsed mocks base method.
+func (m *MockRepositoryInterface) MarkRefreshTokenUsed(ctx context.Context, id int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRefreshTokenUsed", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkRefreshTokenUsed indicates an expected call of MarkRefreshTokenUsed.
+func (mr *MockRepositoryInterfaceMockRecorder) MarkRefreshTokenUsed(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRefreshTokenUsed", reflect.TypeOf((*MockRepositoryInterface)(nil).MarkRefreshTokenUsed), ctx, id)
+}
+
+// MarkUserPhoneVerified mocks base method.
+func (m *MockRepositoryInterface) MarkUserPhoneVerified(ctx context.Context, userID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkUserPhoneVerified", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkUserPhoneVerified indicates an expected call of MarkUserPhoneVerified.
+func (mr *MockRepositoryInterfaceMockRecorder) MarkUserPhoneVerified(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkUserPhoneVerified", reflect.TypeOf((*MockRepositoryInterface)(nil).MarkUserPhoneVerified), ctx, userID)
+}
+
+// MarkUserTOTPStepUsed mocks base method.
+func (m *MockRepositoryInterface) MarkUserTOTPStepUsed(ctx context.Context, userID int, step int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkUserTOTPStepUsed", ctx, userID, step)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkUserTOTPStepUsed indicates an expected call of MarkUserTOTPStepUsed.
+func (mr *MockRepositoryInterfaceMockRecorder) MarkUserTOTPStepUsed(ctx, userID, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkUserTOTPStepUsed", reflect.TypeOf((*MockRepositoryInterface)(nil).MarkUserTOTPStepUsed), ctx, userID, step)
+}
+
+// PurgeDeletedUsers mocks base method.
+func (m *MockRepositoryInterface) PurgeDeletedUsers(ctx context.Context, deletedBefore time.Time, limit int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedUsers", ctx, deletedBefore, limit)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeletedUsers indicates an expected call of PurgeDeletedUsers.
+func (mr *MockRepositoryInterfaceMockRecorder) PurgeDeletedUsers(ctx, deletedBefore, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedUsers", reflect.TypeOf((*MockRepositoryInterface)(nil).PurgeDeletedUsers), ctx, deletedBefore, limit)
+}
+
+// RecordLoginFailure mocks base method.
+func (m *MockRepositoryInterface) RecordLoginFailure(ctx context.Context, subject string, resetAfter time.Duration) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordLoginFailure", ctx, subject, resetAfter)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordLoginFailure indicates an expected call of RecordLoginFailure.
+func (mr *MockRepositoryInterfaceMockRecorder) RecordLoginFailure(ctx, subject, resetAfter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordLoginFailure", reflect.TypeOf((*MockRepositoryInterface)(nil).RecordLoginFailure), ctx, subject, resetAfter)
+}
+
+// RehashUserPassword mocks base method.
+func (m *MockRepositoryInterface) RehashUserPassword(ctx context.Context, userID int, currentHash, newHash string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RehashUserPassword", ctx, userID, currentHash, newHash)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RehashUserPassword indicates an expected call of RehashUserPassword.
+func (mr *MockRepositoryInterfaceMockRecorder) RehashUserPassword(ctx, userID, currentHash, newHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RehashUserPassword", reflect.TypeOf((*MockRepositoryInterface)(nil).RehashUserPassword), ctx, userID, currentHash, newHash)
+}
+
+// RemoveUserRole mocks base method.
+func (m *MockRepositoryInterface) RemoveUserRole(ctx context.Context, userID int, roleName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveUserRole", ctx, userID, roleName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveUserRole indicates an expected call of RemoveUserRole.
+func (mr *MockRepositoryInterfaceMockRecorder) RemoveUserRole(ctx, userID, roleName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveUserRole", reflect.TypeOf((*MockRepositoryInterface)(nil).RemoveUserRole), ctx, userID, roleName)
+}
+
+// RestoreUser mocks base method.
+func (m *MockRepositoryInterface) RestoreUser(ctx context.Context, userID int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreUser", ctx, userID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreUser indicates an expected call of RestoreUser.
+func (mr *MockRepositoryInterfaceMockRecorder) RestoreUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreUser", reflect.TypeOf((*MockRepositoryInterface)(nil).RestoreUser), ctx, userID)
+}
+
+// RevokeRefreshTokenFamily mocks base method.
+func (m *MockRepositoryInterface) RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRefreshTokenFamily", ctx, familyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRefreshTokenFamily indicates an expected call of RevokeRefreshTokenFamily.
+func (mr *MockRepositoryInterfaceMockRecorder) RevokeRefreshTokenFamily(ctx, familyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRefreshTokenFamily", reflect.TypeOf((*MockRepositoryInterface)(nil).RevokeRefreshTokenFamily), ctx, familyID)
+}
+
+// RevokeUserRefreshTokens mocks base method.
+func (m *MockRepositoryInterface) RevokeUserRefreshTokens(ctx context.Context, userGUID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserRefreshTokens", ctx, userGUID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeUserRefreshTokens indicates an expected call of RevokeUserRefreshTokens.
+func (mr *MockRepositoryInterfaceMockRecorder) RevokeUserRefreshTokens(ctx, userGUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserRefreshTokens", reflect.TypeOf((*MockRepositoryInterface)(nil).RevokeUserRefreshTokens), ctx, userGUID)
+}
+
+// SetUserDisabled mocks base method.
+func (m *MockRepositoryInterface) SetUserDisabled(ctx context.Context, userID int, disabled bool) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserDisabled", ctx, userID, disabled)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetUserDisabled indicates an expected call of SetUserDisabled.
+func (mr *MockRepositoryInterfaceMockRecorder) SetUserDisabled(ctx, userID, disabled interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserDisabled", reflect.TypeOf((*MockRepositoryInterface)(nil).SetUserDisabled), ctx, userID, disabled)
+}
+
 // UpdateUser mocks base method.
 func (m *MockRepositoryInterface) UpdateUser(ctx context.Context, user *User) error {
 	m.ctrl.T.Helper()
@@ -92,3 +830,156 @@ func (mr *MockRepositoryInterfaceMockRecorder) UpdateUser(ctx, user interface{})
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockRepositoryInterface)(nil).UpdateUser), ctx, user)
 }
+
+// UpdateUserPassword mocks base method.
+func (m *MockRepositoryInterface) UpdateUserPassword(ctx context.Context, userID int, hashedPassword string, historySize int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserPassword", ctx, userID, hashedPassword, historySize)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserPassword indicates an expected call of UpdateUserPassword.
+func (mr *MockRepositoryInterfaceMockRecorder) UpdateUserPassword(ctx, userID, hashedPassword, historySize interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserPassword", reflect.TypeOf((*MockRepositoryInterface)(nil).UpdateUserPassword), ctx, userID, hashedPassword, historySize)
+}
+
+// UpsertOAuthConsent mocks base method.
+func (m *MockRepositoryInterface) UpsertOAuthConsent(ctx context.Context, consent *OAuthConsent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertOAuthConsent", ctx, consent)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertOAuthConsent indicates an expected call of UpsertOAuthConsent.
+func (mr *MockRepositoryInterfaceMockRecorder) UpsertOAuthConsent(ctx, consent interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertOAuthConsent", reflect.TypeOf((*MockRepositoryInterface)(nil).UpsertOAuthConsent), ctx, consent)
+}
+
+// UpsertPendingUserTOTP mocks base method.
+func (m *MockRepositoryInterface) UpsertPendingUserTOTP(ctx context.Context, userID int, secretCiphertext string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertPendingUserTOTP", ctx, userID, secretCiphertext)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertPendingUserTOTP indicates an expected call of UpsertPendingUserTOTP.
+func (mr *MockRepositoryInterfaceMockRecorder) UpsertPendingUserTOTP(ctx, userID, secretCiphertext interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertPendingUserTOTP", reflect.TypeOf((*MockRepositoryInterface)(nil).UpsertPendingUserTOTP), ctx, userID, secretCiphertext)
+}
+
+// UseLoginChallengeAttempt mocks base method.
+func (m *MockRepositoryInterface) UseLoginChallengeAttempt(ctx context.Context, id, maxAttempts int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseLoginChallengeAttempt", ctx, id, maxAttempts)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseLoginChallengeAttempt indicates an expected call of UseLoginChallengeAttempt.
+func (mr *MockRepositoryInterfaceMockRecorder) UseLoginChallengeAttempt(ctx, id, maxAttempts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseLoginChallengeAttempt", reflect.TypeOf((*MockRepositoryInterface)(nil).UseLoginChallengeAttempt), ctx, id, maxAttempts)
+}
+
+// UseOTPCodeAttempt mocks base method.
+func (m *MockRepositoryInterface) UseOTPCodeAttempt(ctx context.Context, id, maxAttempts int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseOTPCodeAttempt", ctx, id, maxAttempts)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseOTPCodeAttempt indicates an expected call of UseOTPCodeAttempt.
+func (mr *MockRepositoryInterfaceMockRecorder) UseOTPCodeAttempt(ctx, id, maxAttempts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseOTPCodeAttempt", reflect.TypeOf((*MockRepositoryInterface)(nil).UseOTPCodeAttempt), ctx, id, maxAttempts)
+}
+
+// UseTOTPRecoveryCode mocks base method.
+func (m *MockRepositoryInterface) UseTOTPRecoveryCode(ctx context.Context, userID int, codeHash string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTOTPRecoveryCode", ctx, userID, codeHash)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseTOTPRecoveryCode indicates an expected call of UseTOTPRecoveryCode.
+func (mr *MockRepositoryInterfaceMockRecorder) UseTOTPRecoveryCode(ctx, userID, codeHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTOTPRecoveryCode", reflect.TypeOf((*MockRepositoryInterface)(nil).UseTOTPRecoveryCode), ctx, userID, codeHash)
+}
+
+// MockTokenRevocationRepositoryInterface is a mock of TokenRevocationRepositoryInterface interface.
+type MockTokenRevocationRepositoryInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenRevocationRepositoryInterfaceMockRecorder
+}
+
+// MockTokenRevocationRepositoryInterfaceMockRecorder is the mock recorder for MockTokenRevocationRepositoryInterface.
+type MockTokenRevocationRepositoryInterfaceMockRecorder struct {
+	mock *MockTokenRevocationRepositoryInterface
+}
+
+// NewMockTokenRevocationRepositoryInterface creates a new mock instance.
+func NewMockTokenRevocationRepositoryInterface(ctrl *gomock.Controller) *MockTokenRevocationRepositoryInterface {
+	mock := &MockTokenRevocationRepositoryInterface{ctrl: ctrl}
+	mock.recorder = &MockTokenRevocationRepositoryInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokenRevocationRepositoryInterface) EXPECT() *MockTokenRevocationRepositoryInterfaceMockRecorder {
+	return m.recorder
+}
+
+// IsAccessTokenRevoked mocks base method.
+func (m *MockTokenRevocationRepositoryInterface) IsAccessTokenRevoked(ctx context.Context, input IsAccessTokenRevokedInput) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsAccessTokenRevoked", ctx, input)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsAccessTokenRevoked indicates an expected call of IsAccessTokenRevoked.
+func (mr *MockTokenRevocationRepositoryInterfaceMockRecorder) IsAccessTokenRevoked(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsAccessTokenRevoked", reflect.TypeOf((*MockTokenRevocationRepositoryInterface)(nil).IsAccessTokenRevoked), ctx, input)
+}
+
+// RevokeAccessToken mocks base method.
+func (m *MockTokenRevocationRepositoryInterface) RevokeAccessToken(ctx context.Context, token RevokedAccessToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAccessToken", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAccessToken indicates an expected call of RevokeAccessToken.
+func (mr *MockTokenRevocationRepositoryInterfaceMockRecorder) RevokeAccessToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAccessToken", reflect.TypeOf((*MockTokenRevocationRepositoryInterface)(nil).RevokeAccessToken), ctx, token)
+}
+
+// RevokeUserAccessTokens mocks base method.
+func (m *MockTokenRevocationRepositoryInterface) RevokeUserAccessTokens(ctx context.Context, userGUID uuid.UUID, revokedBefore time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserAccessTokens", ctx, userGUID, revokedBefore)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeUserAccessTokens indicates an expected call of RevokeUserAccessTokens.
+func (mr *MockTokenRevocationRepositoryInterfaceMockRecorder) RevokeUserAccessTokens(ctx, userGUID, revokedBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserAccessTokens", reflect.TypeOf((*MockTokenRevocationRepositoryInterface)(nil).RevokeUserAccessTokens), ctx, userGUID, revokedBefore)
+}
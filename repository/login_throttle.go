@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+func (r *Repository) GetLoginThrottle(ctx context.Context, subject string) (throttle *LoginThrottle, err error) {
+	throttle = new(LoginThrottle)
+	err = r.Db.QueryRowContext(
+		ctx,
+		"SELECT subject, failed_attempts, last_failed_at, blocked_until, created_at FROM login_throttles WHERE subject = $1",
+		subject,
+	).Scan(
+		&throttle.Subject,
+		&throttle.FailedAttempts,
+		&throttle.LastFailedAt,
+		&throttle.BlockedUntil,
+		&throttle.CreatedAt,
+	)
+	if err != nil {
+		return
+	}
+	return
+}
+
+// RecordLoginFailure counts a failed login for subject and returns the number
+// of failures so far. Failures older than resetAfter are forgotten, so the
+// count starts again at one.
+func (r *Repository) RecordLoginFailure(ctx context.Context, subject string, resetAfter time.Duration) (failedAttempts int, err error) {
+	err = r.Db.QueryRowContext(
+		ctx,
+		`INSERT INTO login_throttles (subject, failed_attempts, last_failed_at) VALUES ($1, 1, NOW())
+		ON CONFLICT (subject) DO UPDATE SET
+			failed_attempts = CASE
+				WHEN login_throttles.last_failed_at < NOW() - make_interval(secs => $2) THEN 1
+				ELSE login_throttles.failed_attempts + 1
+			END,
+			last_failed_at = NOW()
+		RETURNING failed_attempts`,
+		subject,
+		resetAfter.Seconds(),
+	).Scan(&failedAttempts)
+	return failedAttempts, ConvertPGError(err)
+}
+
+// BlockLoginSubject refuses password logins for subject until the given time.
+func (r *Repository) BlockLoginSubject(ctx context.Context, subject string, until time.Time) error {
+	_, err := r.Db.ExecContext(
+		ctx,
+		"UPDATE login_throttles SET blocked_until = $2 WHERE subject = $1",
+		subject,
+		until,
+	)
+	return ConvertPGError(err)
+}
+
+// ClearLoginThrottle forgets the failures and any block for subject.
+func (r *Repository) ClearLoginThrottle(ctx context.Context, subject string) error {
+	_, err := r.Db.ExecContext(ctx, "DELETE FROM login_throttles WHERE subject = $1", subject)
+	return ConvertPGError(err)
+}
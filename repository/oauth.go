@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+func (r *Repository) GetOAuthClientByClientID(ctx context.Context, clientID string) (client *OAuthClient, err error) {
+	client = new(OAuthClient)
+	err = r.Db.QueryRowContext(
+		ctx,
+		`SELECT id, client_id, name, client_secret_hash, redirect_uris, scopes, grant_types, created_at, last_modified_at
+		FROM oauth_clients
+		WHERE client_id = $1 AND deleted_at IS NULL`,
+		clientID,
+	).Scan(
+		&client.ID,
+		&client.ClientID,
+		&client.Name,
+		&client.ClientSecretHash,
+		pq.Array(&client.RedirectURIs),
+		pq.Array(&client.Scopes),
+		pq.Array(&client.GrantTypes),
+		&client.CreatedAt,
+		&client.LastModifiedAt,
+	)
+	if err != nil {
+		return
+	}
+	return
+}
+
+func (r *Repository) CreateOAuthAuthorizationCode(ctx context.Context, code *OAuthAuthorizationCode) (err error) {
+	err = r.Db.QueryRowContext(
+		ctx,
+		`INSERT INTO oauth_authorization_codes (code_hash, oauth_client_id, user_id, redirect_uri, redirect_uri_sent, scope, code_challenge, code_challenge_method, nonce, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id, created_at`,
+		code.CodeHash,
+		code.OAuthClientID,
+		code.UserID,
+		code.RedirectURI,
+		code.RedirectURISent,
+		code.Scope,
+		code.CodeChallenge,
+		code.CodeChallengeMethod,
+		code.Nonce,
+		code.ExpiresAt,
+	).Scan(&code.ID, &code.CreatedAt)
+	return ConvertPGError(err)
+}
+
+func (r *Repository) GetOAuthAuthorizationCodeByHash(ctx context.Context, codeHash string) (code *OAuthAuthorizationCode, err error) {
+	code = new(OAuthAuthorizationCode)
+	err = r.Db.QueryRowContext(
+		ctx,
+		`SELECT ac.id, ac.code_hash, ac.oauth_client_id, ac.user_id, ac.redirect_uri, ac.redirect_uri_sent, ac.scope, ac.code_challenge,
+			ac.code_challenge_method, ac.nonce, ac.refresh_token_family_id, ac.expires_at, ac.used_at, ac.created_at,
+			u.guid, u.full_name, u.phone_number
+		FROM oauth_authorization_codes ac
+		JOIN users u ON u.id = ac.user_id AND u.deleted_at IS NULL
+		WHERE ac.code_hash = $1`,
+		codeHash,
+	).Scan(
+		&code.ID,
+		&code.CodeHash,
+		&code.OAuthClientID,
+		&code.UserID,
+		&code.RedirectURI,
+		&code.RedirectURISent,
+		&code.Scope,
+		&code.CodeChallenge,
+		&code.CodeChallengeMethod,
+		&code.Nonce,
+		&code.RefreshTokenFamilyID,
+		&code.ExpiresAt,
+		&code.UsedAt,
+		&code.CreatedAt,
+		&code.UserGUID,
+		&code.FullName,
+		&code.PhoneNumber,
+	)
+	if err != nil {
+		return
+	}
+	return
+}
+
+// MarkOAuthAuthorizationCodeUsed consumes the code and remembers the refresh
+// token family it was exchanged for, so a replayed code can revoke the tokens
+// it produced. It reports false when the code had already been used.
+func (r *Repository) MarkOAuthAuthorizationCodeUsed(ctx context.Context, id int, refreshTokenFamilyID uuid.UUID) (marked bool, err error) {
+	result, err := r.Db.ExecContext(
+		ctx,
+		"UPDATE oauth_authorization_codes SET used_at = NOW(), refresh_token_family_id = $2 WHERE id = $1 AND used_at IS NULL",
+		id,
+		refreshTokenFamilyID,
+	)
+	if err != nil {
+		return false, ConvertPGError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, ConvertPGError(err)
+	}
+	return affected == 1, nil
+}
+
+func (r *Repository) GetOAuthConsent(ctx context.Context, userID int, oauthClientID int) (consent *OAuthConsent, err error) {
+	consent = new(OAuthConsent)
+	err = r.Db.QueryRowContext(
+		ctx,
+		`SELECT id, user_id, oauth_client_id, scopes, revoked_at, created_at, last_modified_at
+		FROM oauth_consents
+		WHERE user_id = $1 AND oauth_client_id = $2 AND revoked_at IS NULL`,
+		userID,
+		oauthClientID,
+	).Scan(
+		&consent.ID,
+		&consent.UserID,
+		&consent.OAuthClientID,
+		pq.Array(&consent.Scopes),
+		&consent.RevokedAt,
+		&consent.CreatedAt,
+		&consent.LastModifiedAt,
+	)
+	if err != nil {
+		return
+	}
+	return
+}
+
+// UpsertOAuthConsent records the scopes the user granted to the client,
+// replacing any earlier or revoked consent for the same client.
+func (r *Repository) UpsertOAuthConsent(ctx context.Context, consent *OAuthConsent) (err error) {
+	err = r.Db.QueryRowContext(
+		ctx,
+		`INSERT INTO oauth_consents (user_id, oauth_client_id, scopes) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, oauth_client_id) DO UPDATE SET scopes = EXCLUDED.scopes, revoked_at = NULL
+		RETURNING id, created_at`,
+		consent.UserID,
+		consent.OAuthClientID,
+		pq.Array(consent.Scopes),
+	).Scan(&consent.ID, &consent.CreatedAt)
+	return ConvertPGError(err)
+}
+
+// ListUserOAuthConsents lists the consents the user gave, including revoked
+// ones, newest first.
+func (r *Repository) ListUserOAuthConsents(ctx context.Context, userID int) (consents []*UserOAuthConsent, err error) {
+	rows, err := r.Db.QueryContext(
+		ctx,
+		`SELECT cs.id, cs.user_id, cs.oauth_client_id, cs.scopes, cs.revoked_at, cs.created_at, cs.last_modified_at, c.name
+		FROM oauth_consents cs
+		JOIN oauth_clients c ON c.id = cs.oauth_client_id
+		WHERE cs.user_id = $1
+		ORDER BY cs.created_at DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, ConvertPGError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		consent := new(UserOAuthConsent)
+		err = rows.Scan(&consent.ID, &consent.UserID, &consent.OAuthClientID, pq.Array(&consent.Scopes),
+			&consent.RevokedAt, &consent.CreatedAt, &consent.LastModifiedAt, &consent.ClientName)
+		if err != nil {
+			return nil, err
+		}
+		consents = append(consents, consent)
+	}
+	return consents, rows.Err()
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// CreateOTPCode stores a new code and retires the codes still pending for the
+// same user and purpose, so only the latest code sent can be used.
+func (r *Repository) CreateOTPCode(ctx context.Context, code *OTPCode) (err error) {
+	err = r.Db.QueryRowContext(
+		ctx,
+		`WITH retired AS (
+			UPDATE otp_codes SET consumed_at = NOW()
+			WHERE user_id = $1 AND purpose = $2 AND consumed_at IS NULL
+		)
+		INSERT INTO otp_codes (user_id, purpose, phone_number, code_hash, expires_at) VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`,
+		code.UserID,
+		code.Purpose,
+		code.PhoneNumber,
+		code.CodeHash,
+		code.ExpiresAt,
+	).Scan(&code.ID, &code.CreatedAt)
+	return ConvertPGError(err)
+}
+
+// GetLatestOTPCode returns the most recent code still pending verification.
+func (r *Repository) GetLatestOTPCode(ctx context.Context, userID int, purpose string) (code *OTPCode, err error) {
+	code = new(OTPCode)
+	err = r.Db.QueryRowContext(
+		ctx,
+		`SELECT oc.id, oc.user_id, oc.purpose, oc.phone_number, oc.code_hash, oc.attempts, oc.verification_token_hash, oc.expires_at,
+			oc.verified_at, oc.consumed_at, oc.created_at, u.guid
+		FROM otp_codes oc
+		JOIN users u ON u.id = oc.user_id AND u.deleted_at IS NULL
+		WHERE oc.user_id = $1 AND oc.purpose = $2 AND oc.verified_at IS NULL AND oc.consumed_at IS NULL
+		ORDER BY oc.created_at DESC
+		LIMIT 1`,
+		userID,
+		purpose,
+	).Scan(
+		&code.ID,
+		&code.UserID,
+		&code.Purpose,
+		&code.PhoneNumber,
+		&code.CodeHash,
+		&code.Attempts,
+		&code.VerificationTokenHash,
+		&code.ExpiresAt,
+		&code.VerifiedAt,
+		&code.ConsumedAt,
+		&code.CreatedAt,
+		&code.UserGUID,
+	)
+	if err != nil {
+		return
+	}
+	return
+}
+
+func (r *Repository) GetOTPCodeByVerificationTokenHash(ctx context.Context, tokenHash string, purpose string) (code *OTPCode, err error) {
+	code = new(OTPCode)
+	err = r.Db.QueryRowContext(
+		ctx,
+		`SELECT oc.id, oc.user_id, oc.purpose, oc.phone_number, oc.code_hash, oc.attempts, oc.verification_token_hash, oc.expires_at,
+			oc.verified_at, oc.consumed_at, oc.created_at, u.guid
+		FROM otp_codes oc
+		JOIN users u ON u.id = oc.user_id AND u.deleted_at IS NULL
+		WHERE oc.verification_token_hash = $1 AND oc.purpose = $2`,
+		tokenHash,
+		purpose,
+	).Scan(
+		&code.ID,
+		&code.UserID,
+		&code.Purpose,
+		&code.PhoneNumber,
+		&code.CodeHash,
+		&code.Attempts,
+		&code.VerificationTokenHash,
+		&code.ExpiresAt,
+		&code.VerifiedAt,
+		&code.ConsumedAt,
+		&code.CreatedAt,
+		&code.UserGUID,
+	)
+	if err != nil {
+		return
+	}
+	return
+}
+
+// UseOTPCodeAttempt spends one of the maxAttempts guesses at a code before it
+// is compared, in one statement so concurrent guesses cannot overdraw the
+// limit. It reports false when the attempts had run out.
+func (r *Repository) UseOTPCodeAttempt(ctx context.Context, id int, maxAttempts int) (used bool, err error) {
+	result, err := r.Db.ExecContext(
+		ctx,
+		"UPDATE otp_codes SET attempts = attempts + 1 WHERE id = $1 AND attempts < $2",
+		id,
+		maxAttempts,
+	)
+	if err != nil {
+		return false, ConvertPGError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, ConvertPGError(err)
+	}
+	return affected == 1, nil
+}
+
+// MarkOTPCodeVerified attaches the verification token to the code. It reports
+// false when the code was verified or consumed in the meantime.
+func (r *Repository) MarkOTPCodeVerified(ctx context.Context, id int, verificationTokenHash string) (marked bool, err error) {
+	result, err := r.Db.ExecContext(
+		ctx,
+		"UPDATE otp_codes SET verified_at = NOW(), verification_token_hash = $2 WHERE id = $1 AND verified_at IS NULL AND consumed_at IS NULL",
+		id,
+		verificationTokenHash,
+	)
+	if err != nil {
+		return false, ConvertPGError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, ConvertPGError(err)
+	}
+	return affected == 1, nil
+}
+
+// ConsumeOTPCode closes the flow the code was sent for. It reports false when
+// the code had already been consumed.
+func (r *Repository) ConsumeOTPCode(ctx context.Context, id int) (consumed bool, err error) {
+	result, err := r.Db.ExecContext(
+		ctx,
+		"UPDATE otp_codes SET consumed_at = NOW() WHERE id = $1 AND consumed_at IS NULL",
+		id,
+	)
+	if err != nil {
+		return false, ConvertPGError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, ConvertPGError(err)
+	}
+	return affected == 1, nil
+}
+
+// CountOTPCodesSentSince counts the codes sent to phoneNumber for any purpose
+// since the given time.
+func (r *Repository) CountOTPCodesSentSince(ctx context.Context, phoneNumber string, since time.Time) (count int, err error) {
+	err = r.Db.QueryRowContext(
+		ctx,
+		"SELECT COUNT(*) FROM otp_codes WHERE phone_number = $1 AND created_at >= $2",
+		phoneNumber,
+		since,
+	).Scan(&count)
+	return count, ConvertPGError(err)
+}
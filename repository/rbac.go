@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/lib/pq"
+)
+
+// GetUserAccess returns the names of the user's roles and of every permission
+// they grant, both sorted.
+func (r *Repository) GetUserAccess(ctx context.Context, userID int) (access UserAccess, err error) {
+	err = r.Db.QueryRowContext(
+		ctx,
+		`SELECT
+			ARRAY(
+				SELECT r.name FROM user_roles ur JOIN roles r ON r.id = ur.role_id
+				WHERE ur.user_id = $1 ORDER BY r.name
+			),
+			ARRAY(
+				SELECT DISTINCT p.name FROM user_roles ur
+				JOIN role_permissions rp ON rp.role_id = ur.role_id
+				JOIN permissions p ON p.id = rp.permission_id
+				WHERE ur.user_id = $1 ORDER BY p.name
+			)`,
+		userID,
+	).Scan(pq.Array(&access.Roles), pq.Array(&access.Permissions))
+	return access, ConvertPGError(err)
+}
+
+// AssignUserRole grants the role named roleName to the user. Granting a role
+// the user already has is not an error; found is false when no role has that
+// name.
+func (r *Repository) AssignUserRole(ctx context.Context, userID int, roleName string) (found bool, err error) {
+	err = r.Db.QueryRowContext(
+		ctx,
+		`WITH role AS (
+			SELECT id FROM roles WHERE name = $2
+		), assigned AS (
+			INSERT INTO user_roles (user_id, role_id) SELECT $1, id FROM role
+			ON CONFLICT DO NOTHING
+		)
+		SELECT EXISTS (SELECT 1 FROM role)`,
+		userID, roleName,
+	).Scan(&found)
+	if err != nil {
+		return false, ConvertPGError(err)
+	}
+	return found, nil
+}
+
+// RemoveUserRole takes the role named roleName away from the user, if they
+// have it.
+func (r *Repository) RemoveUserRole(ctx context.Context, userID int, roleName string) error {
+	_, err := r.Db.ExecContext(
+		ctx,
+		"DELETE FROM user_roles ur USING roles r WHERE ur.role_id = r.id AND ur.user_id = $1 AND r.name = $2",
+		userID, roleName,
+	)
+	return ConvertPGError(err)
+}
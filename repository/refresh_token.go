@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+func (r *Repository) CreateRefreshToken(ctx context.Context, token *RefreshToken) (err error) {
+	err = r.Db.QueryRowContext(
+		ctx,
+		"INSERT INTO refresh_tokens (user_id, oauth_client_id, scope, family_id, token_hash, expires_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at",
+		token.UserID,
+		token.OAuthClientID,
+		token.Scope,
+		token.FamilyID,
+		token.TokenHash,
+		token.ExpiresAt,
+	).Scan(&token.ID, &token.CreatedAt)
+	return ConvertPGError(err)
+}
+
+func (r *Repository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (token *RefreshToken, err error) {
+	token = new(RefreshToken)
+	err = r.Db.QueryRowContext(
+		ctx,
+		`SELECT rt.id, rt.user_id, rt.oauth_client_id, rt.scope, rt.family_id, rt.token_hash, rt.expires_at, rt.used_at, rt.revoked_at, rt.created_at,
+			u.guid, u.full_name, u.phone_number
+		FROM refresh_tokens rt
+		JOIN users u ON u.id = rt.user_id AND u.deleted_at IS NULL
+		WHERE rt.token_hash = $1`,
+		tokenHash,
+	).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.OAuthClientID,
+		&token.Scope,
+		&token.FamilyID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.UsedAt,
+		&token.RevokedAt,
+		&token.CreatedAt,
+		&token.UserGUID,
+		&token.FullName,
+		&token.PhoneNumber,
+	)
+	if err != nil {
+		return
+	}
+	return
+}
+
+// MarkRefreshTokenUsed flags the token as consumed. It reports false when the
+// token had already been used or revoked, which lets concurrent refreshes of
+// the same token be detected as reuse.
+func (r *Repository) MarkRefreshTokenUsed(ctx context.Context, id int) (marked bool, err error) {
+	result, err := r.Db.ExecContext(
+		ctx,
+		"UPDATE refresh_tokens SET used_at = NOW() WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL",
+		id,
+	)
+	if err != nil {
+		return false, ConvertPGError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, ConvertPGError(err)
+	}
+	return affected == 1, nil
+}
+
+func (r *Repository) RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID) error {
+	_, err := r.Db.ExecContext(
+		ctx,
+		"UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL",
+		familyID,
+	)
+	if err != nil {
+		return ConvertPGError(err)
+	}
+	return nil
+}
+
+func (r *Repository) RevokeUserRefreshTokens(ctx context.Context, userGUID uuid.UUID) error {
+	_, err := r.Db.ExecContext(
+		ctx,
+		"UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = (SELECT id FROM users WHERE guid = $1) AND revoked_at IS NULL",
+		userGUID,
+	)
+	if err != nil {
+		return ConvertPGError(err)
+	}
+	return nil
+}
+
+// GetUserSessions lists every login of the user that still has refresh
+// tokens on record, newest first.
+func (r *Repository) GetUserSessions(ctx context.Context, userID int) (sessions []UserSession, err error) {
+	rows, err := r.Db.QueryContext(
+		ctx,
+		`SELECT t.family_id, c.name, MIN(t.scope), MIN(t.created_at), MAX(t.created_at), MAX(t.expires_at),
+			MAX(t.revoked_at)
+		FROM refresh_tokens t
+		LEFT JOIN oauth_clients c ON c.id = t.oauth_client_id
+		WHERE t.user_id = $1
+		GROUP BY t.family_id, c.name
+		ORDER BY MIN(t.created_at) DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, ConvertPGError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var session UserSession
+		err = rows.Scan(&session.FamilyID, &session.ClientName, &session.Scope, &session.StartedAt,
+			&session.LastRefreshedAt, &session.ExpiresAt, &session.RevokedAt)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
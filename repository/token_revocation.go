@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (r *Repository) RevokeAccessToken(ctx context.Context, token RevokedAccessToken) error {
+	_, err := r.Db.ExecContext(
+		ctx,
+		"INSERT INTO revoked_access_tokens (jti, user_guid, expires_at) VALUES ($1, $2, $3) ON CONFLICT (jti) DO NOTHING",
+		token.JTI,
+		token.UserGUID,
+		token.ExpiresAt,
+	)
+	if err != nil {
+		return ConvertPGError(err)
+	}
+	return nil
+}
+
+// RevokeUserAccessTokens revokes the user's access tokens issued before
+// revokedBefore. Both sides are compared in whole seconds, the precision of
+// the iat claim.
+func (r *Repository) RevokeUserAccessTokens(ctx context.Context, userGUID uuid.UUID, revokedBefore time.Time) error {
+	_, err := r.Db.ExecContext(
+		ctx,
+		`INSERT INTO user_token_revocations (user_guid, revoked_before) VALUES ($1, $2)
+		ON CONFLICT (user_guid) DO UPDATE SET revoked_before = GREATEST(user_token_revocations.revoked_before, EXCLUDED.revoked_before)`,
+		userGUID,
+		revokedBefore.Truncate(time.Second),
+	)
+	if err != nil {
+		return ConvertPGError(err)
+	}
+	return nil
+}
+
+func (r *Repository) IsAccessTokenRevoked(ctx context.Context, input IsAccessTokenRevokedInput) (revoked bool, err error) {
+	err = r.Db.QueryRowContext(
+		ctx,
+		`SELECT ($1 <> '' AND EXISTS (SELECT 1 FROM revoked_access_tokens WHERE jti = $1))
+			OR EXISTS (SELECT 1 FROM user_token_revocations WHERE user_guid = $2 AND revoked_before > $3)`,
+		input.JTI,
+		input.UserGUID,
+		input.IssuedAt.Truncate(time.Second),
+	).Scan(&revoked)
+	if err != nil {
+		return false, ConvertPGError(err)
+	}
+	return
+}
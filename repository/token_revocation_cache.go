@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CachedTokenRevocationRepository keeps revocation lookups in memory so that
+// JWTMiddleware does not hit the database on every request. Answers are
+// trusted for the configured TTL, except tokens revoked through this instance
+// which stay cached as revoked until they expire. Revocations made through
+// this instance are visible immediately; revocations made by another replica
+// are picked up once the TTL lapses.
+type CachedTokenRevocationRepository struct {
+	next TokenRevocationRepositoryInterface
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu            sync.Mutex
+	entries       map[string]revocationCacheEntry
+	lastEvictedAt time.Time
+}
+
+type revocationCacheEntry struct {
+	userGUID  uuid.UUID
+	revoked   bool
+	expiresAt time.Time
+}
+
+func NewCachedTokenRevocationRepository(next TokenRevocationRepositoryInterface, ttl time.Duration) *CachedTokenRevocationRepository {
+	return &CachedTokenRevocationRepository{
+		next:    next,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]revocationCacheEntry),
+	}
+}
+
+func (c *CachedTokenRevocationRepository) RevokeAccessToken(ctx context.Context, token RevokedAccessToken) error {
+	err := c.next.RevokeAccessToken(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[token.JTI] = revocationCacheEntry{
+		userGUID:  token.UserGUID,
+		revoked:   true,
+		expiresAt: token.ExpiresAt,
+	}
+	return nil
+}
+
+func (c *CachedTokenRevocationRepository) RevokeUserAccessTokens(ctx context.Context, userGUID uuid.UUID, revokedBefore time.Time) error {
+	err := c.next.RevokeUserAccessTokens(ctx, userGUID, revokedBefore)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, entry := range c.entries {
+		if entry.userGUID == userGUID && !entry.revoked {
+			delete(c.entries, key)
+		}
+	}
+	return nil
+}
+
+func (c *CachedTokenRevocationRepository) IsAccessTokenRevoked(ctx context.Context, input IsAccessTokenRevokedInput) (revoked bool, err error) {
+	key := input.JTI
+	if key == "" {
+		// Tokens issued before jti was introduced are told apart by owner and issue time.
+		key = fmt.Sprintf("%s:%d", input.UserGUID, input.IssuedAt.Unix())
+	}
+
+	now := c.now()
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if ok && now.After(entry.expiresAt) {
+		delete(c.entries, key)
+		ok = false
+	}
+	c.mu.Unlock()
+	if ok {
+		return entry.revoked, nil
+	}
+
+	revoked, err = c.next.IsAccessTokenRevoked(ctx, input)
+	if err != nil {
+		return false, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.evictExpired(now)
+	c.entries[key] = revocationCacheEntry{
+		userGUID:  input.UserGUID,
+		revoked:   revoked,
+		expiresAt: now.Add(c.ttl),
+	}
+	return revoked, nil
+}
+
+// evictExpired drops stale entries at most once per TTL so the cache does not
+// grow with every token ever seen. Callers must hold c.mu.
+func (c *CachedTokenRevocationRepository) evictExpired(now time.Time) {
+	if now.Sub(c.lastEvictedAt) < c.ttl {
+		return
+	}
+	c.lastEvictedAt = now
+
+	for key, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+}
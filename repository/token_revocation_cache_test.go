@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachedTokenRevocationRepository(t *testing.T) {
+	ctx := context.Background()
+	userGUID := uuid.New()
+	input := IsAccessTokenRevokedInput{
+		JTI:      "token-id",
+		UserGUID: userGUID,
+		IssuedAt: time.Now().UTC(),
+	}
+
+	t.Run("when lookup is cached then the store is queried once", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		store := NewMockTokenRevocationRepositoryInterface(ctrl)
+		store.EXPECT().IsAccessTokenRevoked(ctx, input).Return(false, nil).Times(1)
+
+		cache := NewCachedTokenRevocationRepository(store, time.Minute)
+		for i := 0; i < 3; i++ {
+			revoked, err := cache.IsAccessTokenRevoked(ctx, input)
+			assert.NoError(t, err)
+			assert.False(t, revoked)
+		}
+	})
+
+	t.Run("when ttl lapses then the store is queried again", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		store := NewMockTokenRevocationRepositoryInterface(ctrl)
+		store.EXPECT().IsAccessTokenRevoked(ctx, input).Return(false, nil).Times(2)
+
+		now := time.Now()
+		cache := NewCachedTokenRevocationRepository(store, time.Minute)
+		cache.now = func() time.Time { return now }
+
+		_, _ = cache.IsAccessTokenRevoked(ctx, input)
+		now = now.Add(2 * time.Minute)
+		_, _ = cache.IsAccessTokenRevoked(ctx, input)
+	})
+
+	t.Run("when token is revoked locally then it is rejected without a lookup", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		store := NewMockTokenRevocationRepositoryInterface(ctrl)
+		store.EXPECT().IsAccessTokenRevoked(ctx, input).Return(false, nil).Times(1)
+		store.EXPECT().RevokeAccessToken(ctx, gomock.Any()).Return(nil)
+
+		cache := NewCachedTokenRevocationRepository(store, time.Minute)
+		revoked, _ := cache.IsAccessTokenRevoked(ctx, input)
+		assert.False(t, revoked)
+
+		err := cache.RevokeAccessToken(ctx, RevokedAccessToken{
+			JTI:       input.JTI,
+			UserGUID:  userGUID,
+			ExpiresAt: time.Now().Add(time.Hour),
+		})
+		assert.NoError(t, err)
+
+		revoked, _ = cache.IsAccessTokenRevoked(ctx, input)
+		assert.True(t, revoked)
+	})
+
+	t.Run("when user tokens are revoked locally then cached answers are dropped", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		store := NewMockTokenRevocationRepositoryInterface(ctrl)
+		gomock.InOrder(
+			store.EXPECT().IsAccessTokenRevoked(ctx, input).Return(false, nil),
+			store.EXPECT().RevokeUserAccessTokens(ctx, userGUID, gomock.Any()).Return(nil),
+			store.EXPECT().IsAccessTokenRevoked(ctx, input).Return(true, nil),
+		)
+
+		cache := NewCachedTokenRevocationRepository(store, time.Minute)
+		revoked, _ := cache.IsAccessTokenRevoked(ctx, input)
+		assert.False(t, revoked)
+
+		err := cache.RevokeUserAccessTokens(ctx, userGUID, time.Now().UTC())
+		assert.NoError(t, err)
+
+		revoked, _ = cache.IsAccessTokenRevoked(ctx, input)
+		assert.True(t, revoked)
+	})
+}
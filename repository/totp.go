@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/lib/pq"
+)
+
+// UpsertPendingUserTOTP stores a new secret waiting for its first code. An
+// enrollment that is already enabled is left untouched.
+func (r *Repository) UpsertPendingUserTOTP(ctx context.Context, userID int, secretCiphertext string) error {
+	_, err := r.Db.ExecContext(
+		ctx,
+		`INSERT INTO user_totp (user_id, secret_ciphertext) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET secret_ciphertext = EXCLUDED.secret_ciphertext, last_used_step = 0
+		WHERE user_totp.enabled_at IS NULL`,
+		userID,
+		secretCiphertext,
+	)
+	return ConvertPGError(err)
+}
+
+func (r *Repository) GetUserTOTP(ctx context.Context, userID int) (totp *UserTOTP, err error) {
+	totp = new(UserTOTP)
+	err = r.Db.QueryRowContext(
+		ctx,
+		`SELECT user_id, secret_ciphertext, last_used_step, enabled_at, created_at, last_modified_at
+		FROM user_totp WHERE user_id = $1`,
+		userID,
+	).Scan(
+		&totp.UserID,
+		&totp.SecretCiphertext,
+		&totp.LastUsedStep,
+		&totp.EnabledAt,
+		&totp.CreatedAt,
+		&totp.LastModifiedAt,
+	)
+	if err != nil {
+		return
+	}
+	return
+}
+
+// EnableUserTOTP turns on a pending enrollment and replaces the user's
+// recovery codes in one statement. It reports false when the enrollment was
+// already enabled.
+func (r *Repository) EnableUserTOTP(ctx context.Context, userID int, usedStep int64, recoveryCodeHashes []string) (enabled bool, err error) {
+	result, err := r.Db.ExecContext(
+		ctx,
+		`WITH enabled AS (
+			UPDATE user_totp SET enabled_at = NOW(), last_used_step = $2
+			WHERE user_id = $1 AND enabled_at IS NULL
+			RETURNING user_id
+		), removed AS (
+			DELETE FROM totp_recovery_codes WHERE user_id IN (SELECT user_id FROM enabled)
+		)
+		INSERT INTO totp_recovery_codes (user_id, code_hash)
+		SELECT enabled.user_id, code_hash FROM enabled, unnest($3::TEXT[]) AS code_hash`,
+		userID,
+		usedStep,
+		pq.Array(recoveryCodeHashes),
+	)
+	if err != nil {
+		return false, ConvertPGError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, ConvertPGError(err)
+	}
+	return affected > 0, nil
+}
+
+// MarkUserTOTPStepUsed records the step of an accepted code. It reports false
+// when that step or a later one was already used, so the code is a replay.
+func (r *Repository) MarkUserTOTPStepUsed(ctx context.Context, userID int, step int64) (marked bool, err error) {
+	result, err := r.Db.ExecContext(
+		ctx,
+		"UPDATE user_totp SET last_used_step = $2 WHERE user_id = $1 AND enabled_at IS NOT NULL AND last_used_step < $2",
+		userID,
+		step,
+	)
+	if err != nil {
+		return false, ConvertPGError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, ConvertPGError(err)
+	}
+	return affected == 1, nil
+}
+
+// UseTOTPRecoveryCode burns a recovery code. It reports false when the code
+// does not belong to the user or was used before.
+func (r *Repository) UseTOTPRecoveryCode(ctx context.Context, userID int, codeHash string) (used bool, err error) {
+	result, err := r.Db.ExecContext(
+		ctx,
+		"UPDATE totp_recovery_codes SET used_at = NOW() WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL",
+		userID,
+		codeHash,
+	)
+	if err != nil {
+		return false, ConvertPGError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, ConvertPGError(err)
+	}
+	return affected == 1, nil
+}
+
+func (r *Repository) CreateLoginChallenge(ctx context.Context, challenge *LoginChallenge) (err error) {
+	err = r.Db.QueryRowContext(
+		ctx,
+		`INSERT INTO login_challenges (user_id, token_hash, expires_at, restore_account) VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`,
+		challenge.UserID,
+		challenge.TokenHash,
+		challenge.ExpiresAt,
+		challenge.RestoreAccount,
+	).Scan(&challenge.ID, &challenge.CreatedAt)
+	return ConvertPGError(err)
+}
+
+func (r *Repository) GetLoginChallengeByTokenHash(ctx context.Context, tokenHash string) (challenge *LoginChallenge, err error) {
+	challenge = new(LoginChallenge)
+	err = r.Db.QueryRowContext(
+		ctx,
+		`SELECT lc.id, lc.user_id, lc.token_hash, lc.attempts, lc.expires_at, lc.consumed_at, lc.restore_account,
+			lc.created_at, u.guid, u.full_name, u.phone_number
+		FROM login_challenges lc
+		JOIN users u ON u.id = lc.user_id AND u.purged_at IS NULL AND (u.deleted_at IS NULL OR lc.restore_account)
+		WHERE lc.token_hash = $1`,
+		tokenHash,
+	).Scan(
+		&challenge.ID,
+		&challenge.UserID,
+		&challenge.TokenHash,
+		&challenge.Attempts,
+		&challenge.ExpiresAt,
+		&challenge.ConsumedAt,
+		&challenge.RestoreAccount,
+		&challenge.CreatedAt,
+		&challenge.UserGUID,
+		&challenge.FullName,
+		&challenge.PhoneNumber,
+	)
+	if err != nil {
+		return
+	}
+	return
+}
+
+// UseLoginChallengeAttempt spends one of the maxAttempts guesses at a second
+// factor before it is checked, in one statement so concurrent guesses cannot
+// overdraw the limit. It reports false when the attempts had run out.
+func (r *Repository) UseLoginChallengeAttempt(ctx context.Context, id int, maxAttempts int) (used bool, err error) {
+	result, err := r.Db.ExecContext(
+		ctx,
+		"UPDATE login_challenges SET attempts = attempts + 1 WHERE id = $1 AND attempts < $2",
+		id,
+		maxAttempts,
+	)
+	if err != nil {
+		return false, ConvertPGError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, ConvertPGError(err)
+	}
+	return affected == 1, nil
+}
+
+// ConsumeLoginChallenge closes the challenge. It reports false when it had
+// already been consumed.
+func (r *Repository) ConsumeLoginChallenge(ctx context.Context, id int) (consumed bool, err error) {
+	result, err := r.Db.ExecContext(
+		ctx,
+		"UPDATE login_challenges SET consumed_at = NOW() WHERE id = $1 AND consumed_at IS NULL",
+		id,
+	)
+	if err != nil {
+		return false, ConvertPGError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, ConvertPGError(err)
+	}
+	return affected == 1, nil
+}
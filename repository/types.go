@@ -19,6 +19,12 @@ type User struct {
 	FullName    string
 	PhoneNumber string
 	Password    string
+	// PhoneVerifiedAt is nil until the user proves they own PhoneNumber.
+	PhoneVerifiedAt *time.Time
+	// PendingPhoneNumber replaces PhoneNumber once it is confirmed.
+	PendingPhoneNumber *string
+	// DisabledAt is set while staff keep the account from logging in.
+	DisabledAt *time.Time
 
 	RecordTimeStamp
 }
@@ -35,9 +41,37 @@ type LoginUserInput struct {
 }
 
 type LoginUserOutput struct {
-	GUID     uuid.UUID
-	FullName string
-	Password string
+	ID                int
+	GUID              uuid.UUID
+	FullName          string
+	Password          string
+	PasswordChangedAt time.Time
+	PhoneVerifiedAt   *time.Time
+	DisabledAt        *time.Time
+	// DeletedAt is only set on deleted accounts that can still be restored.
+	DeletedAt *time.Time
+	// TOTPEnabled means the password alone is not enough to log in.
+	TOTPEnabled bool
+}
+
+// ListUsersInput filters ListUsers. Nil and empty fields do not filter.
+type ListUsersInput struct {
+	CreatedFrom   *time.Time
+	CreatedTo     *time.Time
+	PhoneVerified *bool
+	Disabled      *bool
+	PhonePrefix   string
+	// NameContains matches any part of the full name, ignoring case.
+	NameContains string
+	// After continues the listing after the user it points at.
+	After *UserCursor
+	Limit int
+}
+
+// UserCursor is the position of a user in the newest first listing.
+type UserCursor struct {
+	CreatedAt time.Time
+	ID        int
 }
 
 type GetUserByGUIDOutput struct {
@@ -46,3 +80,232 @@ type GetUserByGUIDOutput struct {
 	FullName    string
 	PhoneNumber string
 }
+
+type RefreshToken struct {
+	ID     int
+	UserID int
+	// OAuthClientID and Scope are set when the token was issued to a
+	// third-party client through the OAuth2 token endpoint.
+	OAuthClientID *int
+	Scope         string
+	FamilyID      uuid.UUID
+	TokenHash     string
+	ExpiresAt     time.Time
+	UsedAt        *time.Time
+	RevokedAt     *time.Time
+	CreatedAt     time.Time
+
+	// UserGUID, FullName and PhoneNumber are joined from the owning user so a
+	// rotated access token can be issued without another lookup.
+	UserGUID    uuid.UUID
+	FullName    string
+	PhoneNumber string
+}
+
+type RevokedAccessToken struct {
+	JTI       string
+	UserGUID  uuid.UUID
+	ExpiresAt time.Time
+}
+
+type IsAccessTokenRevokedInput struct {
+	JTI      string
+	UserGUID uuid.UUID
+	IssuedAt time.Time
+}
+
+type OAuthClient struct {
+	ID       int
+	ClientID string
+	Name     string
+	// ClientSecretHash is nil for public clients, which authenticate with PKCE
+	// alone.
+	ClientSecretHash *string
+	RedirectURIs     []string
+	Scopes           []string
+	GrantTypes       []string
+
+	RecordTimeStamp
+}
+
+type OAuthAuthorizationCode struct {
+	ID                   int
+	CodeHash             string
+	OAuthClientID        int
+	UserID               int
+	RedirectURI          string
+	RedirectURISent      bool
+	Scope                string
+	CodeChallenge        string
+	CodeChallengeMethod  string
+	Nonce                string
+	RefreshTokenFamilyID *uuid.UUID
+	ExpiresAt            time.Time
+	UsedAt               *time.Time
+	CreatedAt            time.Time
+
+	// UserGUID, FullName and PhoneNumber are joined from the user who granted
+	// the code so tokens can be issued without another lookup.
+	UserGUID    uuid.UUID
+	FullName    string
+	PhoneNumber string
+}
+
+type OAuthConsent struct {
+	ID            int
+	UserID        int
+	OAuthClientID int
+	Scopes        []string
+	RevokedAt     *time.Time
+
+	RecordTimeStamp
+}
+
+// OTP purposes keep codes sent for one flow from being accepted by another.
+const (
+	OTPPurposePasswordReset     = "password_reset"
+	OTPPurposePhoneVerification = "phone_verification"
+	OTPPurposePhoneChange       = "phone_change"
+	OTPPurposeLogin             = "login"
+)
+
+// OTPCode is a one-time code sent by SMS. Verifying it hands out a
+// verification token, which is what completes the flow.
+type OTPCode struct {
+	ID                    int
+	UserID                int
+	Purpose               string
+	PhoneNumber           string
+	CodeHash              string
+	Attempts              int
+	VerificationTokenHash *string
+	ExpiresAt             time.Time
+	VerifiedAt            *time.Time
+	ConsumedAt            *time.Time
+	CreatedAt             time.Time
+
+	UserGUID uuid.UUID
+}
+
+// UserTOTP is a user's authenticator app enrollment. It only counts as two
+// factor authentication once EnabledAt is set.
+type UserTOTP struct {
+	UserID           int
+	SecretCiphertext string
+	LastUsedStep     int64
+	EnabledAt        *time.Time
+
+	RecordTimeStamp
+}
+
+// LoginChallenge is handed out by the password step of a two-factor login and
+// exchanged for tokens together with a second factor.
+type LoginChallenge struct {
+	ID         int
+	UserID     int
+	TokenHash  string
+	Attempts   int
+	ExpiresAt  time.Time
+	ConsumedAt *time.Time
+	// RestoreAccount restores the user's deleted account once the challenge
+	// is passed.
+	RestoreAccount bool
+	CreatedAt      time.Time
+
+	// UserGUID, FullName and PhoneNumber are joined from the user so tokens
+	// can be issued without another lookup.
+	UserGUID    uuid.UUID
+	FullName    string
+	PhoneNumber string
+}
+
+// LoginThrottle counts the recent failed password logins for a subject,
+// either an account or a client IP.
+type LoginThrottle struct {
+	Subject        string
+	FailedAttempts int
+	LastFailedAt   time.Time
+	BlockedUntil   *time.Time
+	CreatedAt      time.Time
+}
+
+// UserAccess is what a user may do, by role and by the permissions the roles
+// grant.
+type UserAccess struct {
+	Roles       []string
+	Permissions []string
+}
+
+// Audit actions recorded for staff changes to accounts.
+const (
+	AuditActionUserViewed   = "user.viewed"
+	AuditActionUserUpdated  = "user.updated"
+	AuditActionUserDeleted  = "user.deleted"
+	AuditActionUserDisabled = "user.disabled"
+	AuditActionUserEnabled  = "user.enabled"
+	AuditActionUserUnlocked = "user.unlocked"
+)
+
+// AuditLog is one entry of the audit trail. Details holds whatever the
+// action needs to be understood later, such as the fields that changed.
+type AuditLog struct {
+	ID           int64
+	ActorGUID    *uuid.UUID
+	Action       string
+	TargetUserID *int
+	IPAddress    string
+	Details      map[string]string
+	CreatedAt    time.Time
+}
+
+// Data export formats and the states an export goes through.
+const (
+	DataExportFormatJSON = "json"
+	DataExportFormatZIP  = "zip"
+
+	DataExportStatusPending    = "pending"
+	DataExportStatusProcessing = "processing"
+	DataExportStatusReady      = "ready"
+	DataExportStatusFailed     = "failed"
+	DataExportStatusExpired    = "expired"
+)
+
+// DataExport is a user's request for a copy of their data. The archive itself
+// is only loaded for download.
+type DataExport struct {
+	ID     int
+	GUID   uuid.UUID
+	UserID int
+	Format string
+	Status string
+	// Error says why a failed export failed.
+	Error       *string
+	CreatedAt   time.Time
+	StartedAt   *time.Time
+	CompletedAt *time.Time
+	// ExpiresAt is when a ready archive is deleted.
+	ExpiresAt *time.Time
+
+	// UserGUID is joined from the user the export is about.
+	UserGUID uuid.UUID
+}
+
+// UserSession is one login, that is one refresh token family, and how long it
+// was kept alive.
+type UserSession struct {
+	FamilyID uuid.UUID
+	// ClientName is set when the login was through a third-party client.
+	ClientName      *string
+	Scope           string
+	StartedAt       time.Time
+	LastRefreshedAt time.Time
+	ExpiresAt       time.Time
+	RevokedAt       *time.Time
+}
+
+// UserOAuthConsent is a consent together with the name of the client it was
+// given to.
+type UserOAuthConsent struct {
+	OAuthConsent
+	ClientName string
+}
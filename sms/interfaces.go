@@ -0,0 +1,13 @@
+// This file contains the interfaces for delivering SMS messages.
+// For testing purpose we will generate mock implementations of these
+// interfaces using mockgen. See the Makefile for more information.
+package sms
+
+import "context"
+
+// SMSSender delivers a text message to a phone number. Implementations for a
+// real SMS gateway plug in here; the ones in this package are for local
+// development and tests.
+type SMSSender interface {
+	Send(ctx context.Context, phoneNumber string, message string) error
+}
@@ -0,0 +1,132 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Sender drivers accepted by NewSender.
+const (
+	DriverConsole = "console"
+	DriverFile    = "file"
+	DriverMemory  = "memory"
+)
+
+// NewSender builds the sender named by driver. The file driver appends to
+// filePath; the console driver writes to stdout; the memory driver keeps the
+// messages in the process.
+func NewSender(driver string, filePath string) (SMSSender, error) {
+	switch driver {
+	case "", DriverConsole:
+		return NewConsoleSender(os.Stdout), nil
+	case DriverFile:
+		if filePath == "" {
+			return nil, fmt.Errorf("sms driver %q needs a file path", driver)
+		}
+		return NewFileSender(filePath), nil
+	case DriverMemory:
+		return NewMemorySender(), nil
+	default:
+		return nil, fmt.Errorf("unknown sms driver %q", driver)
+	}
+}
+
+// ConsoleSender prints every message instead of sending it.
+type ConsoleSender struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewConsoleSender(w io.Writer) *ConsoleSender {
+	return &ConsoleSender{w: w}
+}
+
+func (s *ConsoleSender) Send(_ context.Context, phoneNumber string, message string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := fmt.Fprintf(s.w, "[sms] to %s: %s\n", phoneNumber, message)
+	return err
+}
+
+// SentMessage is a line written by FileSender.
+type SentMessage struct {
+	PhoneNumber string    `json:"phone_number"`
+	Message     string    `json:"message"`
+	SentAt      time.Time `json:"sent_at"`
+}
+
+// FileSender appends every message to a file as a JSON line, which scripts
+// and end-to-end tests can read the codes back from.
+type FileSender struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileSender(path string) *FileSender {
+	return &FileSender{path: path}
+}
+
+func (s *FileSender) Send(_ context.Context, phoneNumber string, message string) error {
+	line, err := json.Marshal(SentMessage{PhoneNumber: phoneNumber, Message: message, SentAt: time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// MemorySender keeps every message in memory, so tests can read the codes a
+// flow sent without a gateway.
+type MemorySender struct {
+	mu       sync.Mutex
+	messages []SentMessage
+}
+
+func NewMemorySender() *MemorySender {
+	return &MemorySender{}
+}
+
+func (s *MemorySender) Send(_ context.Context, phoneNumber string, message string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.messages = append(s.messages, SentMessage{PhoneNumber: phoneNumber, Message: message, SentAt: time.Now().UTC()})
+	return nil
+}
+
+// Messages returns a copy of everything sent so far, oldest first.
+func (s *MemorySender) Messages() []SentMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]SentMessage(nil), s.messages...)
+}
+
+// LastMessageTo returns the latest message sent to phoneNumber.
+func (s *MemorySender) LastMessageTo(phoneNumber string) (message SentMessage, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := len(s.messages) - 1; i >= 0; i-- {
+		if s.messages[i].PhoneNumber == phoneNumber {
+			return s.messages[i], true
+		}
+	}
+	return SentMessage{}, false
+}
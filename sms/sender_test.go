@@ -0,0 +1,85 @@
+package sms
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsoleSender(t *testing.T) {
+	var out bytes.Buffer
+
+	err := NewConsoleSender(&out).Send(context.Background(), "+62345678901", "Your code is 123456")
+	assert.NoError(t, err)
+	assert.Equal(t, "[sms] to +62345678901: Your code is 123456\n", out.String())
+}
+
+func TestFileSender(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sms.log")
+	sender := NewFileSender(path)
+
+	assert.NoError(t, sender.Send(context.Background(), "+62345678901", "first"))
+	assert.NoError(t, sender.Send(context.Background(), "+62345678902", "second"))
+
+	f, err := os.Open(path)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	var sent []SentMessage
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var message SentMessage
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &message))
+		sent = append(sent, message)
+	}
+	assert.Len(t, sent, 2)
+	assert.Equal(t, "+62345678902", sent[1].PhoneNumber)
+	assert.Equal(t, "second", sent[1].Message)
+}
+
+func TestMemorySender(t *testing.T) {
+	sender := NewMemorySender()
+
+	assert.NoError(t, sender.Send(context.Background(), "+62345678901", "first"))
+	assert.NoError(t, sender.Send(context.Background(), "+62345678902", "second"))
+	assert.NoError(t, sender.Send(context.Background(), "+62345678901", "third"))
+
+	assert.Len(t, sender.Messages(), 3)
+
+	message, ok := sender.LastMessageTo("+62345678901")
+	assert.True(t, ok)
+	assert.Equal(t, "third", message.Message)
+
+	_, ok = sender.LastMessageTo("+62345678903")
+	assert.False(t, ok)
+}
+
+func TestNewSender(t *testing.T) {
+	t.Run("when success build the console sender by default", func(t *testing.T) {
+		sender, err := NewSender("", "")
+		assert.NoError(t, err)
+		assert.IsType(t, &ConsoleSender{}, sender)
+	})
+
+	t.Run("when success build the memory sender", func(t *testing.T) {
+		sender, err := NewSender(DriverMemory, "")
+		assert.NoError(t, err)
+		assert.IsType(t, &MemorySender{}, sender)
+	})
+
+	t.Run("when error due to file driver has no path", func(t *testing.T) {
+		_, err := NewSender(DriverFile, "")
+		assert.Error(t, err)
+	})
+
+	t.Run("when error due to driver is unknown", func(t *testing.T) {
+		_, err := NewSender("carrier-pigeon", "")
+		assert.Error(t, err)
+	})
+}
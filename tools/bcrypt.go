@@ -4,6 +4,9 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// HashPassword hashes pwd with bcrypt at the default cost. The service hashes
+// passwords and codes with its configured PasswordHasher instead; this is for
+// fixtures and one-off hashes such as OAuth2 client secrets.
 func HashPassword(pwd string) (hashedPwd string, err error) {
 	hashed, err := bcrypt.GenerateFromPassword([]byte(pwd), bcrypt.DefaultCost)
 	if err != nil {
@@ -13,11 +16,8 @@ func HashPassword(pwd string) (hashedPwd string, err error) {
 	return
 }
 
+// IsValidPassword reports whether pwd matches hashed, which may be a bcrypt or
+// an argon2id hash.
 func IsValidPassword(hashed string, pwd string) bool {
-	err := bcrypt.CompareHashAndPassword(
-		[]byte(hashed),
-		[]byte(pwd),
-	)
-
-	return err == nil
+	return VerifyPassword(hashed, pwd)
 }
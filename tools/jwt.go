@@ -8,30 +8,87 @@ import (
 )
 
 type GenerateJWTTokenParams struct {
-	FullName string    `json:"full_name"`
-	GUID     uuid.UUID `json:"user_guid"`
+	FullName    string    `json:"full_name"`
+	GUID        uuid.UUID `json:"user_guid"`
+	PhoneNumber string    `json:"phone_number,omitempty"`
+	// Scope and ClientID are only set on tokens issued to OAuth2 clients.
+	Scope    string `json:"scope,omitempty"`
+	ClientID string `json:"client_id,omitempty"`
+	// Roles and Permissions are only set on tokens from the service's own
+	// logins; OAuth2 clients never act with the user's privileges.
+	Roles       []string `json:"roles,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+
+	// Issuer and Audience become the registered iss and aud claims.
+	Issuer   string   `json:"-"`
+	Audience []string `json:"-"`
 }
 
 type JWTCustomClaims struct {
 	GenerateJWTTokenParams
+	// Name repeats FullName under the OpenID Connect standard claim name.
+	Name string `json:"name,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func generateToken(claim JWTCustomClaims, lifetime int, key string) (tokenString string, expiredAt time.Time, err error) {
+// GenerateClientJWTTokenParams describes a token issued to an OAuth2 client
+// acting on its own behalf, which has no user and therefore no user_guid.
+type GenerateClientJWTTokenParams struct {
+	ClientID string
+	Scope    string
+	Issuer   string
+	Audience []string
+}
+
+type ClientJWTClaims struct {
+	ClientID string `json:"client_id"`
+	Scope    string `json:"scope,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// GenerateIDTokenParams describes an OpenID Connect ID token telling the
+// client which user signed in.
+type GenerateIDTokenParams struct {
+	GUID     uuid.UUID
+	ClientID string
+	// Nonce is repeated from the authorization request when the client sent
+	// one, so the client can tie the token to its own request.
+	Nonce  string
+	Issuer string
+}
+
+type IDTokenClaims struct {
+	Nonce string `json:"nonce,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// setLifetime stamps the token id and validity window on claims.
+func setLifetime(claims *jwt.RegisteredClaims, lifetime int) time.Time {
 	now := time.Now().UTC()
 	timeExpiredAt := now.Add(time.Duration(lifetime) * time.Hour)
 
-	claim.IssuedAt = jwt.NewNumericDate(now)
-	claim.ExpiresAt = jwt.NewNumericDate(timeExpiredAt)
+	claims.ID = uuid.NewString()
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.ExpiresAt = jwt.NewNumericDate(timeExpiredAt)
+	return timeExpiredAt
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claim)
+func signToken(claims jwt.Claims, key string) (tokenString string, err error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
 
 	parsedKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(key))
 	if err != nil {
 		return
 	}
+	token.Header["kid"] = KeyID(&parsedKey.PublicKey)
+
+	return token.SignedString(parsedKey)
+}
+
+func generateToken(claim JWTCustomClaims, lifetime int, key string) (tokenString string, expiredAt time.Time, err error) {
+	timeExpiredAt := setLifetime(&claim.RegisteredClaims, lifetime)
 
-	tokenString, err = token.SignedString(parsedKey)
+	tokenString, err = signToken(claim, key)
 	if err != nil {
 		return
 	}
@@ -40,7 +97,56 @@ func generateToken(claim JWTCustomClaims, lifetime int, key string) (tokenString
 }
 
 func GenerateJWTToken(params GenerateJWTTokenParams, lifetime int, key string) (token string, expiredAt time.Time, err error) {
-	claim := JWTCustomClaims{GenerateJWTTokenParams: params}
+	claim := JWTCustomClaims{
+		GenerateJWTTokenParams: params,
+		Name:                   params.FullName,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:  params.GUID.String(),
+			Issuer:   params.Issuer,
+			Audience: params.Audience,
+		},
+	}
 
 	return generateToken(claim, lifetime, key)
 }
+
+func GenerateClientJWTToken(params GenerateClientJWTTokenParams, lifetime int, key string) (token string, expiredAt time.Time, err error) {
+	claim := ClientJWTClaims{
+		ClientID: params.ClientID,
+		Scope:    params.Scope,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:  params.ClientID,
+			Issuer:   params.Issuer,
+			Audience: params.Audience,
+		},
+	}
+	timeExpiredAt := setLifetime(&claim.RegisteredClaims, lifetime)
+
+	token, err = signToken(claim, key)
+	if err != nil {
+		return
+	}
+
+	return token, timeExpiredAt, nil
+}
+
+// GenerateIDToken signs an ID token whose audience is the client it is issued
+// to.
+func GenerateIDToken(params GenerateIDTokenParams, lifetime int, key string) (token string, expiredAt time.Time, err error) {
+	claim := IDTokenClaims{
+		Nonce: params.Nonce,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:  params.GUID.String(),
+			Issuer:   params.Issuer,
+			Audience: jwt.ClaimStrings{params.ClientID},
+		},
+	}
+	timeExpiredAt := setLifetime(&claim.RegisteredClaims, lifetime)
+
+	token, err = signToken(claim, key)
+	if err != nil {
+		return
+	}
+
+	return token, timeExpiredAt, nil
+}
@@ -0,0 +1,110 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateJWTToken(t *testing.T) {
+	t.Run("token carries the standard and custom claims", func(t *testing.T) {
+		params := GenerateJWTTokenParams{
+			FullName:    "SawitPro Mania",
+			GUID:        uuid.New(),
+			PhoneNumber: "+62345678901",
+			Roles:       []string{"support"},
+			Permissions: []string{"users:read"},
+			Issuer:      "https://auth.sawitpro.com",
+			Audience:    []string{"user-service"},
+		}
+
+		tokenString, _, err := GenerateJWTToken(params, 1, MockRSAPrivateKey())
+		assert.NoError(t, err)
+
+		claims := jwt.MapClaims{}
+		_, err = jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+			return jwt.ParseRSAPublicKeyFromPEM([]byte(MockRSAPublicKey()))
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, params.GUID.String(), claims["sub"])
+		assert.Equal(t, params.GUID.String(), claims["user_guid"])
+		assert.Equal(t, params.FullName, claims["name"])
+		assert.Equal(t, params.FullName, claims["full_name"])
+		assert.Equal(t, params.PhoneNumber, claims["phone_number"])
+		assert.Equal(t, []interface{}{"support"}, claims["roles"])
+		assert.Equal(t, []interface{}{"users:read"}, claims["permissions"])
+		assert.Equal(t, params.Issuer, claims["iss"])
+		assert.Equal(t, []interface{}{"user-service"}, claims["aud"])
+		assert.NotEmpty(t, claims["jti"])
+	})
+
+	t.Run("error when private key is invalid", func(t *testing.T) {
+		_, _, err := GenerateJWTToken(GenerateJWTTokenParams{GUID: uuid.New()}, 1, "invalid")
+		assert.Error(t, err)
+	})
+}
+
+func TestGenerateClientJWTToken(t *testing.T) {
+	t.Run("token carries the client instead of a user", func(t *testing.T) {
+		params := GenerateClientJWTTokenParams{
+			ClientID: "billing-job",
+			Scope:    "users:read",
+			Issuer:   "https://auth.sawitpro.com",
+		}
+
+		tokenString, _, err := GenerateClientJWTToken(params, 1, MockRSAPrivateKey())
+		assert.NoError(t, err)
+
+		claims := jwt.MapClaims{}
+		_, err = jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+			return jwt.ParseRSAPublicKeyFromPEM([]byte(MockRSAPublicKey()))
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, "billing-job", claims["sub"])
+		assert.Equal(t, "billing-job", claims["client_id"])
+		assert.Equal(t, "users:read", claims["scope"])
+		assert.NotContains(t, claims, "user_guid")
+		assert.NotEmpty(t, claims["jti"])
+	})
+}
+
+func TestGenerateIDToken(t *testing.T) {
+	t.Run("token names the user, the client and the nonce", func(t *testing.T) {
+		params := GenerateIDTokenParams{
+			GUID:     uuid.New(),
+			ClientID: "third-party-app",
+			Nonce:    "n-0S6_WzA2Mj",
+			Issuer:   "https://auth.sawitpro.com",
+		}
+
+		tokenString, _, err := GenerateIDToken(params, 1, MockRSAPrivateKey())
+		assert.NoError(t, err)
+
+		claims := jwt.MapClaims{}
+		_, err = jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+			return jwt.ParseRSAPublicKeyFromPEM([]byte(MockRSAPublicKey()))
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, params.GUID.String(), claims["sub"])
+		assert.Equal(t, params.Issuer, claims["iss"])
+		assert.Equal(t, []interface{}{"third-party-app"}, claims["aud"])
+		assert.Equal(t, "n-0S6_WzA2Mj", claims["nonce"])
+		assert.NotEmpty(t, claims["exp"])
+		assert.NotEmpty(t, claims["iat"])
+	})
+
+	t.Run("token leaves out a nonce that was not sent", func(t *testing.T) {
+		tokenString, _, err := GenerateIDToken(GenerateIDTokenParams{GUID: uuid.New(), ClientID: "third-party-app"}, 1, MockRSAPrivateKey())
+		assert.NoError(t, err)
+
+		claims := jwt.MapClaims{}
+		_, _, err = jwt.NewParser().ParseUnverified(tokenString, claims)
+		assert.NoError(t, err)
+		assert.NotContains(t, claims, "nonce")
+	})
+}
@@ -0,0 +1,160 @@
+package tools
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrUnknownKeyID = errors.New("unknown signing key id")
+
+// JSONWebKey is the public half of an RSA signing key as published in a JWKS.
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type KeyRingOptions struct {
+	// PrivateKey is the PEM encoded key new tokens are signed with.
+	PrivateKey string
+	// PublicKey is the PEM encoded public half of PrivateKey. It is only
+	// needed when the ring is used to verify tokens without a private key.
+	PublicKey string
+	// RetiredPublicKeys holds zero or more concatenated PEM blocks of keys
+	// that no longer sign tokens but whose tokens are still accepted.
+	RetiredPublicKeys string
+}
+
+// KeyRing holds the active signing key and every public key tokens may still
+// be verified with, indexed by their key id.
+type KeyRing struct {
+	activeKeyID string
+	publicKeys  map[string]*rsa.PublicKey
+	keyIDs      []string
+}
+
+func NewKeyRing(opts KeyRingOptions) (*KeyRing, error) {
+	ring := &KeyRing{publicKeys: make(map[string]*rsa.PublicKey)}
+
+	if opts.PrivateKey != "" {
+		privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(opts.PrivateKey))
+		if err != nil {
+			return nil, fmt.Errorf("parse private key: %w", err)
+		}
+		ring.activeKeyID = ring.add(&privateKey.PublicKey)
+	}
+
+	if opts.PublicKey != "" {
+		publicKey, err := jwt.ParseRSAPublicKeyFromPEM([]byte(opts.PublicKey))
+		if err != nil {
+			return nil, fmt.Errorf("parse public key: %w", err)
+		}
+		keyID := ring.add(publicKey)
+		if ring.activeKeyID == "" {
+			ring.activeKeyID = keyID
+		}
+	}
+
+	rest := []byte(strings.TrimSpace(opts.RetiredPublicKeys))
+	for len(rest) > 0 {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return nil, errors.New("parse retired public keys: invalid PEM data")
+		}
+
+		publicKey, err := jwt.ParseRSAPublicKeyFromPEM(pem.EncodeToMemory(block))
+		if err != nil {
+			return nil, fmt.Errorf("parse retired public key: %w", err)
+		}
+		ring.add(publicKey)
+	}
+
+	if ring.activeKeyID == "" {
+		return nil, errors.New("key ring needs a private or public key")
+	}
+	return ring, nil
+}
+
+func (k *KeyRing) add(publicKey *rsa.PublicKey) string {
+	keyID := KeyID(publicKey)
+	if _, ok := k.publicKeys[keyID]; !ok {
+		k.publicKeys[keyID] = publicKey
+		k.keyIDs = append(k.keyIDs, keyID)
+	}
+	return keyID
+}
+
+func (k *KeyRing) ActiveKeyID() string {
+	return k.activeKeyID
+}
+
+// Keyfunc resolves the verification key from the token's kid header. Tokens
+// without a kid were issued before key rotation existed and are checked
+// against the active key.
+func (k *KeyRing) Keyfunc(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+		return nil, jwt.ErrSignatureInvalid
+	}
+
+	keyID, _ := token.Header["kid"].(string)
+	if keyID == "" {
+		keyID = k.activeKeyID
+	}
+
+	publicKey, ok := k.publicKeys[keyID]
+	if !ok {
+		return nil, ErrUnknownKeyID
+	}
+	return publicKey, nil
+}
+
+// JWKS lists every verification key, the active one first.
+func (k *KeyRing) JWKS() []JSONWebKey {
+	keys := make([]JSONWebKey, 0, len(k.keyIDs))
+	keys = append(keys, jsonWebKey(k.activeKeyID, k.publicKeys[k.activeKeyID]))
+	for _, keyID := range k.keyIDs {
+		if keyID == k.activeKeyID {
+			continue
+		}
+		keys = append(keys, jsonWebKey(keyID, k.publicKeys[keyID]))
+	}
+	return keys
+}
+
+func jsonWebKey(keyID string, publicKey *rsa.PublicKey) JSONWebKey {
+	n, e := rsaPublicKeyParams(publicKey)
+	return JSONWebKey{
+		Kty: "RSA",
+		Use: "sig",
+		Alg: jwt.SigningMethodRS256.Alg(),
+		Kid: keyID,
+		N:   n,
+		E:   e,
+	}
+}
+
+func rsaPublicKeyParams(publicKey *rsa.PublicKey) (n string, e string) {
+	n = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+	e = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+	return
+}
+
+// KeyID derives a stable key id from the RFC 7638 JWK thumbprint of the key,
+// so every replica computes the same kid without extra configuration.
+func KeyID(publicKey *rsa.PublicKey) string {
+	n, e := rsaPublicKeyParams(publicKey)
+	thumbprint := sha256.Sum256([]byte(`{"e":"` + e + `","kty":"RSA","n":"` + n + `"}`))
+	return base64.RawURLEncoding.EncodeToString(thumbprint[:])
+}
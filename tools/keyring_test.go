@@ -0,0 +1,96 @@
+package tools
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func generateTestKeyPEM(t *testing.T) (privatePEM string, publicPEM string) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	assert.NoError(t, err)
+
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	assert.NoError(t, err)
+
+	privatePEM = string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+	publicPEM = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}))
+	return
+}
+
+func TestKeyRing(t *testing.T) {
+	retiredPrivateKey, retiredPublicKey := generateTestKeyPEM(t)
+	params := GenerateJWTTokenParams{FullName: "SawitPro Mania", GUID: uuid.New()}
+
+	ring, err := NewKeyRing(KeyRingOptions{
+		PrivateKey:        MockRSAPrivateKey(),
+		PublicKey:         MockRSAPublicKey(),
+		RetiredPublicKeys: retiredPublicKey,
+	})
+	assert.NoError(t, err)
+
+	t.Run("token is signed with the kid of the active key", func(t *testing.T) {
+		tokenString, _, err := GenerateJWTToken(params, 1, MockRSAPrivateKey())
+		assert.NoError(t, err)
+
+		token, err := jwt.ParseWithClaims(tokenString, new(JWTCustomClaims), ring.Keyfunc)
+		assert.NoError(t, err)
+		assert.Equal(t, ring.ActiveKeyID(), token.Header["kid"])
+	})
+
+	t.Run("token signed with a retired key is still accepted", func(t *testing.T) {
+		tokenString, _, err := GenerateJWTToken(params, 1, retiredPrivateKey)
+		assert.NoError(t, err)
+
+		token, err := jwt.ParseWithClaims(tokenString, new(JWTCustomClaims), ring.Keyfunc)
+		assert.NoError(t, err)
+		assert.True(t, token.Valid)
+	})
+
+	t.Run("token without kid is checked against the active key", func(t *testing.T) {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, JWTCustomClaims{GenerateJWTTokenParams: params})
+		key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(MockRSAPrivateKey()))
+		assert.NoError(t, err)
+		tokenString, err := token.SignedString(key)
+		assert.NoError(t, err)
+
+		_, err = jwt.ParseWithClaims(tokenString, new(JWTCustomClaims), ring.Keyfunc)
+		assert.NoError(t, err)
+	})
+
+	t.Run("token signed with an unknown key is rejected", func(t *testing.T) {
+		unknownPrivateKey, _ := generateTestKeyPEM(t)
+		tokenString, _, err := GenerateJWTToken(params, 1, unknownPrivateKey)
+		assert.NoError(t, err)
+
+		_, err = jwt.ParseWithClaims(tokenString, new(JWTCustomClaims), ring.Keyfunc)
+		assert.ErrorIs(t, err, ErrUnknownKeyID)
+	})
+
+	t.Run("jwks lists the active key first", func(t *testing.T) {
+		keys := ring.JWKS()
+		assert.Len(t, keys, 2)
+		assert.Equal(t, ring.ActiveKeyID(), keys[0].Kid)
+		assert.Equal(t, "RSA", keys[0].Kty)
+		assert.Equal(t, "RS256", keys[0].Alg)
+		assert.Equal(t, "AQAB", keys[0].E)
+	})
+}
+
+func TestNewKeyRing_Error(t *testing.T) {
+	t.Run("when no key is configured", func(t *testing.T) {
+		_, err := NewKeyRing(KeyRingOptions{})
+		assert.Error(t, err)
+	})
+
+	t.Run("when retired keys are not valid PEM", func(t *testing.T) {
+		_, err := NewKeyRing(KeyRingOptions{PrivateKey: MockRSAPrivateKey(), RetiredPublicKeys: "not a key"})
+		assert.Error(t, err)
+	})
+}
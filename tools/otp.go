@@ -0,0 +1,22 @@
+package tools
+
+import (
+	"crypto/rand"
+	"math/big"
+)
+
+const OTPLength = 6
+
+// GenerateOTP returns a random numeric code of the given length. Leading zeros
+// are kept, so every code has exactly length digits.
+func GenerateOTP(length int) (string, error) {
+	digits := make([]byte, length)
+	for i := range digits {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		digits[i] = byte('0' + n.Int64())
+	}
+	return string(digits), nil
+}
@@ -0,0 +1,14 @@
+package tools
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateOTP(t *testing.T) {
+	code, err := GenerateOTP(OTPLength)
+	assert.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^[0-9]{6}$`), code)
+}
@@ -0,0 +1,214 @@
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// hibpPrefixLength is the number of leading SHA-1 hex digits that name a range
+// file in the k-anonymity layout served by the Pwned Passwords range API.
+const hibpPrefixLength = 5
+
+type PasswordBlocklistOptions struct {
+	// CommonPasswordsFile lists one password per line, e.g. a top-N list.
+	// Entries are matched case-insensitively.
+	CommonPasswordsFile string
+	// BreachedHashesPath is a Pwned Passwords download in one of two layouts:
+	// a single file of SHA1:COUNT lines sorted by hash, which is searched on
+	// disk without being loaded, or a directory of range files named after
+	// the first five hex digits of the hash and holding SUFFIX:COUNT lines.
+	BreachedHashesPath string
+}
+
+// PasswordBlocklist tells whether a password is too common or has shown up in
+// a breach.
+type PasswordBlocklist struct {
+	common []string
+
+	breachedFile *os.File
+	breachedSize int64
+	breachedDir  string
+}
+
+func LoadPasswordBlocklist(opts PasswordBlocklistOptions) (*PasswordBlocklist, error) {
+	b := &PasswordBlocklist{}
+
+	if opts.CommonPasswordsFile != "" {
+		f, err := os.Open(opts.CommonPasswordsFile)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line != "" {
+				b.common = append(b.common, strings.ToLower(line))
+			}
+		}
+		if err = scanner.Err(); err != nil {
+			return nil, err
+		}
+		sort.Strings(b.common)
+	}
+
+	if opts.BreachedHashesPath != "" {
+		info, err := os.Stat(opts.BreachedHashesPath)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			b.breachedDir = opts.BreachedHashesPath
+		} else {
+			b.breachedFile, err = os.Open(opts.BreachedHashesPath)
+			if err != nil {
+				return nil, err
+			}
+			b.breachedSize = info.Size()
+		}
+	}
+
+	return b, nil
+}
+
+// IsBlocked reports whether pwd is on the common list or among the breached
+// hashes.
+func (b *PasswordBlocklist) IsBlocked(pwd string) (bool, error) {
+	if b.isCommon(pwd) {
+		return true, nil
+	}
+
+	sum := sha1.Sum([]byte(pwd))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	switch {
+	case b.breachedFile != nil:
+		return b.searchBreachedFile(hash)
+	case b.breachedDir != "":
+		return b.searchBreachedRange(hash)
+	default:
+		return false, nil
+	}
+}
+
+// Close releases the breached hashes file.
+func (b *PasswordBlocklist) Close() error {
+	if b.breachedFile == nil {
+		return nil
+	}
+	return b.breachedFile.Close()
+}
+
+func (b *PasswordBlocklist) isCommon(pwd string) bool {
+	pwd = strings.ToLower(pwd)
+	i := sort.SearchStrings(b.common, pwd)
+	return i < len(b.common) && b.common[i] == pwd
+}
+
+// searchBreachedFile binary searches the sorted file by byte offset. low is
+// always the start of a line and every line before it sorts below hash; no
+// line starting at or after high can match.
+func (b *PasswordBlocklist) searchBreachedFile(hash string) (bool, error) {
+	low, high := int64(0), b.breachedSize
+	for low < high {
+		mid := low + (high-low)/2
+		start, err := b.nextLineStart(mid)
+		if err != nil {
+			return false, err
+		}
+		if start >= high {
+			high = mid
+			continue
+		}
+
+		line, next, err := b.readLine(start)
+		if err != nil {
+			return false, err
+		}
+		switch strings.Compare(hibpLineHash(line), hash) {
+		case 0:
+			return true, nil
+		case -1:
+			low = next
+		default:
+			high = start
+		}
+	}
+	return false, nil
+}
+
+// nextLineStart returns the offset of the first line starting at or after
+// offset.
+func (b *PasswordBlocklist) nextLineStart(offset int64) (int64, error) {
+	if offset == 0 {
+		return 0, nil
+	}
+	_, next, err := b.readLine(offset - 1)
+	return next, err
+}
+
+// readLine reads from offset to the end of the line and returns the offset
+// just past it.
+func (b *PasswordBlocklist) readLine(offset int64) (line string, next int64, err error) {
+	reader := bufio.NewReader(io.NewSectionReader(b.breachedFile, offset, b.breachedSize-offset))
+	raw, err := reader.ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return "", 0, err
+	}
+	return string(bytes.TrimSpace(raw)), offset + int64(len(raw)), nil
+}
+
+// searchBreachedRange scans the range file for the hash's prefix.
+func (b *PasswordBlocklist) searchBreachedRange(hash string) (bool, error) {
+	prefix, suffix := hash[:hibpPrefixLength], hash[hibpPrefixLength:]
+
+	f, err := os.Open(filepath.Join(b.breachedDir, prefix+".txt"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if hibpLineHash(scanner.Text()) == suffix {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
+
+// hibpLineHash strips the ":COUNT" from a Pwned Passwords line.
+func hibpLineHash(line string) string {
+	hash, _, _ := strings.Cut(strings.TrimSpace(line), ":")
+	return strings.ToUpper(hash)
+}
+
+var (
+	passwordBlocklistMu sync.RWMutex
+	passwordBlocklist   *PasswordBlocklist
+)
+
+// SetPasswordBlocklist makes the not_breached_pwd validation check blocklist.
+// Without one the validation lets every password through.
+func SetPasswordBlocklist(blocklist *PasswordBlocklist) {
+	passwordBlocklistMu.Lock()
+	defer passwordBlocklistMu.Unlock()
+	passwordBlocklist = blocklist
+}
+
+func currentPasswordBlocklist() *PasswordBlocklist {
+	passwordBlocklistMu.RLock()
+	defer passwordBlocklistMu.RUnlock()
+	return passwordBlocklist
+}
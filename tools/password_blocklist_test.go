@@ -0,0 +1,128 @@
+package tools
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sha1Hex(pwd string) string {
+	sum := sha1.Sum([]byte(pwd))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// writeBreachedHashesFile writes the hashes of passwords in the sorted
+// single-file layout, with counts of varying width like the real download.
+func writeBreachedHashesFile(t *testing.T, passwords []string) string {
+	var lines []string
+	for i, pwd := range passwords {
+		lines = append(lines, fmt.Sprintf("%s:%d", sha1Hex(pwd), (i*7919)%100000+1))
+	}
+	sort.Strings(lines)
+
+	path := filepath.Join(t.TempDir(), "pwned-passwords-sha1-ordered-by-hash.txt")
+	assert.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\r\n")+"\r\n"), 0o600))
+	return path
+}
+
+func TestPasswordBlocklist(t *testing.T) {
+	t.Run("when the password is on the common list then it is blocked in any case", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "common.txt")
+		assert.NoError(t, os.WriteFile(path, []byte("123456\npassword1!\n\nqwerty\n"), 0o600))
+
+		blocklist, err := LoadPasswordBlocklist(PasswordBlocklistOptions{CommonPasswordsFile: path})
+		assert.NoError(t, err)
+
+		blocked, err := blocklist.IsBlocked("Password1!")
+		assert.NoError(t, err)
+		assert.True(t, blocked)
+
+		blocked, err = blocklist.IsBlocked("IloveVirginCo2Nut123$")
+		assert.NoError(t, err)
+		assert.False(t, blocked)
+	})
+
+	t.Run("when the hash is in the sorted file then it is found wherever it sits", func(t *testing.T) {
+		var passwords []string
+		for i := 0; i < 500; i++ {
+			passwords = append(passwords, fmt.Sprintf("Breached%d!", i))
+		}
+		blocklist, err := LoadPasswordBlocklist(PasswordBlocklistOptions{BreachedHashesPath: writeBreachedHashesFile(t, passwords)})
+		assert.NoError(t, err)
+		defer blocklist.Close()
+
+		for _, pwd := range passwords {
+			blocked, err := blocklist.IsBlocked(pwd)
+			assert.NoError(t, err)
+			assert.True(t, blocked, pwd)
+		}
+		for i := 500; i < 600; i++ {
+			blocked, err := blocklist.IsBlocked(fmt.Sprintf("Breached%d!", i))
+			assert.NoError(t, err)
+			assert.False(t, blocked)
+		}
+	})
+
+	t.Run("when the file holds a single hash then it is still found", func(t *testing.T) {
+		blocklist, err := LoadPasswordBlocklist(PasswordBlocklistOptions{BreachedHashesPath: writeBreachedHashesFile(t, []string{"Password1!"})})
+		assert.NoError(t, err)
+		defer blocklist.Close()
+
+		blocked, err := blocklist.IsBlocked("Password1!")
+		assert.NoError(t, err)
+		assert.True(t, blocked)
+	})
+
+	t.Run("when the hashes are split into range files then the prefix file is scanned", func(t *testing.T) {
+		dir := t.TempDir()
+		hash := sha1Hex("Password1!")
+		content := "0018A45C4D1DEF81644B54AB7F969B88D65:1\r\n" + hash[5:] + ":2413945\r\n"
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, hash[:5]+".txt"), []byte(content), 0o600))
+
+		blocklist, err := LoadPasswordBlocklist(PasswordBlocklistOptions{BreachedHashesPath: dir})
+		assert.NoError(t, err)
+
+		blocked, err := blocklist.IsBlocked("Password1!")
+		assert.NoError(t, err)
+		assert.True(t, blocked)
+
+		blocked, err = blocklist.IsBlocked("IloveVirginCo2Nut123$")
+		assert.NoError(t, err)
+		assert.False(t, blocked)
+	})
+
+	t.Run("when a configured file is missing then loading fails", func(t *testing.T) {
+		_, err := LoadPasswordBlocklist(PasswordBlocklistOptions{CommonPasswordsFile: filepath.Join(t.TempDir(), "missing.txt")})
+		assert.Error(t, err)
+	})
+}
+
+func TestValidateNotBreachedPasswordVal(t *testing.T) {
+	type TestPayload struct {
+		Password string `json:"password" validate:"pwd,not_breached_pwd"`
+	}
+	t.Cleanup(func() { SetPasswordBlocklist(nil) })
+
+	SetPasswordBlocklist(nil)
+	assert.NoError(t, ValidateRequestPayload(TestPayload{Password: "Password1!"}))
+
+	blocklist, err := LoadPasswordBlocklist(PasswordBlocklistOptions{BreachedHashesPath: writeBreachedHashesFile(t, []string{"Password1!"})})
+	assert.NoError(t, err)
+	defer blocklist.Close()
+	SetPasswordBlocklist(blocklist)
+
+	err = ValidateRequestPayload(TestPayload{Password: "Password1!"})
+	var errData *Err
+	assert.True(t, errors.As(err, &errData))
+	assert.Equal(t, []ExtraValidation{{Field: "password", Message: "not_breached_pwd"}}, errData.Extra)
+
+	assert.NoError(t, ValidateRequestPayload(TestPayload{Password: "IloveVirginCo2Nut123$"}))
+}
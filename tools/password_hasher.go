@@ -0,0 +1,209 @@
+package tools
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Password hashing algorithms accepted by NewPasswordHasher.
+const (
+	PasswordHashBcrypt   = "bcrypt"
+	PasswordHashArgon2id = "argon2id"
+)
+
+// Argon2id defaults follow the OWASP recommendation of 19 MiB, two passes and
+// one lane.
+const (
+	DefaultArgon2Memory      = 19 * 1024
+	DefaultArgon2Iterations  = 2
+	DefaultArgon2Parallelism = 1
+
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+var errMalformedPasswordHash = errors.New("malformed password hash")
+
+type PasswordHasherOptions struct {
+	// Algorithm new hashes are made with; bcrypt when empty.
+	Algorithm string
+	// BcryptCost defaults to bcrypt.DefaultCost.
+	BcryptCost int
+	// Argon2Memory is in KiB. Zero values take the defaults above.
+	Argon2Memory      uint32
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+}
+
+// PasswordHasher hashes passwords with the configured algorithm and verifies
+// them against hashes made by any supported one, telling the algorithm apart
+// by the hash prefix. NeedsRehash reports hashes made with another algorithm
+// or other parameters, so callers can upgrade them after a successful login.
+type PasswordHasher struct {
+	algorithm  string
+	bcryptCost int
+	argon2id   argon2idParams
+}
+
+type argon2idParams struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+}
+
+func NewPasswordHasher(opts PasswordHasherOptions) (*PasswordHasher, error) {
+	h := &PasswordHasher{
+		algorithm:  opts.Algorithm,
+		bcryptCost: opts.BcryptCost,
+		argon2id: argon2idParams{
+			memory:      opts.Argon2Memory,
+			iterations:  opts.Argon2Iterations,
+			parallelism: opts.Argon2Parallelism,
+		},
+	}
+
+	switch h.algorithm {
+	case "":
+		h.algorithm = PasswordHashBcrypt
+	case PasswordHashBcrypt, PasswordHashArgon2id:
+	default:
+		return nil, fmt.Errorf("unknown password hash algorithm %q", opts.Algorithm)
+	}
+
+	if h.bcryptCost == 0 {
+		h.bcryptCost = bcrypt.DefaultCost
+	}
+	if h.bcryptCost < bcrypt.MinCost || h.bcryptCost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("bcrypt cost must be between %d and %d, got %d", bcrypt.MinCost, bcrypt.MaxCost, h.bcryptCost)
+	}
+
+	if h.argon2id.memory == 0 {
+		h.argon2id.memory = DefaultArgon2Memory
+	}
+	if h.argon2id.iterations == 0 {
+		h.argon2id.iterations = DefaultArgon2Iterations
+	}
+	if h.argon2id.parallelism == 0 {
+		h.argon2id.parallelism = DefaultArgon2Parallelism
+	}
+	return h, nil
+}
+
+// Hash hashes pwd with the configured algorithm.
+func (h *PasswordHasher) Hash(pwd string) (string, error) {
+	if h.algorithm == PasswordHashArgon2id {
+		return hashArgon2id(pwd, h.argon2id)
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(pwd), h.bcryptCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+// Verify reports whether pwd matches hashed, whichever algorithm made it.
+func (h *PasswordHasher) Verify(hashed string, pwd string) bool {
+	return VerifyPassword(hashed, pwd)
+}
+
+// NeedsRehash reports whether hashed was made with another algorithm or with
+// parameters other than the configured ones.
+func (h *PasswordHasher) NeedsRehash(hashed string) bool {
+	switch passwordHashAlgorithm(hashed) {
+	case PasswordHashBcrypt:
+		if h.algorithm != PasswordHashBcrypt {
+			return true
+		}
+		cost, err := bcrypt.Cost([]byte(hashed))
+		return err != nil || cost != h.bcryptCost
+	case PasswordHashArgon2id:
+		if h.algorithm != PasswordHashArgon2id {
+			return true
+		}
+		params, _, _, err := parseArgon2id(hashed)
+		return err != nil || params != h.argon2id
+	default:
+		return true
+	}
+}
+
+// VerifyPassword reports whether pwd matches a bcrypt or argon2id hash.
+func VerifyPassword(hashed string, pwd string) bool {
+	switch passwordHashAlgorithm(hashed) {
+	case PasswordHashBcrypt:
+		return bcrypt.CompareHashAndPassword([]byte(hashed), []byte(pwd)) == nil
+	case PasswordHashArgon2id:
+		params, salt, key, err := parseArgon2id(hashed)
+		if err != nil {
+			return false
+		}
+		candidate := argon2.IDKey([]byte(pwd), salt, params.iterations, params.memory, params.parallelism, uint32(len(key)))
+		return subtle.ConstantTimeCompare(candidate, key) == 1
+	default:
+		return false
+	}
+}
+
+func passwordHashAlgorithm(hashed string) string {
+	switch {
+	case strings.HasPrefix(hashed, "$2a$"), strings.HasPrefix(hashed, "$2b$"), strings.HasPrefix(hashed, "$2y$"):
+		return PasswordHashBcrypt
+	case strings.HasPrefix(hashed, "$argon2id$"):
+		return PasswordHashArgon2id
+	default:
+		return ""
+	}
+}
+
+// hashArgon2id encodes the hash in the PHC string format,
+// $argon2id$v=19$m=<memory>,t=<iterations>,p=<parallelism>$<salt>$<key>.
+func hashArgon2id(pwd string, params argon2idParams) (string, error) {
+	salt := make([]byte, argon2SaltLength)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(pwd), salt, params.iterations, params.memory, params.parallelism, argon2KeyLength)
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, params.memory, params.iterations, params.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func parseArgon2id(hashed string) (params argon2idParams, salt []byte, key []byte, err error) {
+	parts := strings.Split(hashed, "$")
+	if len(parts) != 6 || parts[1] != PasswordHashArgon2id {
+		return params, nil, nil, errMalformedPasswordHash
+	}
+
+	var version int
+	_, err = fmt.Sscanf(parts[2], "v=%d", &version)
+	if err != nil || version != argon2.Version {
+		return params, nil, nil, errMalformedPasswordHash
+	}
+
+	_, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.iterations, &params.parallelism)
+	if err != nil {
+		return params, nil, nil, errMalformedPasswordHash
+	}
+
+	salt, err = base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, errMalformedPasswordHash
+	}
+	key, err = base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, errMalformedPasswordHash
+	}
+	return params, salt, key, nil
+}
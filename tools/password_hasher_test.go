@@ -0,0 +1,73 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPasswordHasher(t *testing.T) {
+	argon2id, err := NewPasswordHasher(PasswordHasherOptions{Algorithm: PasswordHashArgon2id, Argon2Memory: 1024, Argon2Iterations: 1})
+	assert.NoError(t, err)
+	bcryptHasher, err := NewPasswordHasher(PasswordHasherOptions{Algorithm: PasswordHashBcrypt, BcryptCost: bcrypt.MinCost})
+	assert.NoError(t, err)
+
+	t.Run("when hashing with argon2id then the hash is a PHC string", func(t *testing.T) {
+		hashed, err := argon2id.Hash("IloveVirginCo2Nut123$")
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(hashed, "$argon2id$v=19$m=1024,t=1,p=1$"))
+
+		assert.True(t, argon2id.Verify(hashed, "IloveVirginCo2Nut123$"))
+		assert.False(t, argon2id.Verify(hashed, "IloveVirginCo2Nut123"))
+		assert.False(t, argon2id.NeedsRehash(hashed))
+	})
+
+	t.Run("when verifying then the algorithm is told from the hash", func(t *testing.T) {
+		hashed, err := bcryptHasher.Hash("IloveVirginCo2Nut123$")
+		assert.NoError(t, err)
+
+		assert.True(t, argon2id.Verify(hashed, "IloveVirginCo2Nut123$"))
+		assert.True(t, IsValidPassword(hashed, "IloveVirginCo2Nut123$"))
+	})
+
+	t.Run("when the hash is outdated then it needs a rehash", func(t *testing.T) {
+		bcryptHash, err := bcryptHasher.Hash("IloveVirginCo2Nut123$")
+		assert.NoError(t, err)
+		argon2Hash, err := argon2id.Hash("IloveVirginCo2Nut123$")
+		assert.NoError(t, err)
+
+		strongerBcrypt, err := NewPasswordHasher(PasswordHasherOptions{BcryptCost: bcrypt.MinCost + 1})
+		assert.NoError(t, err)
+		strongerArgon2id, err := NewPasswordHasher(PasswordHasherOptions{Algorithm: PasswordHashArgon2id, Argon2Memory: 2048, Argon2Iterations: 1})
+		assert.NoError(t, err)
+
+		assert.True(t, argon2id.NeedsRehash(bcryptHash), "other algorithm")
+		assert.True(t, bcryptHasher.NeedsRehash(argon2Hash), "other algorithm")
+		assert.True(t, strongerBcrypt.NeedsRehash(bcryptHash), "other cost")
+		assert.True(t, strongerArgon2id.NeedsRehash(argon2Hash), "other memory")
+		assert.False(t, bcryptHasher.NeedsRehash(bcryptHash))
+		assert.True(t, bcryptHasher.NeedsRehash("plaintext"))
+	})
+
+	t.Run("when the hash is malformed then nothing matches it", func(t *testing.T) {
+		for _, hashed := range []string{
+			"",
+			"$argon2id$v=19$m=1024,t=1,p=1$c2FsdA",
+			"$argon2id$v=18$m=1024,t=1,p=1$c2FsdA$a2V5",
+			"$argon2id$v=19$m=x,t=1,p=1$c2FsdA$a2V5",
+			"$argon2i$v=19$m=1024,t=1,p=1$c2FsdA$a2V5",
+		} {
+			assert.False(t, VerifyPassword(hashed, ""), hashed)
+		}
+	})
+}
+
+func TestNewPasswordHasher_Error(t *testing.T) {
+	_, err := NewPasswordHasher(PasswordHasherOptions{Algorithm: "md5"})
+	assert.Error(t, err)
+
+	_, err = NewPasswordHasher(PasswordHasherOptions{BcryptCost: bcrypt.MaxCost + 1})
+	assert.Error(t, err)
+}
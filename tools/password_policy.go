@@ -0,0 +1,75 @@
+package tools
+
+import (
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// PasswordPolicy is what the pwd validation asks of a new password.
+type PasswordPolicy struct {
+	MinLength        int
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireNumber    bool
+	RequireSpecial   bool
+}
+
+// DefaultPasswordPolicy is enforced until SetPasswordPolicy is called: at
+// least six characters with a capital letter, a number and a special
+// character.
+var DefaultPasswordPolicy = PasswordPolicy{
+	MinLength:        6,
+	RequireUppercase: true,
+	RequireNumber:    true,
+	RequireSpecial:   true,
+}
+
+// Allows reports whether pwd meets the policy.
+func (p PasswordPolicy) Allows(pwd string) bool {
+	if utf8.RuneCountInString(pwd) < p.MinLength {
+		return false
+	}
+
+	hasUppercase := false
+	hasLowercase := false
+	hasNumber := false
+	hasSpecial := false
+
+	for _, char := range pwd {
+		switch {
+		case unicode.IsUpper(char):
+			hasUppercase = true
+		case unicode.IsLower(char):
+			hasLowercase = true
+		case unicode.IsNumber(char):
+			hasNumber = true
+		case !unicode.IsLetter(char):
+			hasSpecial = true
+		}
+	}
+
+	return (hasUppercase || !p.RequireUppercase) &&
+		(hasLowercase || !p.RequireLowercase) &&
+		(hasNumber || !p.RequireNumber) &&
+		(hasSpecial || !p.RequireSpecial)
+}
+
+var (
+	passwordPolicyMu sync.RWMutex
+	passwordPolicy   = DefaultPasswordPolicy
+)
+
+// SetPasswordPolicy replaces the policy the pwd validation enforces.
+func SetPasswordPolicy(policy PasswordPolicy) {
+	passwordPolicyMu.Lock()
+	defer passwordPolicyMu.Unlock()
+	passwordPolicy = policy
+}
+
+// CurrentPasswordPolicy returns the policy the pwd validation enforces.
+func CurrentPasswordPolicy() PasswordPolicy {
+	passwordPolicyMu.RLock()
+	defer passwordPolicyMu.RUnlock()
+	return passwordPolicy
+}
@@ -0,0 +1,44 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordPolicy(t *testing.T) {
+	t.Run("when using the default policy then it keeps the original rules", func(t *testing.T) {
+		assert.True(t, DefaultPasswordPolicy.Allows("IloveVirginCo2Nut123$"))
+		assert.True(t, DefaultPasswordPolicy.Allows("ABC12$"))
+		assert.False(t, DefaultPasswordPolicy.Allows("Ab1$"), "too short")
+		assert.False(t, DefaultPasswordPolicy.Allows("password"))
+	})
+
+	t.Run("when classes are required then each one is checked", func(t *testing.T) {
+		policy := PasswordPolicy{MinLength: 8, RequireLowercase: true, RequireNumber: true}
+
+		assert.True(t, policy.Allows("lowercase1"))
+		assert.False(t, policy.Allows("UPPERCASE1"), "no lowercase")
+		assert.False(t, policy.Allows("lowercase"), "no number")
+		assert.False(t, policy.Allows("lower1"), "too short")
+	})
+
+	t.Run("when the length is counted then characters are counted, not bytes", func(t *testing.T) {
+		policy := PasswordPolicy{MinLength: 6}
+
+		assert.False(t, policy.Allows("ééééé"))
+		assert.True(t, policy.Allows("éééééé"))
+	})
+
+	t.Run("when the policy is set then the pwd validation follows it", func(t *testing.T) {
+		type TestPayload struct {
+			Password string `json:"password" validate:"pwd"`
+		}
+		t.Cleanup(func() { SetPasswordPolicy(DefaultPasswordPolicy) })
+
+		SetPasswordPolicy(PasswordPolicy{MinLength: 12, RequireLowercase: true})
+		assert.Error(t, ValidateRequestPayload(TestPayload{Password: "Short1$"}))
+		assert.NoError(t, ValidateRequestPayload(TestPayload{Password: "long enough passphrase"}))
+		assert.Equal(t, 12, CurrentPasswordPolicy().MinLength)
+	})
+}
@@ -0,0 +1,31 @@
+package tools
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"regexp"
+)
+
+const PKCEMethodS256 = "S256"
+
+// pkceValueRegex matches the RFC 7636 code verifier grammar, which an S256
+// code challenge also satisfies.
+var pkceValueRegex = regexp.MustCompile(`^[A-Za-z0-9\-._~]{43,128}$`)
+
+func IsValidPKCEValue(value string) bool {
+	return pkceValueRegex.MatchString(value)
+}
+
+// PKCEChallengeS256 derives the code challenge a client sends for verifier.
+func PKCEChallengeS256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func VerifyPKCES256(verifier string, challenge string) bool {
+	if !IsValidPKCEValue(verifier) {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(PKCEChallengeS256(verifier)), []byte(challenge)) == 1
+}
@@ -0,0 +1,26 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyPKCES256(t *testing.T) {
+	verifier := "M25iVXpKU3puUjFaYWg3T1NDTDQtcW1ROUY5YXlwalNoc0hhakxifmZHag"
+	challenge := "qjrzSW9gMiUgpUvqgEPE4_-8swvyCtfOVvg55o5S_es"
+
+	t.Run("when verifier matches the challenge", func(t *testing.T) {
+		assert.Equal(t, challenge, PKCEChallengeS256(verifier))
+		assert.True(t, VerifyPKCES256(verifier, challenge))
+	})
+
+	t.Run("when verifier does not match the challenge", func(t *testing.T) {
+		assert.False(t, VerifyPKCES256(strings.Repeat("a", 43), challenge))
+	})
+
+	t.Run("when verifier is too short", func(t *testing.T) {
+		assert.False(t, VerifyPKCES256("short", PKCEChallengeS256("short")))
+	})
+}
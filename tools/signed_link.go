@@ -0,0 +1,26 @@
+package tools
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strconv"
+	"time"
+)
+
+// SignLink returns the signature that lets a link to subject be used until
+// expiresAt without any other credentials.
+func SignLink(key []byte, subject string, expiresAt time.Time) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(subject + "|" + strconv.FormatInt(expiresAt.Unix(), 10)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyLink reports whether signature was made by SignLink with the same key
+// for subject and expiresAt, and whether expiresAt is still ahead of now.
+func VerifyLink(key []byte, subject string, expiresAt time.Time, signature string, now time.Time) bool {
+	if !now.Before(expiresAt) {
+		return false
+	}
+	return hmac.Equal([]byte(SignLink(key, subject, expiresAt)), []byte(signature))
+}
@@ -0,0 +1,35 @@
+package tools
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyLink(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	now := time.Now()
+	expiresAt := now.Add(time.Hour)
+	signature := SignLink(key, "export-1", expiresAt)
+
+	t.Run("when success the signature matches", func(t *testing.T) {
+		assert.True(t, VerifyLink(key, "export-1", expiresAt, signature, now))
+	})
+
+	t.Run("when error the link has expired", func(t *testing.T) {
+		assert.False(t, VerifyLink(key, "export-1", expiresAt, signature, expiresAt))
+	})
+
+	t.Run("when error the expiry was changed", func(t *testing.T) {
+		assert.False(t, VerifyLink(key, "export-1", expiresAt.Add(time.Hour), signature, now))
+	})
+
+	t.Run("when error the link is for another subject", func(t *testing.T) {
+		assert.False(t, VerifyLink(key, "export-2", expiresAt, signature, now))
+	})
+
+	t.Run("when error the key is different", func(t *testing.T) {
+		assert.False(t, VerifyLink([]byte("another key"), "export-1", expiresAt, signature, now))
+	})
+}
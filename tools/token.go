@@ -0,0 +1,28 @@
+package tools
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+const opaqueTokenByteLength = 32
+
+// GenerateOpaqueToken returns a random URL-safe token together with the hash
+// that should be persisted instead of the token itself.
+func GenerateOpaqueToken() (token string, tokenHash string, err error) {
+	b := make([]byte, opaqueTokenByteLength)
+	_, err = rand.Read(b)
+	if err != nil {
+		return
+	}
+
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, HashOpaqueToken(token), nil
+}
+
+func HashOpaqueToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
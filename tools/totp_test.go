@@ -0,0 +1,114 @@
+package tools
+
+import (
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rfc6238Secret is the SHA1 seed from the RFC 6238 test vectors.
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode(t *testing.T) {
+	t.Run("when matching the RFC 6238 test vectors", func(t *testing.T) {
+		code, err := TOTPCode(rfc6238Secret, TOTPStep(time.Unix(59, 0)))
+		assert.NoError(t, err)
+		assert.Equal(t, "287082", code)
+
+		code, err = TOTPCode(rfc6238Secret, TOTPStep(time.Unix(1111111109, 0)))
+		assert.NoError(t, err)
+		assert.Equal(t, "081804", code)
+	})
+
+	t.Run("when secret is not base32", func(t *testing.T) {
+		_, err := TOTPCode("not base32!", 1)
+		assert.Error(t, err)
+	})
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	step := TOTPStep(now)
+
+	t.Run("when code is for the current step", func(t *testing.T) {
+		matched, ok, err := ValidateTOTP(rfc6238Secret, "081804", now, 0)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, step, matched)
+	})
+
+	t.Run("when code is for the previous step", func(t *testing.T) {
+		code, _ := TOTPCode(rfc6238Secret, step-1)
+		matched, ok, err := ValidateTOTP(rfc6238Secret, code, now, 0)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, step-1, matched)
+	})
+
+	t.Run("when code was already used", func(t *testing.T) {
+		_, ok, err := ValidateTOTP(rfc6238Secret, "081804", now, step)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("when code is wrong", func(t *testing.T) {
+		_, ok, err := ValidateTOTP(rfc6238Secret, "000000", now, 0)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+}
+
+func TestTOTPURI(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	assert.NoError(t, err)
+
+	uri, err := url.Parse(TOTPURI("UserService", "+62345678901", secret))
+	assert.NoError(t, err)
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, "totp", uri.Host)
+	assert.Equal(t, "/UserService:+62345678901", uri.Path)
+	assert.Equal(t, secret, uri.Query().Get("secret"))
+	assert.Equal(t, "UserService", uri.Query().Get("issuer"))
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	assert.NoError(t, err)
+	assert.Len(t, codes, 10)
+	for _, code := range codes {
+		assert.Regexp(t, regexp.MustCompile(`^[a-z2-7]{5}-[a-z2-7]{5}$`), code)
+		assert.Equal(t, code, NormalizeRecoveryCode(" "+code[:5]+code[6:]+" "))
+	}
+}
+
+func TestEncryptSecret(t *testing.T) {
+	key, err := ParseEncryptionKey("MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+	assert.NoError(t, err)
+
+	t.Run("when decrypting with the same key", func(t *testing.T) {
+		ciphertext, err := EncryptSecret(key, rfc6238Secret)
+		assert.NoError(t, err)
+		assert.NotContains(t, ciphertext, rfc6238Secret)
+
+		plaintext, err := DecryptSecret(key, ciphertext)
+		assert.NoError(t, err)
+		assert.Equal(t, rfc6238Secret, plaintext)
+	})
+
+	t.Run("when decrypting with another key", func(t *testing.T) {
+		ciphertext, err := EncryptSecret(key, rfc6238Secret)
+		assert.NoError(t, err)
+
+		otherKey, _ := ParseEncryptionKey("ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA=")
+		_, err = DecryptSecret(otherKey, ciphertext)
+		assert.Error(t, err)
+	})
+
+	t.Run("when key has the wrong length", func(t *testing.T) {
+		_, err := ParseEncryptionKey("c2hvcnQ=")
+		assert.Error(t, err)
+	})
+}
@@ -1,6 +1,7 @@
 package tools
 
 import (
+	"log"
 	"net/http"
 	"reflect"
 	"regexp"
@@ -36,6 +37,11 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+
+	err = validate.RegisterValidation("not_breached_pwd", ValidateNotBreachedPasswordVal)
+	if err != nil {
+		panic(err)
+	}
 }
 
 func ValidatePhoneNumberVal(fl validator.FieldLevel) bool {
@@ -58,24 +64,27 @@ func ValidatePhoneNumberVal(fl validator.FieldLevel) bool {
 	return regex.MatchString(v.String())
 }
 
+// ValidatePasswordVal checks the password against the policy set with
+// SetPasswordPolicy.
 func ValidatePasswordVal(fl validator.FieldLevel) bool {
-	password := fl.Field().String()
-
-	hasCapital := false
-	hasNumber := false
-	hasSpecial := false
-
-	for _, char := range password {
-		if unicode.IsUpper(char) {
-			hasCapital = true
-		} else if unicode.IsNumber(char) {
-			hasNumber = true
-		} else if !unicode.IsLetter(char) && !unicode.IsNumber(char) {
-			hasSpecial = true
-		}
+	return CurrentPasswordPolicy().Allows(fl.Field().String())
+}
+
+// ValidateNotBreachedPasswordVal rejects passwords on the blocklist set with
+// SetPasswordBlocklist. A blocklist that cannot be read lets the password
+// through rather than blocking every sign up.
+func ValidateNotBreachedPasswordVal(fl validator.FieldLevel) bool {
+	blocklist := currentPasswordBlocklist()
+	if blocklist == nil {
+		return true
 	}
 
-	return hasCapital && hasNumber && hasSpecial
+	blocked, err := blocklist.IsBlocked(fl.Field().String())
+	if err != nil {
+		log.Printf("checking password blocklist: %v", err)
+		return true
+	}
+	return !blocked
 }
 
 func ValidateRequestPayload(s interface{}) error {